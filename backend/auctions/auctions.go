@@ -0,0 +1,191 @@
+// Package auctions closes bidding on sealed and reverse-auction tasks. A
+// recurring job finds auctions whose closing time passed, picks the lowest
+// bid of a reverse auction or shortlists the lowest sealed bids, and
+// rejects the rest. The client then accepts one of the picked bids.
+package auctions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/jobs"
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/notify"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// JobAuctionClose is the recurring job that runs Scan.
+const JobAuctionClose = "task_auction_close"
+
+const (
+	scanInterval = time.Minute
+	// scanBatch caps the auctions closed by one scan; the rest are closed
+	// by the next.
+	scanBatch = 100
+)
+
+// Config controls how auctions close.
+type Config struct {
+	ShortlistSize int // sealed bids shortlisted when bidding closes
+}
+
+// ConfigFromEnv reads AUCTION_SHORTLIST_SIZE, 3 by default.
+func ConfigFromEnv() Config {
+	config := Config{ShortlistSize: 3}
+	if value, err := strconv.Atoi(os.Getenv("AUCTION_SHORTLIST_SIZE")); err == nil && value > 0 {
+		config.ShortlistSize = value
+	}
+	return config
+}
+
+type Service struct {
+	tasks    repository.TaskRepository
+	bids     repository.BidRepository
+	tx       repository.Transactor
+	notifier *notify.Service
+	config   Config
+}
+
+// NewService builds the auction closer and schedules its scan on queue.
+func NewService(tasks repository.TaskRepository, bids repository.BidRepository, tx repository.Transactor, notifier *notify.Service, queue *jobs.Queue, config Config) *Service {
+	if config.ShortlistSize < 1 {
+		config.ShortlistSize = 1
+	}
+
+	s := &Service{tasks: tasks, bids: bids, tx: tx, notifier: notifier, config: config}
+	queue.Register(JobAuctionClose, func(ctx context.Context, job *models.Job) error {
+		return s.Scan(ctx, time.Now())
+	})
+	queue.Every("task-auctions", scanInterval, JobAuctionClose, nil)
+	return s
+}
+
+// Scan closes every auction whose bidding closed at or before now. An
+// auction that fails to close does not stop the others; their errors are
+// returned together.
+func (s *Service) Scan(ctx context.Context, now time.Time) error {
+	// MongoDB keeps milliseconds; saves conditional on UpdatedAt must
+	// compare against what was stored.
+	now = now.Truncate(time.Millisecond)
+
+	tasks, err := s.tasks.FindBiddingDue(ctx, now, scanBatch)
+	if err != nil {
+		return fmt.Errorf("find auctions due to close: %w", err)
+	}
+
+	var errs []error
+	for i := range tasks {
+		if err := s.close(ctx, tasks[i].ID, now); err != nil {
+			errs = append(errs, fmt.Errorf("close bidding on task %s: %w", tasks[i].ID.Hex(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closing is what closing one auction changed.
+type closing struct {
+	task        *models.Task
+	shortlisted []models.Bid
+	rejected    []models.Bid
+	reason      string
+}
+
+// close picks the auction's bids in one transaction and then tells the
+// client and the bidders. A task saved by someone else meanwhile is left
+// for the next scan.
+func (s *Service) close(ctx context.Context, taskID primitive.ObjectID, now time.Time) error {
+	var result *closing
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = s.pick(ctx, taskID, now)
+		return err
+	})
+	if errors.Is(err, repository.ErrConflict) {
+		log.Printf("Task %s changed while its bidding was closing, retrying later", taskID.Hex())
+		return nil
+	}
+	if err != nil || result == nil {
+		return err
+	}
+
+	total := len(result.shortlisted) + len(result.rejected)
+	s.notifier.Notify(ctx, notify.BiddingClosed(result.task, result.shortlisted, total))
+	for i := range result.shortlisted {
+		s.notifier.Notify(ctx, notify.BidShortlisted(result.task, &result.shortlisted[i]))
+	}
+	for i := range result.rejected {
+		s.notifier.Notify(ctx, notify.BidRejected(result.task, &result.rejected[i], result.reason))
+	}
+	return nil
+}
+
+// pick is the body of the closing transaction. Open bids are ranked by
+// amount, earlier bids first on a tie; the best one wins a reverse auction
+// and the best ShortlistSize are shortlisted from a sealed one. The task is
+// written first, so a conflicting save aborts before any bid changes.
+func (s *Service) pick(ctx context.Context, taskID primitive.ObjectID, now time.Time) (*closing, error) {
+	task, err := s.tasks.FindByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != models.TaskStatusOpen || !task.AwaitingClose() {
+		return nil, nil
+	}
+	lastUpdatedAt := task.UpdatedAt
+
+	bids, err := s.bids.FindByTaskAndStatus(ctx, task.ID, models.OpenBidStatuses...)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(bids, func(i, j int) bool {
+		return models.ToCents(bids[i].Amount) < models.ToCents(bids[j].Amount)
+	})
+
+	keep := s.config.ShortlistSize
+	reason := "it was not shortlisted when sealed bidding closed"
+	if task.BiddingMode == models.BiddingReverseAuction {
+		keep = 1
+		reason = "a lower bid won the auction"
+	}
+	keep = min(keep, len(bids))
+
+	task.BiddingClosedAt = &now
+	task.Record(models.TaskEventCloseBidding, nil, fmt.Sprintf("Bidding closed with %d open bid(s), %d shortlisted", len(bids), keep), now)
+	if err := s.tasks.UpdateIfUnchanged(ctx, task, lastUpdatedAt); err != nil {
+		return nil, err
+	}
+
+	// A bid changed by its freelancer in the meantime, which can only be a
+	// withdrawal, is left as it is.
+	result := &closing{task: task, reason: reason}
+	for i := range bids {
+		bid := &bids[i]
+		bidUpdatedAt := bid.UpdatedAt
+		if i < keep {
+			bid.Shortlisted = true
+			bid.UpdatedAt = now
+		} else if err := bid.Transition(models.BidEventReject, nil, "Rejected because "+reason, now); err != nil {
+			continue
+		}
+
+		if err := s.bids.UpdateIfUnchanged(ctx, bid, bidUpdatedAt); err != nil {
+			if errors.Is(err, repository.ErrConflict) {
+				continue
+			}
+			return nil, err
+		}
+		if i < keep {
+			result.shortlisted = append(result.shortlisted, *bid)
+		} else {
+			result.rejected = append(result.rejected, *bid)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,150 @@
+package auctions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/jobs"
+	"github.com/Vivekpdy/tasklanceweb/backend/mailer"
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/notify"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestScanClosesAuctions(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          string
+		shortlistSize int
+		amounts       []float64 // in the order the bids were placed
+		picked        []int     // indexes into amounts, best first
+	}{
+		{"sealed shortlist", models.BiddingSealed, 2, []float64{500, 300, 450, 400}, []int{1, 3}},
+		{"sealed tie goes to the earlier bid", models.BiddingSealed, 2, []float64{400, 300, 300, 350}, []int{1, 2}},
+		{"sealed with fewer bids than the shortlist", models.BiddingSealed, 3, []float64{500, 300}, []int{1, 0}},
+		{"reverse auction", models.BiddingReverseAuction, 3, []float64{450, 380, 400}, []int{1}},
+		{"reverse auction tie", models.BiddingReverseAuction, 3, []float64{400, 380, 380}, []int{1}},
+		{"no bids", models.BiddingSealed, 3, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repos := repository.NewMemoryRepositories()
+			queue := jobs.NewQueue(repos.Jobs, jobs.Config{MaxAttempts: 1})
+			notifier := notify.NewService(repos.Notifications, repos.NotificationPreferences, repos.Users, mailer.NewMemoryMailer(), queue, notify.EmailConfig{})
+			service := NewService(repos.Tasks, repos.Bids, repos.Transactor, notifier, queue, Config{ShortlistSize: tt.shortlistSize})
+
+			now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+			closesAt := now.Add(-time.Minute)
+			task := &models.Task{
+				ID:              primitive.NewObjectID(),
+				Title:           "Landing page",
+				ClientID:        primitive.NewObjectID(),
+				Status:          models.TaskStatusOpen,
+				BiddingMode:     tt.mode,
+				BiddingClosesAt: &closesAt,
+				CreatedAt:       now.Add(-24 * time.Hour),
+				UpdatedAt:       now.Add(-24 * time.Hour),
+			}
+			if err := repos.Tasks.Create(ctx, task); err != nil {
+				t.Fatal(err)
+			}
+			bids := make([]models.Bid, len(tt.amounts))
+			for i, amount := range tt.amounts {
+				bids[i] = models.Bid{ID: primitive.NewObjectID(), TaskID: task.ID, FreelancerID: primitive.NewObjectID(), Amount: amount}
+				bids[i].Submit(bids[i].FreelancerID, task.CreatedAt.Add(time.Duration(i)*time.Minute))
+				if err := repos.Bids.Create(ctx, &bids[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Nothing closes before the closing time.
+			if err := service.Scan(ctx, closesAt.Add(-time.Second)); err != nil {
+				t.Fatal(err)
+			}
+			if open, _ := repos.Tasks.FindByID(ctx, task.ID); open.BiddingClosedAt != nil {
+				t.Fatal("bidding closed early")
+			}
+
+			if err := service.Scan(ctx, now); err != nil {
+				t.Fatal(err)
+			}
+			closed, err := repos.Tasks.FindByID(ctx, task.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if closed.BiddingClosedAt == nil || closed.Status != models.TaskStatusOpen {
+				t.Fatalf("task is %q closed at %v, want still open with bidding closed", closed.Status, closed.BiddingClosedAt)
+			}
+			if last := closed.StatusHistory[len(closed.StatusHistory)-1]; last.Event != models.TaskEventCloseBidding {
+				t.Errorf("last history event = %q, want %q", last.Event, models.TaskEventCloseBidding)
+			}
+
+			picked := make(map[int]bool)
+			for _, i := range tt.picked {
+				picked[i] = true
+			}
+			for i := range bids {
+				bid, err := repos.Bids.FindByID(ctx, bids[i].ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if picked[i] {
+					if !bid.Shortlisted || bid.Status != models.BidStatusPending {
+						t.Errorf("bid %d ($%.0f) is %q shortlisted=%v, want a pending shortlisted bid", i, bid.Amount, bid.Status, bid.Shortlisted)
+					}
+				} else if bid.Shortlisted || bid.Status != models.BidStatusRejected {
+					t.Errorf("bid %d ($%.0f) is %q shortlisted=%v, want rejected", i, bid.Amount, bid.Status, bid.Shortlisted)
+				}
+			}
+
+			// A closed auction is not closed again.
+			if err := service.Scan(ctx, now.Add(time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+			again, _ := repos.Tasks.FindByID(ctx, task.ID)
+			if !again.BiddingClosedAt.Equal(*closed.BiddingClosedAt) || len(again.StatusHistory) != len(closed.StatusHistory) {
+				t.Error("a closed auction was closed again")
+			}
+		})
+	}
+}
+
+func TestScanLeavesWithdrawnBids(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewMemoryRepositories()
+	queue := jobs.NewQueue(repos.Jobs, jobs.Config{MaxAttempts: 1})
+	notifier := notify.NewService(repos.Notifications, repos.NotificationPreferences, repos.Users, mailer.NewMemoryMailer(), queue, notify.EmailConfig{})
+	service := NewService(repos.Tasks, repos.Bids, repos.Transactor, notifier, queue, Config{ShortlistSize: 1})
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	closesAt := now.Add(-time.Minute)
+	task := &models.Task{ID: primitive.NewObjectID(), Status: models.TaskStatusOpen, BiddingMode: models.BiddingReverseAuction, BiddingClosesAt: &closesAt, CreatedAt: now, UpdatedAt: now}
+	if err := repos.Tasks.Create(ctx, task); err != nil {
+		t.Fatal(err)
+	}
+	withdrawn := models.Bid{ID: primitive.NewObjectID(), TaskID: task.ID, FreelancerID: primitive.NewObjectID(), Amount: 100}
+	withdrawn.Submit(withdrawn.FreelancerID, now.Add(-time.Hour))
+	if err := withdrawn.Transition(models.BidEventWithdraw, &withdrawn.FreelancerID, "", now.Add(-30*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	open := models.Bid{ID: primitive.NewObjectID(), TaskID: task.ID, FreelancerID: primitive.NewObjectID(), Amount: 300}
+	open.Submit(open.FreelancerID, now.Add(-time.Hour))
+	for _, bid := range []*models.Bid{&withdrawn, &open} {
+		if err := repos.Bids.Create(ctx, bid); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := service.Scan(ctx, now); err != nil {
+		t.Fatal(err)
+	}
+	if bid, _ := repos.Bids.FindByID(ctx, withdrawn.ID); bid.Status != models.BidStatusWithdrawn || bid.Shortlisted {
+		t.Errorf("withdrawn bid is %q shortlisted=%v, want it untouched", bid.Status, bid.Shortlisted)
+	}
+	if bid, _ := repos.Bids.FindByID(ctx, open.ID); !bid.Shortlisted {
+		t.Error("the only open bid did not win")
+	}
+}
@@ -0,0 +1,68 @@
+// Package bootstrap prepares data the application needs before it can serve
+// requests.
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"github.com/Vivekpdy/tasklanceweb/backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrAdminEmailTaken is returned when the configured admin email belongs to
+// an account that is not an admin. Promoting it would hand admin rights to
+// whoever registered that email, so startup refuses instead.
+var ErrAdminEmailTaken = errors.New("admin email is already registered to a non-admin account")
+
+// EnsureAdmin creates the first administrator account when none exists yet.
+// Once any admin exists this is a no-op, so it is safe to run on every start.
+func EnsureAdmin(ctx context.Context, users repository.UserRepository, email, password string) error {
+	count, err := users.CountByType(ctx, models.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	_, err = users.FindByEmail(ctx, email)
+	switch {
+	case err == nil:
+		return fmt.Errorf("%w: %s", ErrAdminEmailTaken, email)
+	case !errors.Is(err, repository.ErrNotFound):
+		return err
+	}
+
+	if len(password) < 6 {
+		return errors.New("admin password must be at least 6 characters")
+	}
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	admin := models.User{
+		ID:         primitive.NewObjectID(),
+		Email:      email,
+		Password:   hashedPassword,
+		FirstName:  "Admin",
+		LastName:   "User",
+		UserType:   models.RoleAdmin,
+		IsVerified: true,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	if err := users.Create(ctx, &admin); err != nil {
+		return err
+	}
+
+	log.Printf("Created admin account %s", email)
+	return nil
+}
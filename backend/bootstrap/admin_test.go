@@ -0,0 +1,71 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"github.com/Vivekpdy/tasklanceweb/backend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestEnsureAdminCreatesAdmin(t *testing.T) {
+	ctx := context.Background()
+	users := repository.NewMemoryUserRepository()
+
+	if err := EnsureAdmin(ctx, users, "admin@example.com", "secret1"); err != nil {
+		t.Fatal(err)
+	}
+	admin, err := users.FindByEmail(ctx, "admin@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if admin.UserType != models.RoleAdmin || !admin.IsVerified {
+		t.Errorf("created user is %q verified=%v, want a verified admin", admin.UserType, admin.IsVerified)
+	}
+	if !utils.CheckPasswordHash("secret1", admin.Password) {
+		t.Error("admin password is not ADMIN_PASSWORD")
+	}
+
+	// Running again once an admin exists is a no-op.
+	if err := EnsureAdmin(ctx, users, "other@example.com", "secret2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := users.FindByEmail(ctx, "other@example.com"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("second admin lookup = %v, want ErrNotFound", err)
+	}
+}
+
+func TestEnsureAdminRefusesToPromote(t *testing.T) {
+	ctx := context.Background()
+	users := repository.NewMemoryUserRepository()
+	squatter := models.User{
+		ID:        primitive.NewObjectID(),
+		Email:     "admin@example.com",
+		Password:  "chosen-by-someone-else",
+		UserType:  models.RoleFreelancer,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := users.Create(ctx, &squatter); err != nil {
+		t.Fatal(err)
+	}
+
+	err := EnsureAdmin(ctx, users, "admin@example.com", "secret1")
+	if !errors.Is(err, ErrAdminEmailTaken) {
+		t.Fatalf("EnsureAdmin = %v, want ErrAdminEmailTaken", err)
+	}
+	user, err := users.FindByID(ctx, squatter.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.UserType != models.RoleFreelancer || user.IsVerified {
+		t.Errorf("existing user became %q verified=%v, want it untouched", user.UserType, user.IsVerified)
+	}
+	if count, _ := users.CountByType(ctx, models.RoleAdmin); count != 0 {
+		t.Errorf("admin count = %d, want 0", count)
+	}
+}
@@ -0,0 +1,117 @@
+package chat
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// writeWait bounds a single write to the client.
+	writeWait = 10 * time.Second
+	// pongWait is how long a connection may stay silent before it is
+	// considered dead; pings are sent well within it.
+	pongWait   = 60 * time.Second
+	pingPeriod = pongWait * 9 / 10
+	// maxFrameBytes leaves room for a message of models.MaxMessageLength
+	// bytes once JSON escaping is applied.
+	maxFrameBytes = 32 << 10
+	// sendBuffer is how many events may queue for a slow client before it
+	// is disconnected.
+	sendBuffer = 32
+)
+
+// Client is one open WebSocket connection to a conversation.
+type Client struct {
+	ConversationID primitive.ObjectID
+	UserID         primitive.ObjectID
+
+	hub  *Hub
+	conn *websocket.Conn
+	send chan []byte
+}
+
+// Send queues event for this connection only, such as an error reply.
+func (c *Client) Send(event Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to encode chat event: %v", err)
+		return
+	}
+
+	c.hub.mu.RLock()
+	defer c.hub.mu.RUnlock()
+	if _, ok := c.hub.rooms[c.ConversationID][c]; ok {
+		c.deliver(data)
+	}
+}
+
+// deliver queues data without blocking the hub. A client whose queue is full
+// has stopped reading and is disconnected; the hub lock must be held.
+func (c *Client) deliver(data []byte) {
+	select {
+	case c.send <- data:
+	default:
+		c.conn.Close()
+	}
+}
+
+func (c *Client) readPump(handle Handler) {
+	defer func() {
+		c.hub.leave(c)
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(maxFrameBytes)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("Chat connection closed: %v", err)
+			}
+			return
+		}
+
+		var frame Frame
+		if err := json.Unmarshal(data, &frame); err != nil {
+			c.Send(Event{Type: EventError, ConversationID: c.ConversationID, Error: "Invalid frame"})
+			continue
+		}
+		handle(c, frame)
+	}
+}
+
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case data, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Package chat delivers conversation events to connected WebSocket clients.
+// Messages are persisted by the caller before they are broadcast; the hub
+// only fans events out to the connections open in this process.
+package chat
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/gorilla/websocket"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Event types sent to clients. Clients send frames with the first three.
+const (
+	EventMessage = "message"
+	EventTyping  = "typing"
+	EventRead    = "read"
+	EventError   = "error"
+)
+
+// Event is a server-to-client frame.
+type Event struct {
+	Type           string             `json:"type"`
+	ConversationID primitive.ObjectID `json:"conversation_id"`
+	// Message is the stored message of a message event.
+	Message *models.Message `json:"message,omitempty"`
+	// UserID is who is typing or who read the messages.
+	UserID *primitive.ObjectID `json:"user_id,omitempty"`
+	Typing *bool               `json:"typing,omitempty"`
+	// ReadAt and Through tell the sender that every message up to Through
+	// was read at ReadAt.
+	ReadAt  *time.Time          `json:"read_at,omitempty"`
+	Through *primitive.ObjectID `json:"through,omitempty"`
+	Error   string              `json:"error,omitempty"`
+}
+
+// Frame is a client-to-server frame: a message with Body, a typing
+// indicator, or a read receipt for MessageID (every received message when
+// empty).
+type Frame struct {
+	Type      string `json:"type"`
+	Body      string `json:"body,omitempty"`
+	Typing    bool   `json:"typing,omitempty"`
+	MessageID string `json:"message_id,omitempty"`
+}
+
+// Handler acts on one frame read from a client.
+type Handler func(client *Client, frame Frame)
+
+// Hub tracks the open connections of every conversation.
+type Hub struct {
+	mu    sync.RWMutex
+	rooms map[primitive.ObjectID]map[*Client]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{rooms: make(map[primitive.ObjectID]map[*Client]struct{})}
+}
+
+// NewUpgrader accepts WebSocket handshakes from allowedOrigin and from
+// clients that send no Origin header, which browsers always do.
+func NewUpgrader(allowedOrigin string) *websocket.Upgrader {
+	return &websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			return origin == "" || origin == allowedOrigin
+		},
+	}
+}
+
+// Serve joins conn to a conversation on behalf of userID and passes every
+// frame it sends to handle. It blocks until the connection closes.
+func (h *Hub) Serve(conn *websocket.Conn, conversationID, userID primitive.ObjectID, handle Handler) {
+	client := &Client{
+		ConversationID: conversationID,
+		UserID:         userID,
+		hub:            h,
+		conn:           conn,
+		send:           make(chan []byte, sendBuffer),
+	}
+	h.join(client)
+	go client.writePump()
+	client.readPump(handle)
+}
+
+// Broadcast sends event to every connection in its conversation.
+func (h *Hub) Broadcast(event Event) {
+	h.publish(event, nil)
+}
+
+// BroadcastOthers sends event to the conversation's connections that do not
+// belong to userID, so typing indicators are not echoed to their author.
+func (h *Hub) BroadcastOthers(event Event, userID primitive.ObjectID) {
+	h.publish(event, &userID)
+}
+
+func (h *Hub) publish(event Event, skip *primitive.ObjectID) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to encode chat event: %v", err)
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client := range h.rooms[event.ConversationID] {
+		if skip != nil && client.UserID == *skip {
+			continue
+		}
+		client.deliver(data)
+	}
+}
+
+func (h *Hub) join(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	room, ok := h.rooms[client.ConversationID]
+	if !ok {
+		room = make(map[*Client]struct{})
+		h.rooms[client.ConversationID] = room
+	}
+	room[client] = struct{}{}
+}
+
+// leave removes client and closes its send queue, which stops its writer.
+func (h *Hub) leave(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	room := h.rooms[client.ConversationID]
+	if _, ok := room[client]; !ok {
+		return
+	}
+	delete(room, client)
+	if len(room) == 0 {
+		delete(h.rooms, client.ConversationID)
+	}
+	close(client.send)
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -60,6 +61,9 @@ func createIndexes() {
 		Keys:    map[string]interface{}{"email": 1},
 		Options: options.Index().SetUnique(true),
 	})
+	userCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+	})
 
 	// Task collection indexes
 	taskCollection := MongoDB.Collection("tasks")
@@ -68,27 +72,135 @@ func createIndexes() {
 		{Keys: map[string]interface{}{"freelancer_id": 1}},
 		{Keys: map[string]interface{}{"status": 1}},
 		{Keys: map[string]interface{}{"category": 1}},
+		{Keys: map[string]interface{}{"required_skills": 1}},
+		{Keys: map[string]interface{}{"budget": 1}},
+		{Keys: map[string]interface{}{"deadline": 1}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "deadline", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "bidding_closes_at", Value: 1}}},
+		{Keys: bson.D{{Key: "freelancer_id", Value: 1}, {Key: "status", Value: 1}}},
+		{Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{
+			Keys: bson.D{{Key: "title", Value: "text"}, {Key: "description", Value: "text"}},
+			Options: options.Index().
+				SetName("tasks_text").
+				SetWeights(bson.M{"title": 3, "description": 1}),
+		},
 	})
 
 	// Bid collection indexes
 	bidCollection := MongoDB.Collection("bids")
 	bidCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
-		{Keys: map[string]interface{}{"task_id": 1}},
+		{Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}},
 		{Keys: map[string]interface{}{"freelancer_id": 1}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "task_id", Value: 1}}},
 	})
 
 	// Review collection indexes
 	reviewCollection := MongoDB.Collection("reviews")
 	reviewCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
 		{Keys: map[string]interface{}{"task_id": 1}},
-		{Keys: map[string]interface{}{"reviewed_user_id": 1}},
+		{Keys: bson.D{{Key: "reviewed_user_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+	})
+
+	// Milestone collection indexes
+	milestoneCollection := MongoDB.Collection("milestones")
+	milestoneCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "due_date", Value: 1}}},
+	})
+
+	// Conversation collection indexes
+	conversationCollection := MongoDB.Collection("conversations")
+	conversationCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "freelancer_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "updated_at", Value: -1}, {Key: "_id", Value: -1}}},
+	})
+
+	// Message collection indexes
+	messageCollection := MongoDB.Collection("messages")
+	messageCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "conversation_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "conversation_id", Value: 1}, {Key: "read_at", Value: 1}}},
+	})
+
+	// Notification collection indexes
+	notificationCollection := MongoDB.Collection("notifications")
+	notificationCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "read_at", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "digest_pending", Value: 1}, {Key: "created_at", Value: 1}}},
+	})
+
+	// Notification preference collection indexes
+	notificationPreferenceCollection := MongoDB.Collection("notification_preferences")
+	notificationPreferenceCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: map[string]interface{}{"user_id": 1}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "email", Value: 1}, {Key: "last_digest_at", Value: 1}}},
+	})
+
+	// Job collection indexes
+	jobCollection := MongoDB.Collection("jobs")
+	jobCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "type", Value: 1}, {Key: "run_at", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "leased_until", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: map[string]interface{}{"unique_key": 1}, Options: options.Index().SetUnique(true).SetSparse(true)},
+		{Keys: map[string]interface{}{"expires_at": 1}, Options: options.Index().SetExpireAfterSeconds(0)},
 	})
 
 	// Payment collection indexes
 	paymentCollection := MongoDB.Collection("payments")
 	paymentCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
-		{Keys: map[string]interface{}{"task_id": 1}},
+		{Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
 		{Keys: map[string]interface{}{"transaction_id": 1}},
+		{Keys: map[string]interface{}{"payout_id": 1}},
+	})
+
+	// Payment event collection indexes
+	paymentEventCollection := MongoDB.Collection("payment_events")
+	paymentEventCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "provider", Value: 1}, {Key: "event_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: map[string]interface{}{"payment_id": 1}},
+	})
+
+	// Session collection indexes
+	sessionCollection := MongoDB.Collection("sessions")
+	sessionCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: map[string]interface{}{"user_id": 1}},
+		{Keys: map[string]interface{}{"refresh_token_hash": 1}, Options: options.Index().SetUnique(true)},
+		{Keys: map[string]interface{}{"previous_token_hashes": 1}},
+		{Keys: map[string]interface{}{"expires_at": 1}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+
+	// Password reset collection indexes
+	passwordResetCollection := MongoDB.Collection("password_resets")
+	passwordResetCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: map[string]interface{}{"token_hash": 1}, Options: options.Index().SetUnique(true)},
+		{Keys: map[string]interface{}{"expires_at": 1}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+
+	// Idempotency key collection indexes
+	idempotencyKeyCollection := MongoDB.Collection("idempotency_keys")
+	idempotencyKeyCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "key", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: map[string]interface{}{"expires_at": 1}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+
+	// Admin action collection indexes
+	adminActionCollection := MongoDB.Collection("admin_actions")
+	adminActionCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}}},
+		{Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+	})
+
+	// Ledger collection indexes
+	ledgerCollection := MongoDB.Collection("ledger_entries")
+	ledgerCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "payment_id", Value: 1}, {Key: "type", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: map[string]interface{}{"task_id": 1}},
+		{Keys: map[string]interface{}{"client_id": 1}},
+		{Keys: map[string]interface{}{"freelancer_id": 1}},
+		{Keys: bson.D{{Key: "postings.account", Value: 1}, {Key: "postings.owner_id", Value: 1}}},
 	})
 
 	log.Println("MongoDB indexes created successfully")
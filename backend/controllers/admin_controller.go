@@ -0,0 +1,431 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/escrow"
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"github.com/Vivekpdy/tasklanceweb/backend/policy"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AdminController struct {
+	users        repository.UserRepository
+	sessions     repository.SessionRepository
+	tasks        repository.TaskRepository
+	reviews      repository.ReviewRepository
+	adminActions repository.AdminActionRepository
+	jobs         repository.JobRepository
+	tx           repository.Transactor
+	escrow       *escrow.Service
+}
+
+func NewAdminController(users repository.UserRepository, sessions repository.SessionRepository, tasks repository.TaskRepository, reviews repository.ReviewRepository, adminActions repository.AdminActionRepository, jobs repository.JobRepository, tx repository.Transactor, escrow *escrow.Service) *AdminController {
+	return &AdminController{
+		users:        users,
+		sessions:     sessions,
+		tasks:        tasks,
+		reviews:      reviews,
+		adminActions: adminActions,
+		jobs:         jobs,
+		tx:           tx,
+		escrow:       escrow,
+	}
+}
+
+// ModerationInput is the body of every moderation action; a reason is mandatory
+// so the audit log always explains the decision.
+type ModerationInput struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// GetPermissions returns the role to permission table enforced by the API.
+func (ctrl *AdminController) GetPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, policy.Matrix())
+}
+
+// ListUsers searches users by name or email, role and suspension state.
+func (ctrl *AdminController) ListUsers(c *gin.Context) {
+	filter := repository.UserFilter{
+		Query:    c.Query("q"),
+		UserType: c.Query("user_type"),
+	}
+	if value := c.Query("suspended"); value != "" {
+		suspended, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid suspended filter"})
+			return
+		}
+		filter.Suspended = &suspended
+	}
+
+	page, err := pageParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	users, err := ctrl.users.Search(ctx, filter, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	response := pagination.Page[models.UserResponse]{
+		Items:      make([]models.UserResponse, 0, len(users.Items)),
+		NextCursor: users.NextCursor,
+		HasMore:    users.HasMore,
+	}
+	for i := range users.Items {
+		response.Items = append(response.Items, users.Items[i].ToResponse())
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SuspendUser blocks an account from logging in and revokes its sessions.
+func (ctrl *AdminController) SuspendUser(c *gin.Context) {
+	ctrl.setSuspended(c, true)
+}
+
+// ReinstateUser lifts a suspension.
+func (ctrl *AdminController) ReinstateUser(c *gin.Context) {
+	ctrl.setSuspended(c, false)
+}
+
+func (ctrl *AdminController) setSuspended(c *gin.Context, suspend bool) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input ModerationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := ctrl.users.FindByID(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if suspend && user.UserType == models.RoleAdmin {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Admin accounts cannot be suspended"})
+		return
+	}
+	if user.IsSuspended() == suspend {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already in the requested state"})
+		return
+	}
+
+	action := models.AdminActionReinstateUser
+	user.SuspendedAt = nil
+	if suspend {
+		action = models.AdminActionSuspendUser
+		now := time.Now()
+		user.SuspendedAt = &now
+	}
+	user.UpdatedAt = time.Now()
+
+	err = ctrl.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := ctrl.users.Update(ctx, user); err != nil {
+			return err
+		}
+		if suspend {
+			if _, err := ctrl.sessions.RevokeAllForUser(ctx, user.ID); err != nil {
+				return err
+			}
+		}
+		return ctrl.record(ctx, c, action, "user", user.ID, input.Reason)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User updated successfully",
+		"user":    user.ToResponse(),
+	})
+}
+
+// CancelTask force-cancels a task regardless of its owner and schedules the
+// refund of any escrowed funds to the client.
+func (ctrl *AdminController) CancelTask(c *gin.Context) {
+	taskID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	var input ModerationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	task, err := ctrl.tasks.FindByID(ctx, taskID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	adminID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	previousStatus := task.Status
+	if err := task.Transition(models.TaskEventForceCancel, &adminID, input.Reason, time.Now()); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Task is already " + task.Status})
+		return
+	}
+
+	err = ctrl.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := ctrl.tasks.UpdateIfStatus(ctx, task, previousStatus); err != nil {
+			return err
+		}
+		if err := ctrl.escrow.ScheduleSettlement(ctx, task, &adminID); err != nil {
+			return err
+		}
+		return ctrl.record(ctx, c, models.AdminActionCancelTask, "task", task.ID, input.Reason)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Task was modified concurrently, please retry"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel task"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Task cancelled successfully",
+		"task":    task,
+	})
+}
+
+// HideReview hides a review from listings and from the reviewed user's rating.
+func (ctrl *AdminController) HideReview(c *gin.Context) {
+	ctrl.setReviewHidden(c, true)
+}
+
+// UnhideReview restores a hidden review.
+func (ctrl *AdminController) UnhideReview(c *gin.Context) {
+	ctrl.setReviewHidden(c, false)
+}
+
+func (ctrl *AdminController) setReviewHidden(c *gin.Context, hide bool) {
+	reviewID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
+		return
+	}
+
+	var input ModerationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	review, err := ctrl.reviews.FindByID(ctx, reviewID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+
+	if review.IsHidden == hide {
+		c.JSON(http.StatusConflict, gin.H{"error": "Review is already in the requested state"})
+		return
+	}
+
+	action := models.AdminActionUnhideReview
+	if hide {
+		action = models.AdminActionHideReview
+	}
+	review.IsHidden = hide
+	review.UpdatedAt = time.Now()
+
+	err = ctrl.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := ctrl.reviews.Update(ctx, review); err != nil {
+			return err
+		}
+		if err := refreshUserRating(ctx, ctrl.reviews, ctrl.users, review.ReviewedUserID); err != nil {
+			return err
+		}
+		return ctrl.record(ctx, c, action, "review", review.ID, input.Reason)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update review"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Review updated successfully",
+		"review":  review,
+	})
+}
+
+// ListActions returns the moderation audit log, optionally for one target.
+func (ctrl *AdminController) ListActions(c *gin.Context) {
+	filter := repository.AdminActionFilter{TargetType: c.Query("target_type")}
+	if value := c.Query("target_id"); value != "" {
+		targetID, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target ID"})
+			return
+		}
+		filter.TargetID = targetID
+	}
+
+	page, err := pageParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	actions, err := ctrl.adminActions.Find(ctx, filter, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch admin actions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, actions)
+}
+
+// ListJobs pages through background jobs, newest first, optionally by status
+// and type; status=dead lists the jobs that ran out of attempts.
+func (ctrl *AdminController) ListJobs(c *gin.Context) {
+	filter := repository.JobFilter{Status: c.Query("status"), Type: c.Query("type")}
+	switch filter.Status {
+	case "", models.JobQueued, models.JobRunning, models.JobSucceeded, models.JobDead:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job status"})
+		return
+	}
+
+	page, err := pageParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	jobs, err := ctrl.jobs.Find(ctx, filter, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch jobs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+// GetJob returns one job with its payload and last error.
+func (ctrl *AdminController) GetJob(c *gin.Context) {
+	jobID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job, err := ctrl.jobs.FindByID(ctx, jobID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"job": job})
+}
+
+// RetryJob puts a dead job back in the queue with a fresh set of attempts.
+func (ctrl *AdminController) RetryJob(c *gin.Context) {
+	jobID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	var input ModerationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var job *models.Job
+	err = ctrl.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if job, err = ctrl.jobs.Requeue(ctx, jobID, time.Now()); err != nil {
+			return err
+		}
+		return ctrl.record(ctx, c, models.AdminActionRetryJob, "job", job.ID, input.Reason)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		case errors.Is(err, repository.ErrConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "Only dead jobs can be retried"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retry job"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Job queued for retry",
+		"job":     job,
+	})
+}
+
+// record appends a moderation decision by the current admin to the audit
+// log. Callers run it in the transaction that makes the change.
+func (ctrl *AdminController) record(ctx context.Context, c *gin.Context, action, targetType string, targetID primitive.ObjectID, reason string) error {
+	adminID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	return ctrl.adminActions.Create(ctx, &models.AdminAction{
+		ID:         primitive.NewObjectID(),
+		AdminID:    adminID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     reason,
+		CreatedAt:  time.Now(),
+	})
+}
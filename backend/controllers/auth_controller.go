@@ -2,18 +2,32 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"time"
 
-	"github.com/Vivekpdy/tasklanceweb/backend/config"
+	"github.com/Vivekpdy/tasklanceweb/backend/mailer"
 	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
 	"github.com/Vivekpdy/tasklanceweb/backend/utils"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+type AuthController struct {
+	users          repository.UserRepository
+	sessions       repository.SessionRepository
+	passwordResets repository.PasswordResetRepository
+	mailer         mailer.Mailer
+}
+
+func NewAuthController(users repository.UserRepository, sessions repository.SessionRepository, passwordResets repository.PasswordResetRepository, mail mailer.Mailer) *AuthController {
+	return &AuthController{users: users, sessions: sessions, passwordResets: passwordResets, mailer: mail}
+}
+
 type RegisterInput struct {
 	Email     string `json:"email" binding:"required,email"`
 	Password  string `json:"password" binding:"required,min=6"`
@@ -27,21 +41,39 @@ type LoginInput struct {
 	Password string `json:"password" binding:"required"`
 }
 
-func Register(c *gin.Context) {
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type VerifyEmailInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationInput struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ForgotPasswordInput struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordInput struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+func (ctrl *AuthController) Register(c *gin.Context) {
 	var input RegisterInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	collection := config.MongoDB.Collection("users")
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	// Check if user already exists
-	var existingUser models.User
-	err := collection.FindOne(ctx, bson.M{"email": input.Email}).Decode(&existingUser)
-	if err == nil {
+	if _, err := ctrl.users.FindByEmail(ctx, input.Email); err == nil {
 		c.JSON(http.StatusConflict, gin.H{"error": "Email already registered"})
 		return
 	}
@@ -67,41 +99,48 @@ func Register(c *gin.Context) {
 		UpdatedAt:  time.Now(),
 	}
 
-	_, err = collection.InsertOne(ctx, user)
-	if err != nil {
+	if err := ctrl.users.Create(ctx, &user); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email already registered"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
 	}
 
-	// Generate JWT token
-	jwtSecret := os.Getenv("JWT_SECRET")
-	token, err := utils.GenerateToken(user.ID.Hex(), user.Email, user.UserType, jwtSecret)
+	// Send the verification link; a mail failure should not undo the signup
+	if err := ctrl.sendVerificationEmail(ctx, &user); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", user.Email, err)
+	}
+
+	// Start a session and issue its tokens
+	token, refreshToken, err := ctrl.startSession(ctx, c, &user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "User registered successfully",
-		"token":   token,
-		"user":    user.ToResponse(),
+		"message":       "User registered successfully",
+		"token":         token,
+		"refresh_token": refreshToken,
+		"expires_in":    int(utils.AccessTokenTTL().Seconds()),
+		"user":          user.ToResponse(),
 	})
 }
 
-func Login(c *gin.Context) {
+func (ctrl *AuthController) Login(c *gin.Context) {
 	var input LoginInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	collection := config.MongoDB.Collection("users")
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	// Find user by email
-	var user models.User
-	err := collection.FindOne(ctx, bson.M{"email": input.Email}).Decode(&user)
+	user, err := ctrl.users.FindByEmail(ctx, input.Email)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 		return
@@ -113,17 +152,344 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	// Generate JWT token
+	if user.IsSuspended() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account suspended"})
+		return
+	}
+
+	// Start a session and issue its tokens
+	token, refreshToken, err := ctrl.startSession(ctx, c, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Login successful",
+		"token":         token,
+		"refresh_token": refreshToken,
+		"expires_in":    int(utils.AccessTokenTTL().Seconds()),
+		"user":          user.ToResponse(),
+	})
+}
+
+// Refresh exchanges a refresh token for a new access token and rotates the
+// refresh token. Presenting a token that was already rotated out is treated as
+// theft and revokes the whole session.
+func (ctrl *AuthController) Refresh(c *gin.Context) {
+	var input RefreshInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	hash := utils.HashToken(input.RefreshToken)
+	session, err := ctrl.sessions.FindByTokenHash(ctx, hash)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	if session.RefreshTokenHash != hash {
+		ctrl.sessions.Revoke(ctx, session.ID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token reuse detected, session revoked"})
+		return
+	}
+
+	if !session.IsActive(time.Now()) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session expired or revoked"})
+		return
+	}
+
+	user, err := ctrl.users.FindByID(ctx, session.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	if user.IsSuspended() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account suspended"})
+		return
+	}
+
+	refreshToken, refreshHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	// Rotation only succeeds if the presented token is still current, so two
+	// concurrent refreshes with the same token cannot both win.
+	if err := ctrl.sessions.Rotate(ctx, session.ID, hash, refreshHash, time.Now().Add(utils.RefreshTokenTTL())); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			ctrl.sessions.Revoke(ctx, session.ID)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token reuse detected, session revoked"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate refresh token"})
+		return
+	}
+
 	jwtSecret := os.Getenv("JWT_SECRET")
-	token, err := utils.GenerateToken(user.ID.Hex(), user.Email, user.UserType, jwtSecret)
+	token, err := utils.GenerateToken(user.ID.Hex(), user.Email, user.UserType, session.ID.Hex(), jwtSecret)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Login successful",
-		"token":   token,
+		"token":         token,
+		"refresh_token": refreshToken,
+		"expires_in":    int(utils.AccessTokenTTL().Seconds()),
+	})
+}
+
+// Logout revokes the session the current access token belongs to.
+func (ctrl *AuthController) Logout(c *gin.Context) {
+	sessionID, err := primitive.ObjectIDFromHex(c.GetString("sessionID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ctrl.sessions.Revoke(ctx, sessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// LogoutAll revokes every session of the current user, signing them out on all devices.
+func (ctrl *AuthController) LogoutAll(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	revoked, err := ctrl.sessions.RevokeAllForUser(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Logged out from all devices",
+		"revoked_sessions": revoked,
+	})
+}
+
+// VerifyEmail marks the account in a signed verification link as verified.
+// The token is accepted from the query string so the emailed link can be
+// opened directly, or from a JSON body.
+func (ctrl *AuthController) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		var input VerifyEmailInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Verification token required"})
+			return
+		}
+		token = input.Token
+	}
+
+	jwtSecret := os.Getenv("JWT_SECRET")
+	claims, err := utils.ValidateActionToken(token, utils.PurposeEmailVerification, jwtSecret)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification link"})
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification link"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := ctrl.users.FindByID(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if user.IsVerified {
+		c.JSON(http.StatusOK, gin.H{"message": "Email already verified"})
+		return
+	}
+
+	user.IsVerified = true
+	user.UpdatedAt = time.Now()
+	if err := ctrl.users.Update(ctx, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email verified successfully",
 		"user":    user.ToResponse(),
 	})
 }
+
+// ResendVerification emails a fresh verification link. It responds the same
+// way whether or not the address is registered, so it cannot be used to
+// discover accounts.
+func (ctrl *AuthController) ResendVerification(c *gin.Context) {
+	var input ResendVerificationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if user, err := ctrl.users.FindByEmail(ctx, input.Email); err == nil && !user.IsVerified {
+		if err := ctrl.sendVerificationEmail(ctx, user); err != nil {
+			log.Printf("Failed to send verification email to %s: %v", user.Email, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the account exists and is unverified, a verification email has been sent"})
+}
+
+// ForgotPassword emails a single-use reset link. Like ResendVerification it
+// responds identically for unknown addresses.
+func (ctrl *AuthController) ForgotPassword(c *gin.Context) {
+	var input ForgotPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if user, err := ctrl.users.FindByEmail(ctx, input.Email); err == nil {
+		if err := ctrl.sendPasswordResetEmail(ctx, user); err != nil {
+			log.Printf("Failed to send password reset email to %s: %v", user.Email, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the account exists, a password reset email has been sent"})
+}
+
+// ResetPassword consumes a reset token, sets the new password and signs the
+// user out everywhere.
+func (ctrl *AuthController) ResetPassword(c *gin.Context) {
+	var input ResetPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	reset, err := ctrl.passwordResets.Consume(ctx, utils.HashToken(input.Token), time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		return
+	}
+
+	user, err := ctrl.users.FindByID(ctx, reset.UserID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(input.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	user.Password = hashedPassword
+	user.UpdatedAt = time.Now()
+	if err := ctrl.users.Update(ctx, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	if _, err := ctrl.sessions.RevokeAllForUser(ctx, user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully, please log in again"})
+}
+
+// sendPasswordResetEmail stores a new reset token for user and emails the link.
+func (ctrl *AuthController) sendPasswordResetEmail(ctx context.Context, user *models.User) error {
+	token, tokenHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	reset := models.PasswordReset{
+		ID:        primitive.NewObjectID(),
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(utils.PasswordResetTTL()),
+		CreatedAt: time.Now(),
+	}
+	if err := ctrl.passwordResets.Create(ctx, &reset); err != nil {
+		return err
+	}
+
+	link := frontendURL() + "/reset-password?token=" + url.QueryEscape(token)
+	return ctrl.mailer.Send(ctx, mailer.PasswordResetEmail(user.Email, user.FirstName, link))
+}
+
+// sendVerificationEmail emails user a signed, expiring verification link.
+func (ctrl *AuthController) sendVerificationEmail(ctx context.Context, user *models.User) error {
+	jwtSecret := os.Getenv("JWT_SECRET")
+	token, err := utils.GenerateActionToken(user.ID.Hex(), utils.PurposeEmailVerification, utils.EmailVerificationTTL(), jwtSecret)
+	if err != nil {
+		return err
+	}
+
+	link := appURL() + "/api/v1/auth/verify?token=" + url.QueryEscape(token)
+	return ctrl.mailer.Send(ctx, mailer.VerificationEmail(user.Email, user.FirstName, link))
+}
+
+// startSession records a new session for user and returns its access and refresh tokens.
+func (ctrl *AuthController) startSession(ctx context.Context, c *gin.Context, user *models.User) (string, string, error) {
+	refreshToken, refreshHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	session := models.Session{
+		ID:               primitive.NewObjectID(),
+		UserID:           user.ID,
+		RefreshTokenHash: refreshHash,
+		UserAgent:        c.Request.UserAgent(),
+		IPAddress:        c.ClientIP(),
+		ExpiresAt:        time.Now().Add(utils.RefreshTokenTTL()),
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+	if err := ctrl.sessions.Create(ctx, &session); err != nil {
+		return "", "", err
+	}
+
+	jwtSecret := os.Getenv("JWT_SECRET")
+	token, err := utils.GenerateToken(user.ID.Hex(), user.Email, user.UserType, session.ID.Hex(), jwtSecret)
+	if err != nil {
+		return "", "", err
+	}
+	return token, refreshToken, nil
+}
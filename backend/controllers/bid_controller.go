@@ -1,38 +1,177 @@
 package controllers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
-	"github.com/Vivekpdy/tasklanceweb/backend/config"
+	"github.com/Vivekpdy/tasklanceweb/backend/escrow"
 	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/notify"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func GetTaskBids(c *gin.Context) {
-	taskID := c.Param("taskId")
+type BidController struct {
+	bids       repository.BidRepository
+	tasks      repository.TaskRepository
+	milestones repository.MilestoneRepository
+	tx         repository.Transactor
+	escrow     *escrow.Service
+	notifier   *notify.Service
+}
+
+func NewBidController(bids repository.BidRepository, tasks repository.TaskRepository, milestones repository.MilestoneRepository, tx repository.Transactor, escrow *escrow.Service, notifier *notify.Service) *BidController {
+	return &BidController{bids: bids, tasks: tasks, milestones: milestones, tx: tx, escrow: escrow, notifier: notifier}
+}
+
+// Reasons a bid acceptance transaction aborts.
+var (
+	errTaskNotOpen   = errors.New("task is no longer open")
+	errBidNotPending = errors.New("bid is no longer pending")
+	errBidChanged    = errors.New("bid changed while being accepted")
+)
+
+// GetTaskBids lists a task's bids. The task owner and admins see every bid,
+// and so does everyone else on open-mode tasks. On sealed and
+// reverse-auction tasks other users only see their own bid, with the number
+// of open bids and, in a reverse auction, the lowest amount to beat. Only
+// the task owner sees its private triage fields, and may narrow the list
+// with ?view=active, shortlisted or archived.
+func (ctrl *BidController) GetTaskBids(c *gin.Context) {
+	taskID, err := primitive.ObjectIDFromHex(c.Param("taskId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	page, err := pageParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	task, err := ctrl.tasks.FindByID(ctx, taskID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	view := c.Query("view")
+	switch view {
+	case "", repository.BidViewActive, repository.BidViewShortlisted, repository.BidViewArchived:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "view must be active, shortlisted or archived"})
+		return
+	}
+
+	if task.ClientID == userID {
+		bids, err := ctrl.bids.FindByTask(ctx, taskID, repository.BidFilter{View: view}, page)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bids"})
+			return
+		}
+		c.JSON(http.StatusOK, clientBidPage(bids))
+		return
+	}
+	if view != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only task owner can filter bids by view"})
+		return
+	}
 
-	var bids []models.Bid
-	if err := config.DB.Preload("Freelancer").Where("task_id = ?", taskID).Find(&bids).Error; err != nil {
+	if !task.IsAuction() || c.GetString("userType") == models.RoleAdmin {
+		bids, err := ctrl.bids.FindByTask(ctx, taskID, repository.BidFilter{}, page)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bids"})
+			return
+		}
+		c.JSON(http.StatusOK, bids)
+		return
+	}
+
+	stats, err := ctrl.bids.OpenBidStats(ctx, taskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bids"})
+		return
+	}
+	items := []models.Bid{}
+	bid, err := ctrl.bids.FindByTaskAndFreelancer(ctx, taskID, userID)
+	if err == nil {
+		items = append(items, *bid)
+	} else if !errors.Is(err, repository.ErrNotFound) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bids"})
 		return
 	}
 
-	c.JSON(http.StatusOK, bids)
+	response := gin.H{
+		"items":       items,
+		"next_cursor": "",
+		"has_more":    false,
+		"open_bids":   stats.Count,
+	}
+	if task.BiddingMode == models.BiddingReverseAuction && stats.Count > 0 {
+		response["lowest_amount"] = stats.LowestAmount
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 type CreateBidInput struct {
-	TaskID           uint    `json:"task_id" binding:"required"`
-	Amount           float64 `json:"amount" binding:"required,gt=0"`
-	ProposedDeadline string  `json:"proposed_deadline" binding:"required"`
-	CoverLetter      string  `json:"cover_letter" binding:"required"`
+	TaskID           string                   `json:"task_id" binding:"required"`
+	Amount           float64                  `json:"amount" binding:"required,gt=0"`
+	ProposedDeadline string                   `json:"proposed_deadline" binding:"required"`
+	CoverLetter      string                   `json:"cover_letter" binding:"required"`
+	Milestones       []ProposedMilestoneInput `json:"milestones" binding:"omitempty,dive"`
 }
 
-func CreateBid(c *gin.Context) {
-	userID := c.GetUint("userID")
-	userType := c.GetString("userType")
+type ProposedMilestoneInput struct {
+	Title   string  `json:"title" binding:"required"`
+	Amount  float64 `json:"amount" binding:"required,gt=0"`
+	DueDate string  `json:"due_date" binding:"required"`
+}
+
+// proposedMilestones converts a bid's milestone proposals, which must add up
+// to exactly the bid amount.
+func proposedMilestones(inputs []ProposedMilestoneInput, amount float64) ([]models.ProposedMilestone, error) {
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+
+	milestones := make([]models.ProposedMilestone, 0, len(inputs))
+	var total int64
+	for _, input := range inputs {
+		dueDate, err := parseDate(input.DueDate)
+		if err != nil {
+			return nil, err
+		}
+		total += models.ToCents(input.Amount)
+		milestones = append(milestones, models.ProposedMilestone{Title: input.Title, Amount: input.Amount, DueDate: dueDate})
+	}
+	if total != models.ToCents(amount) {
+		return nil, errors.New("milestone amounts must add up to the bid amount")
+	}
+	return milestones, nil
+}
 
-	if userType != "freelancer" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only freelancers can create bids"})
+func (ctrl *BidController) CreateBid(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
 		return
 	}
 
@@ -42,32 +181,68 @@ func CreateBid(c *gin.Context) {
 		return
 	}
 
+	taskID, err := primitive.ObjectIDFromHex(input.TaskID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	proposedDeadline, err := parseDate(input.ProposedDeadline)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	milestones, err := proposedMilestones(input.Milestones, input.Amount)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
 	// Check if task exists
-	var task models.Task
-	if err := config.DB.First(&task, input.TaskID).Error; err != nil {
+	task, err := ctrl.tasks.FindByID(ctx, taskID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 		return
 	}
 
+	if task.Status != models.TaskStatusOpen {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Task is not open for bidding"})
+		return
+	}
+	if !task.AcceptsBids(time.Now()) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Bidding on this task has closed"})
+		return
+	}
+	if !ctrl.undercuts(c, ctx, task, input.Amount) {
+		return
+	}
+
 	// Check if bid already exists
-	var existingBid models.Bid
-	if err := config.DB.Where("task_id = ? AND freelancer_id = ?", input.TaskID, userID).First(&existingBid).Error; err == nil {
+	if _, err := ctrl.bids.FindByTaskAndFreelancer(ctx, taskID, userID); err == nil {
 		c.JSON(http.StatusConflict, gin.H{"error": "You have already bid on this task"})
 		return
 	}
 
 	bid := models.Bid{
-		TaskID:       input.TaskID,
-		FreelancerID: userID,
-		Amount:       input.Amount,
-		CoverLetter:  input.CoverLetter,
-		Status:       "pending",
+		ID:               primitive.NewObjectID(),
+		TaskID:           taskID,
+		FreelancerID:     userID,
+		Amount:           input.Amount,
+		ProposedDeadline: proposedDeadline,
+		CoverLetter:      input.CoverLetter,
+		Milestones:       milestones,
 	}
+	bid.Submit(userID, time.Now())
 
-	if err := config.DB.Create(&bid).Error; err != nil {
+	if err := ctrl.bids.Create(ctx, &bid); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create bid"})
 		return
 	}
+	ctrl.notifier.Notify(ctx, notify.BidReceived(task, &bid))
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Bid created successfully",
@@ -75,12 +250,50 @@ func CreateBid(c *gin.Context) {
 	})
 }
 
-func UpdateBid(c *gin.Context) {
-	id := c.Param("id")
-	userID := c.GetUint("userID")
+// undercuts checks that a new bid amount on a reverse auction is below the
+// current lowest open bid, writing the error response and returning false
+// when it is not. Other modes accept any amount.
+func (ctrl *BidController) undercuts(c *gin.Context, ctx context.Context, task *models.Task, amount float64) bool {
+	if task.BiddingMode != models.BiddingReverseAuction {
+		return true
+	}
+
+	stats, err := ctrl.bids.OpenBidStats(ctx, task.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bids"})
+		return false
+	}
+	if stats.Count > 0 && models.ToCents(amount) >= models.ToCents(stats.LowestAmount) {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Bids must be lower than the current lowest bid of $%.2f", stats.LowestAmount)})
+		return false
+	}
+	return true
+}
 
-	var bid models.Bid
-	if err := config.DB.First(&bid, id).Error; err != nil {
+type UpdateBidInput struct {
+	Amount           float64                  `json:"amount" binding:"required,gt=0"`
+	ProposedDeadline string                   `json:"proposed_deadline"`
+	CoverLetter      string                   `json:"cover_letter" binding:"required"`
+	Milestones       []ProposedMilestoneInput `json:"milestones" binding:"omitempty,dive"`
+}
+
+func (ctrl *BidController) UpdateBid(c *gin.Context) {
+	bidID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bid ID"})
+		return
+	}
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	bid, err := ctrl.bids.FindByID(ctx, bidID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Bid not found"})
 		return
 	}
@@ -90,17 +303,55 @@ func UpdateBid(c *gin.Context) {
 		return
 	}
 
-	var input CreateBidInput
+	if bid.Status != models.BidStatusPending {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only pending bids can be updated"})
+		return
+	}
+
+	var input UpdateBidInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	task, err := ctrl.tasks.FindByID(ctx, bid.TaskID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	if !task.AcceptsBids(time.Now()) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Bidding on this task has closed"})
+		return
+	}
+	if models.ToCents(input.Amount) != models.ToCents(bid.Amount) && !ctrl.undercuts(c, ctx, task, input.Amount) {
+		return
+	}
+
+	if input.ProposedDeadline != "" {
+		proposedDeadline, err := parseDate(input.ProposedDeadline)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		bid.ProposedDeadline = proposedDeadline
+	}
+
+	milestones, err := proposedMilestones(input.Milestones, input.Amount)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lastUpdatedAt := bid.UpdatedAt
 	bid.Amount = input.Amount
 	bid.CoverLetter = input.CoverLetter
+	bid.Milestones = milestones
+	if err := bid.Transition(models.BidEventRevise, &userID, "", time.Now()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only pending bids can be updated"})
+		return
+	}
 
-	if err := config.DB.Save(&bid).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update bid"})
+	if !ctrl.saveBid(c, ctx, bid, lastUpdatedAt) {
 		return
 	}
 
@@ -110,39 +361,663 @@ func UpdateBid(c *gin.Context) {
 	})
 }
 
-func AcceptBid(c *gin.Context) {
-	id := c.Param("id")
-	userID := c.GetUint("userID")
+type AcceptBidInput struct {
+	// PaymentSource is the gateway token charged for the bid amount.
+	PaymentSource string `json:"payment_source"`
+}
+
+// AcceptBid charges the client for the bid amount into escrow and assigns the
+// bidder to the task. If the charge fails nothing is assigned. Milestone
+// contracts are not charged upfront: each milestone is funded separately,
+// and milestones proposed with the bid replace any the client defined.
+//
+// Assigning the task, accepting the bid and rejecting the competing bids
+// happen in one transaction that requires the task to still be open, so of
+// two concurrent accepts one fails with 409 and has its charge refunded.
+func (ctrl *BidController) AcceptBid(c *gin.Context) {
+	bidID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bid ID"})
+		return
+	}
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input AcceptBidInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	var bid models.Bid
-	if err := config.DB.Preload("Task").First(&bid, id).Error; err != nil {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	bid, err := ctrl.bids.FindByID(ctx, bidID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Bid not found"})
 		return
 	}
 
+	task, err := ctrl.tasks.FindByID(ctx, bid.TaskID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
 	// Check if user is the task owner
-	if bid.Task.ClientID != userID {
+	if task.ClientID != userID {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Only task owner can accept bids"})
 		return
 	}
 
-	// Update bid status
-	bid.Status = "accepted"
-	if err := config.DB.Save(&bid).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept bid"})
+	if !task.CanTransition(models.TaskEventAcceptBid) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Task is no longer open"})
+		return
+	}
+	if task.AwaitingClose() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Bids can be accepted once bidding closes"})
+		return
+	}
+	if bid.Status != models.BidStatusPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Bid is no longer pending"})
 		return
 	}
 
-	// Update task status and assign freelancer
-	bid.Task.Status = "in_progress"
-	bid.Task.FreelancerID = &bid.FreelancerID
-	if err := config.DB.Save(&bid.Task).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task"})
+	milestones, err := ctrl.milestones.FindByTask(ctx, task.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch milestones"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	// The payment names the bidder as payee; the task itself is only
+	// assigned inside the transaction
+	task.FreelancerID = &bid.FreelancerID
+
+	var payment *models.Payment
+	if len(milestones) == 0 && len(bid.Milestones) == 0 {
+		payment, err = ctrl.escrow.Fund(ctx, task, bid.Amount, input.PaymentSource)
+		if err != nil {
+			fundingError(c, err)
+			return
+		}
+	}
+
+	var result *acceptance
+	err = ctrl.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = ctrl.accept(ctx, bid, userID, time.Now())
+		return err
+	})
+	if err != nil {
+		if payment != nil {
+			if refundErr := ctrl.escrow.RefundPayment(ctx, payment); refundErr != nil {
+				log.Printf("Failed to refund payment %s after a failed bid acceptance: %v", payment.ID.Hex(), refundErr)
+			}
+		}
+		switch {
+		case errors.Is(err, errTaskNotOpen):
+			c.JSON(http.StatusConflict, gin.H{"error": "Task is no longer open"})
+		case errors.Is(err, errBidNotPending):
+			c.JSON(http.StatusConflict, gin.H{"error": "Bid is no longer pending"})
+		case errors.Is(err, errBidChanged):
+			c.JSON(http.StatusConflict, gin.H{"error": "Bid was changed by the freelancer, please review it and retry"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept bid"})
+		}
+		return
+	}
+
+	ctrl.notifier.Notify(ctx, notify.BidAccepted(result.task, result.bid))
+	for i := range result.rejected {
+		ctrl.notifier.Notify(ctx, notify.BidRejected(result.task, &result.rejected[i], "another bid was accepted"))
+	}
+
+	if result.milestones != nil {
+		milestones = result.milestones
+	}
+	response := gin.H{
 		"message": "Bid accepted successfully",
+		"bid":     result.bid,
+	}
+	if payment != nil {
+		response["payment"] = payment
+	}
+	if len(milestones) > 0 {
+		response["milestones"] = milestones
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// acceptance is what a bid acceptance transaction changed.
+type acceptance struct {
+	task       *models.Task
+	bid        *models.Bid
+	rejected   []models.Bid
+	milestones []models.Milestone // replaced from the bid's proposal, if any
+}
+
+// accept is the body of the acceptance transaction. It rereads the task and
+// bid inside the transaction, and its first write is the conditional task
+// update, so a concurrent accept aborts before anything else changes.
+// expected is the bid as the client saw it when the charge was made.
+func (ctrl *BidController) accept(ctx context.Context, expected *models.Bid, userID primitive.ObjectID, now time.Time) (*acceptance, error) {
+	bid, err := ctrl.bids.FindByID(ctx, expected.ID)
+	if err != nil {
+		return nil, err
+	}
+	if bid.Status != models.BidStatusPending {
+		return nil, errBidNotPending
+	}
+	if !bid.UpdatedAt.Equal(expected.UpdatedAt) {
+		return nil, errBidChanged
+	}
+	lastUpdatedAt := bid.UpdatedAt
+
+	task, err := ctrl.tasks.FindByID(ctx, bid.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := task.Transition(models.TaskEventAcceptBid, &userID, "", now); err != nil {
+		return nil, errTaskNotOpen
+	}
+	task.FreelancerID = &bid.FreelancerID
+
+	// The bid is written first: it is the write most likely to conflict, with
+	// a freelancer editing it outside any transaction, and stores that cannot
+	// roll back must not have assigned the task by then.
+	if err := bid.Transition(models.BidEventAccept, &userID, "", now); err != nil {
+		return nil, errBidNotPending
+	}
+	if err := ctrl.bids.UpdateIfUnchanged(ctx, bid, lastUpdatedAt); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, errBidChanged
+		}
+		return nil, err
+	}
+
+	open, err := ctrl.bids.FindByTaskAndStatus(ctx, task.ID, models.OpenBidStatuses...)
+	if err != nil {
+		return nil, err
+	}
+	siblings := make([]models.Bid, 0, len(open))
+	for _, sibling := range open {
+		if sibling.ID != bid.ID {
+			siblings = append(siblings, sibling)
+		}
+	}
+	if len(siblings) > 0 {
+		task.Record(models.TaskEventRejectBids, &userID, fmt.Sprintf("Rejected %d open bid(s) because another bid was accepted", len(siblings)), now)
+	}
+
+	if err := ctrl.tasks.UpdateIfStatus(ctx, task, models.TaskStatusOpen); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, errTaskNotOpen
+		}
+		return nil, err
+	}
+
+	// A sibling changed by its freelancer in the meantime is left for the
+	// deadline scan, which rejects open bids on tasks that are not open.
+	result := &acceptance{task: task, bid: bid}
+	for i := range siblings {
+		sibling := &siblings[i]
+		siblingUpdatedAt := sibling.UpdatedAt
+		if err := sibling.Transition(models.BidEventReject, &userID, "Rejected because another bid was accepted", now); err != nil {
+			continue
+		}
+		if err := ctrl.bids.UpdateIfUnchanged(ctx, sibling, siblingUpdatedAt); err != nil {
+			if errors.Is(err, repository.ErrConflict) {
+				continue
+			}
+			return nil, err
+		}
+		result.rejected = append(result.rejected, *sibling)
+	}
+
+	if len(bid.Milestones) > 0 {
+		if result.milestones, err = ctrl.replaceMilestones(ctx, task, bid); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// replaceMilestones turns the accepted bid's proposals into the task's
+// milestones. The task was open until now, so none of the milestones it
+// replaces can have been funded.
+func (ctrl *BidController) replaceMilestones(ctx context.Context, task *models.Task, bid *models.Bid) ([]models.Milestone, error) {
+	if err := ctrl.milestones.DeleteByTask(ctx, task.ID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	milestones := make([]models.Milestone, 0, len(bid.Milestones))
+	for _, proposed := range bid.Milestones {
+		milestone := models.Milestone{
+			ID:        primitive.NewObjectID(),
+			TaskID:    task.ID,
+			Title:     proposed.Title,
+			Amount:    proposed.Amount,
+			DueDate:   proposed.DueDate,
+			Status:    models.MilestoneStatusPending,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := ctrl.milestones.Create(ctx, &milestone); err != nil {
+			return nil, err
+		}
+		milestones = append(milestones, milestone)
+	}
+	return milestones, nil
+}
+
+// saveBid stores a negotiation or triage step on bid unless somebody else
+// changed the bid since it was loaded, writing the error response and
+// returning false when it could not be saved.
+func (ctrl *BidController) saveBid(c *gin.Context, ctx context.Context, bid *models.Bid, lastUpdatedAt time.Time) bool {
+	if err := ctrl.bids.UpdateIfUnchanged(ctx, bid, lastUpdatedAt); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Bid was changed by someone else, reload it and retry"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update bid"})
+		return false
+	}
+	return true
+}
+
+// loadNegotiation loads the bid in the :id parameter and its task for a
+// negotiation step, writing the error response and returning ok false when
+// either is missing.
+func (ctrl *BidController) loadNegotiation(c *gin.Context, ctx context.Context) (bid *models.Bid, task *models.Task, ok bool) {
+	bidID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bid ID"})
+		return nil, nil, false
+	}
+
+	bid, err = ctrl.bids.FindByID(ctx, bidID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bid not found"})
+		return nil, nil, false
+	}
+
+	task, err = ctrl.tasks.FindByID(ctx, bid.TaskID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return nil, nil, false
+	}
+	return bid, task, true
+}
+
+type NegotiationNoteInput struct {
+	Note string `json:"note" binding:"max=1000"`
+}
+
+// WithdrawBid lets a freelancer take back a pending or countered bid. A
+// withdrawn bid is final; it cannot be reopened or bid again.
+func (ctrl *BidController) WithdrawBid(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input NegotiationNoteInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	bid, task, ok := ctrl.loadNegotiation(c, ctx)
+	if !ok {
+		return
+	}
+
+	if bid.FreelancerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only withdraw your own bids"})
+		return
+	}
+
+	lastUpdatedAt := bid.UpdatedAt
+	if err := bid.Transition(models.BidEventWithdraw, &userID, input.Note, time.Now()); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only pending or countered bids can be withdrawn"})
+		return
+	}
+	if !ctrl.saveBid(c, ctx, bid, lastUpdatedAt) {
+		return
+	}
+	ctrl.notifier.Notify(ctx, notify.BidWithdrawn(task, bid))
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Bid withdrawn successfully",
+		"bid":     bid,
+	})
+}
+
+type CounterBidInput struct {
+	Amount           float64 `json:"amount" binding:"required,gt=0"`
+	ProposedDeadline string  `json:"proposed_deadline" binding:"required"`
+	Note             string  `json:"note" binding:"max=1000"`
+}
+
+// CounterBid records the task owner's counter-offer on a pending bid. The
+// bid keeps its terms until the freelancer accepts the offer. The amount of
+// a bid with proposed milestones cannot be countered, since the milestones
+// must add up to it.
+func (ctrl *BidController) CounterBid(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input CounterBidInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	proposedDeadline, err := parseDate(input.ProposedDeadline)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	bid, task, ok := ctrl.loadNegotiation(c, ctx)
+	if !ok {
+		return
+	}
+
+	if task.ClientID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only task owner can counter bids"})
+		return
+	}
+	if task.Status != models.TaskStatusOpen {
+		c.JSON(http.StatusConflict, gin.H{"error": "Task is no longer open"})
+		return
+	}
+	if task.AwaitingClose() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Bids can be countered once bidding closes"})
+		return
+	}
+	if len(bid.Milestones) > 0 && models.ToCents(input.Amount) != models.ToCents(bid.Amount) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The amount of a bid with proposed milestones cannot be countered"})
+		return
+	}
+
+	lastUpdatedAt := bid.UpdatedAt
+	if err := bid.Counter(userID, input.Amount, proposedDeadline, input.Note, time.Now()); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only pending bids can be countered"})
+		return
+	}
+	if !ctrl.saveBid(c, ctx, bid, lastUpdatedAt) {
+		return
+	}
+	ctrl.notifier.Notify(ctx, notify.BidCountered(task, bid, bid.PendingCounter()))
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Counter-offer sent successfully",
+		"bid":     bid,
+	})
+}
+
+// AcceptCounter lets the freelancer take the task owner's counter-offer,
+// which becomes the bid's terms.
+func (ctrl *BidController) AcceptCounter(c *gin.Context) {
+	ctrl.answerCounter(c, models.BidEventAcceptCounter)
+}
+
+// DeclineCounter lets the freelancer turn down the task owner's
+// counter-offer, leaving the bid on its own terms.
+func (ctrl *BidController) DeclineCounter(c *gin.Context) {
+	ctrl.answerCounter(c, models.BidEventDeclineCounter)
+}
+
+func (ctrl *BidController) answerCounter(c *gin.Context, event string) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input NegotiationNoteInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	bid, task, ok := ctrl.loadNegotiation(c, ctx)
+	if !ok {
+		return
+	}
+
+	if bid.FreelancerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only answer counter-offers on your own bids"})
+		return
+	}
+	if task.Status != models.TaskStatusOpen {
+		c.JSON(http.StatusConflict, gin.H{"error": "Task is no longer open"})
+		return
+	}
+
+	lastUpdatedAt := bid.UpdatedAt
+	if err := bid.Transition(event, &userID, input.Note, time.Now()); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Bid has no counter-offer to answer"})
+		return
+	}
+	if !ctrl.saveBid(c, ctx, bid, lastUpdatedAt) {
+		return
+	}
+	accepted := event == models.BidEventAcceptCounter
+	ctrl.notifier.Notify(ctx, notify.CounterAnswered(task, bid, accepted))
+
+	message := "Counter-offer declined"
+	if accepted {
+		message = "Counter-offer accepted"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
 		"bid":     bid,
 	})
 }
+
+// clientBidPage converts a page of bids to the task owner's view of them.
+func clientBidPage(bids *pagination.Page[models.Bid]) *pagination.Page[models.ClientBid] {
+	items := make([]models.ClientBid, len(bids.Items))
+	for i := range bids.Items {
+		items[i] = bids.Items[i].ForClient()
+	}
+	return &pagination.Page[models.ClientBid]{Items: items, NextCursor: bids.NextCursor, HasMore: bids.HasMore}
+}
+
+type TriageBidInput struct {
+	Shortlisted *bool   `json:"shortlisted"`
+	Archived    *bool   `json:"archived"`
+	Note        *string `json:"note" binding:"omitempty,max=2000"`
+}
+
+// TriageBid lets the task owner shortlist, archive and annotate a bid.
+// Freelancers see whether their bid is shortlisted, as they do when an
+// auction shortlists it, but the archive and the note are private to the
+// owner. Auction bids can be triaged once bidding closes.
+func (ctrl *BidController) TriageBid(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input TriageBidInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Shortlisted == nil && input.Archived == nil && input.Note == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provide shortlisted, archived or note"})
+		return
+	}
+	if input.Shortlisted != nil && input.Archived != nil && *input.Shortlisted && *input.Archived {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A bid cannot be both shortlisted and archived"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	bid, task, ok := ctrl.loadNegotiation(c, ctx)
+	if !ok {
+		return
+	}
+
+	if task.ClientID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only task owner can triage bids"})
+		return
+	}
+	if task.AwaitingClose() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Bids can be triaged once bidding closes"})
+		return
+	}
+
+	lastUpdatedAt := bid.UpdatedAt
+	if err := bid.Triage(input.Shortlisted, input.Archived, input.Note, time.Now()); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only pending or countered bids can be shortlisted"})
+		return
+	}
+	if !ctrl.saveBid(c, ctx, bid, lastUpdatedAt) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Bid updated successfully",
+		"bid":     bid.ForClient(),
+	})
+}
+
+// maxComparedBids caps how many bids one comparison may include.
+const maxComparedBids = 10
+
+type bidComparison struct {
+	Bid            models.ClientBid              `json:"bid"`
+	Freelancer     repository.ComparedFreelancer `json:"freelancer"`
+	MatchedSkills  []string                      `json:"matched_skills"`
+	SkillOverlap   float64                       `json:"skill_overlap"` // share of the required skills matched, 1 when none are required
+	CompletedTasks int                           `json:"completed_tasks"`
+	OnTimeRate     *float64                      `json:"on_time_rate"` // null without completed tasks
+}
+
+// CompareBids returns the selected bids side by side, each with its
+// freelancer's rating, skill overlap with the task's required skills,
+// completed-task count and on-time delivery rate. Bids are selected with
+// ?ids=a,b,c and default to the shortlist.
+func (ctrl *BidController) CompareBids(c *gin.Context) {
+	taskID, err := primitive.ObjectIDFromHex(c.Param("taskId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	// IDs may be repeated or comma separated: ids=a&ids=b or ids=a,b
+	var bidIDs []primitive.ObjectID
+	for _, value := range c.QueryArray("ids") {
+		for _, hex := range strings.Split(value, ",") {
+			if hex = strings.TrimSpace(hex); hex == "" {
+				continue
+			}
+			bidID, err := primitive.ObjectIDFromHex(hex)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bid ID " + hex})
+				return
+			}
+			if !slices.Contains(bidIDs, bidID) {
+				bidIDs = append(bidIDs, bidID)
+			}
+		}
+	}
+	if len(bidIDs) > maxComparedBids {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d bids can be compared", maxComparedBids)})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	task, err := ctrl.tasks.FindByID(ctx, taskID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	if task.ClientID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only task owner can compare bids"})
+		return
+	}
+
+	if len(bidIDs) == 0 {
+		shortlist, err := ctrl.bids.FindByTask(ctx, taskID, repository.BidFilter{View: repository.BidViewShortlisted}, pagination.Params{Limit: maxComparedBids})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bids"})
+			return
+		}
+		for _, bid := range shortlist.Items {
+			bidIDs = append(bidIDs, bid.ID)
+		}
+		if len(bidIDs) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Select bids with ids or shortlist some first"})
+			return
+		}
+	}
+
+	comparisons, err := ctrl.bids.Compare(ctx, taskID, bidIDs, task.RequiredSkills)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compare bids"})
+		return
+	}
+	if len(comparisons) != len(bidIDs) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Some bids were not found on this task"})
+		return
+	}
+
+	required := len(models.NormalizeSkills(task.RequiredSkills))
+	items := make([]bidComparison, len(comparisons))
+	for i := range comparisons {
+		comparison := &comparisons[i]
+		item := bidComparison{
+			Bid:            comparison.Bid.ForClient(),
+			Freelancer:     comparison.Freelancer,
+			MatchedSkills:  comparison.MatchedSkills,
+			SkillOverlap:   1,
+			CompletedTasks: comparison.CompletedTasks,
+		}
+		if required > 0 {
+			item.SkillOverlap = float64(len(comparison.MatchedSkills)) / float64(required)
+		}
+		if comparison.CompletedTasks > 0 {
+			rate := float64(comparison.OnTimeTasks) / float64(comparison.CompletedTasks)
+			item.OnTimeRate = &rate
+		}
+		items[i] = item
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"task_id":         task.ID,
+		"required_skills": task.RequiredSkills,
+		"items":           items,
+	})
+}
@@ -0,0 +1,477 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/chat"
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	errEmptyMessage   = errors.New("empty message")
+	errMessageTooLong = errors.New("message too long")
+)
+
+type ChatController struct {
+	conversations repository.ConversationRepository
+	messages      repository.MessageRepository
+	tasks         repository.TaskRepository
+	bids          repository.BidRepository
+	hub           *chat.Hub
+	upgrader      *websocket.Upgrader
+}
+
+func NewChatController(conversations repository.ConversationRepository, messages repository.MessageRepository, tasks repository.TaskRepository, bids repository.BidRepository, hub *chat.Hub) *ChatController {
+	return &ChatController{
+		conversations: conversations,
+		messages:      messages,
+		tasks:         tasks,
+		bids:          bids,
+		hub:           hub,
+		upgrader:      chat.NewUpgrader(frontendURL()),
+	}
+}
+
+// GetTaskConversations lists the task's conversations the caller takes part
+// in: all of them for the task owner, their own for a freelancer. Each
+// carries the caller's unread count.
+func (ctrl *ChatController) GetTaskConversations(c *gin.Context) {
+	taskID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	page, err := pageParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	task, err := ctrl.tasks.FindByID(ctx, taskID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	conversations := &pagination.Page[models.Conversation]{Items: []models.Conversation{}}
+	if isTaskOwner(task, userID) {
+		conversations, err = ctrl.conversations.FindByTask(ctx, task.ID, page)
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch conversations"})
+			return
+		}
+	} else {
+		conversation, err := ctrl.conversations.FindByTaskAndFreelancer(ctx, task.ID, userID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch conversations"})
+			return
+		}
+		// A freelancer has at most one conversation per task
+		if conversation != nil && page.After == nil {
+			conversations.Items = append(conversations.Items, *conversation)
+		}
+	}
+
+	for i := range conversations.Items {
+		unread, err := ctrl.messages.CountUnread(ctx, conversations.Items[i].ID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch conversations"})
+			return
+		}
+		conversations.Items[i].Unread = unread
+	}
+
+	c.JSON(http.StatusOK, conversations)
+}
+
+type StartConversationInput struct {
+	// FreelancerID is required when the task owner starts the conversation.
+	FreelancerID string `json:"freelancer_id"`
+}
+
+// StartConversation opens the conversation between the task owner and a
+// freelancer who bid on the task or is assigned to it, or returns the one
+// that already exists. Either side may start it.
+func (ctrl *ChatController) StartConversation(c *gin.Context) {
+	taskID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input StartConversationInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	task, err := ctrl.tasks.FindByID(ctx, taskID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	freelancerID := userID
+	if isTaskOwner(task, userID) {
+		freelancerID, err = primitive.ObjectIDFromHex(input.FreelancerID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "freelancer_id is required"})
+			return
+		}
+	}
+
+	eligible, err := ctrl.canChat(ctx, task, freelancerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start conversation"})
+		return
+	}
+	if !eligible {
+		if freelancerID == userID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only the task owner, its bidders and the assigned freelancer can chat about a task"})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "That freelancer has not bid on this task"})
+		}
+		return
+	}
+
+	existing, err := ctrl.conversations.FindByTaskAndFreelancer(ctx, task.ID, freelancerID)
+	if err == nil {
+		c.JSON(http.StatusOK, existing)
+		return
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start conversation"})
+		return
+	}
+
+	now := time.Now()
+	conversation := models.Conversation{
+		ID:           primitive.NewObjectID(),
+		TaskID:       task.ID,
+		ClientID:     task.ClientID,
+		FreelancerID: freelancerID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := ctrl.conversations.Create(ctx, &conversation); err != nil {
+		// The other side started it at the same moment
+		if errors.Is(err, repository.ErrDuplicate) {
+			if existing, err := ctrl.conversations.FindByTaskAndFreelancer(ctx, task.ID, freelancerID); err == nil {
+				c.JSON(http.StatusOK, existing)
+				return
+			}
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start conversation"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, conversation)
+}
+
+// GetMessages pages through a conversation's history, newest first.
+func (ctrl *ChatController) GetMessages(c *gin.Context) {
+	params, err := pageParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conversation, _, ok := ctrl.load(ctx, c)
+	if !ok {
+		return
+	}
+
+	page, err := ctrl.messages.FindByConversation(ctx, conversation.ID, params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch messages"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+type SendMessageInput struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// SendMessage posts a message over REST, for clients without a socket open.
+// It is delivered to open connections like one sent over the socket.
+func (ctrl *ChatController) SendMessage(c *gin.Context) {
+	var input SendMessageInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conversation, userID, ok := ctrl.load(ctx, c)
+	if !ok {
+		return
+	}
+
+	message, err := ctrl.post(ctx, conversation, userID, input.Body)
+	if err != nil {
+		if problem := messageProblem(err); problem != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": problem})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, message)
+}
+
+type MarkReadInput struct {
+	// MessageID is the newest message read; every received message when empty.
+	MessageID string `json:"message_id"`
+}
+
+// MarkRead records that the caller has read the conversation up to a message
+// and sends a read receipt to the other side.
+func (ctrl *ChatController) MarkRead(c *gin.Context) {
+	var input MarkReadInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conversation, userID, ok := ctrl.load(ctx, c)
+	if !ok {
+		return
+	}
+
+	marked, err := ctrl.markRead(ctx, conversation, userID, input.MessageID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark messages as read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"marked": marked})
+}
+
+// Connect upgrades the request to a WebSocket joined to the conversation.
+// Clients send message, typing and read frames and receive the same events
+// from both participants; see the chat package for the frame format.
+func (ctrl *ChatController) Connect(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	conversation, userID, ok := ctrl.load(ctx, c)
+	cancel()
+	if !ok {
+		return
+	}
+
+	conn, err := ctrl.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already replied with an HTTP error
+		return
+	}
+
+	ctrl.hub.Serve(conn, conversation.ID, userID, func(client *chat.Client, frame chat.Frame) {
+		ctrl.handleFrame(conversation, client, frame)
+	})
+}
+
+// handleFrame applies one frame received over a conversation's socket.
+func (ctrl *ChatController) handleFrame(conversation *models.Conversation, client *chat.Client, frame chat.Frame) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	reply := func(message string) {
+		client.Send(chat.Event{Type: chat.EventError, ConversationID: conversation.ID, Error: message})
+	}
+
+	switch frame.Type {
+	case chat.EventMessage:
+		if _, err := ctrl.post(ctx, conversation, client.UserID, frame.Body); err != nil {
+			if problem := messageProblem(err); problem != "" {
+				reply(problem)
+				return
+			}
+			log.Printf("Failed to store chat message in conversation %s: %v", conversation.ID.Hex(), err)
+			reply("Failed to send message")
+		}
+	case chat.EventTyping:
+		typing := frame.Typing
+		ctrl.hub.BroadcastOthers(chat.Event{
+			Type:           chat.EventTyping,
+			ConversationID: conversation.ID,
+			UserID:         &client.UserID,
+			Typing:         &typing,
+		}, client.UserID)
+	case chat.EventRead:
+		if _, err := ctrl.markRead(ctx, conversation, client.UserID, frame.MessageID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				reply("Message not found")
+				return
+			}
+			reply("Failed to mark messages as read")
+		}
+	default:
+		reply("Unknown frame type")
+	}
+}
+
+// post stores a message and delivers it to the conversation's connections.
+func (ctrl *ChatController) post(ctx context.Context, conversation *models.Conversation, senderID primitive.ObjectID, body string) (*models.Message, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, errEmptyMessage
+	}
+	if len(body) > models.MaxMessageLength {
+		return nil, errMessageTooLong
+	}
+
+	message := &models.Message{
+		ID:             primitive.NewObjectID(),
+		ConversationID: conversation.ID,
+		SenderID:       senderID,
+		Body:           body,
+		CreatedAt:      time.Now(),
+	}
+	if err := ctrl.messages.Create(ctx, message); err != nil {
+		return nil, err
+	}
+	if err := ctrl.conversations.Touch(ctx, conversation.ID, message.CreatedAt); err != nil {
+		log.Printf("Failed to update conversation %s: %v", conversation.ID.Hex(), err)
+	}
+
+	ctrl.hub.Broadcast(chat.Event{Type: chat.EventMessage, ConversationID: conversation.ID, Message: message})
+	return message, nil
+}
+
+// messageProblem describes a message body post rejected, or returns "" for
+// storage errors.
+func messageProblem(err error) string {
+	switch {
+	case errors.Is(err, errEmptyMessage):
+		return "Message body is required"
+	case errors.Is(err, errMessageTooLong):
+		return fmt.Sprintf("Message body must be at most %d bytes", models.MaxMessageLength)
+	}
+	return ""
+}
+
+// markRead marks the messages readerID received up to messageID (all of them
+// when empty) as read and broadcasts a receipt when any changed.
+func (ctrl *ChatController) markRead(ctx context.Context, conversation *models.Conversation, readerID primitive.ObjectID, messageID string) (int64, error) {
+	now := time.Now()
+	through := now
+	var throughID *primitive.ObjectID
+	if messageID != "" {
+		id, err := primitive.ObjectIDFromHex(messageID)
+		if err != nil {
+			return 0, repository.ErrNotFound
+		}
+		message, err := ctrl.messages.FindByID(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+		if message.ConversationID != conversation.ID {
+			return 0, repository.ErrNotFound
+		}
+		through = message.CreatedAt
+		throughID = &message.ID
+	}
+
+	marked, err := ctrl.messages.MarkRead(ctx, conversation.ID, readerID, through, now)
+	if err != nil {
+		return 0, err
+	}
+	if marked > 0 {
+		ctrl.hub.Broadcast(chat.Event{
+			Type:           chat.EventRead,
+			ConversationID: conversation.ID,
+			UserID:         &readerID,
+			ReadAt:         &now,
+			Through:        throughID,
+		})
+	}
+	return marked, nil
+}
+
+// canChat reports whether freelancerID may talk to the owner about task:
+// they must have bid on it or be assigned to it.
+func (ctrl *ChatController) canChat(ctx context.Context, task *models.Task, freelancerID primitive.ObjectID) (bool, error) {
+	if isTaskOwner(task, freelancerID) {
+		return false, nil
+	}
+	if isAssignedFreelancer(task, freelancerID) {
+		return true, nil
+	}
+	_, err := ctrl.bids.FindByTaskAndFreelancer(ctx, task.ID, freelancerID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// load fetches the conversation named in the path and checks that the caller
+// takes part in it, writing the error response when not.
+func (ctrl *ChatController) load(ctx context.Context, c *gin.Context) (*models.Conversation, primitive.ObjectID, bool) {
+	conversationID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conversation ID"})
+		return nil, primitive.NilObjectID, false
+	}
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return nil, primitive.NilObjectID, false
+	}
+
+	conversation, err := ctrl.conversations.FindByID(ctx, conversationID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return nil, primitive.NilObjectID, false
+	}
+	if !conversation.HasParticipant(userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not part of this conversation"})
+		return nil, primitive.NilObjectID, false
+	}
+	return conversation, userID, true
+}
@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/escrow"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// currentUserID returns the authenticated user's ID as set by AuthMiddleware.
+func currentUserID(c *gin.Context) (primitive.ObjectID, error) {
+	return primitive.ObjectIDFromHex(c.GetString("userID"))
+}
+
+// pageParams reads the limit and cursor query parameters shared by list endpoints.
+func pageParams(c *gin.Context) (pagination.Params, error) {
+	return pagination.Parse(c.Query("limit"), c.Query("cursor"))
+}
+
+// fundingError reports a failed escrow funding. Gateway failures become 402
+// with the provider's reason so the client can retry with another source.
+func fundingError(c *gin.Context, err error) {
+	if errors.Is(err, escrow.ErrPaymentFailed) {
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fund escrow"})
+}
+
+// parseDate accepts either an RFC 3339 timestamp or a plain YYYY-MM-DD date.
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, errors.New("invalid date format, expected RFC3339 or YYYY-MM-DD")
+}
+
+// appURL is the public base URL of the API, used to build links in emails.
+func appURL() string {
+	if url := os.Getenv("APP_URL"); url != "" {
+		return strings.TrimRight(url, "/")
+	}
+	return "http://localhost:8080"
+}
+
+// frontendURL is the public URL of the web app, used for links that open a page.
+func frontendURL() string {
+	if url := os.Getenv("FRONTEND_URL"); url != "" {
+		return strings.TrimRight(url, "/")
+	}
+	return "http://localhost:5173"
+}
@@ -0,0 +1,386 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/escrow"
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MilestoneController struct {
+	milestones repository.MilestoneRepository
+	tasks      repository.TaskRepository
+	tx         repository.Transactor
+	escrow     *escrow.Service
+}
+
+func NewMilestoneController(milestones repository.MilestoneRepository, tasks repository.TaskRepository, tx repository.Transactor, escrow *escrow.Service) *MilestoneController {
+	return &MilestoneController{milestones: milestones, tasks: tasks, tx: tx, escrow: escrow}
+}
+
+// GetTaskMilestones lists a task's milestones with the progress computed from them.
+func (ctrl *MilestoneController) GetTaskMilestones(c *gin.Context) {
+	taskID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	milestones, err := ctrl.milestones.FindByTask(ctx, taskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch milestones"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":    milestones,
+		"progress": models.MilestoneProgress(milestones),
+	})
+}
+
+type MilestoneInput struct {
+	Title       string  `json:"title" binding:"required"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount" binding:"required,gt=0"`
+	DueDate     string  `json:"due_date" binding:"required"`
+}
+
+// CreateMilestone adds a milestone to a task that is open or being worked on.
+func (ctrl *MilestoneController) CreateMilestone(c *gin.Context) {
+	taskID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input MilestoneInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dueDate, err := parseDate(input.DueDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	task, err := ctrl.tasks.FindByID(ctx, taskID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	if !isTaskOwner(task, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only add milestones to your own tasks"})
+		return
+	}
+
+	if task.Status != models.TaskStatusOpen && task.Status != models.TaskStatusInProgress {
+		c.JSON(http.StatusConflict, gin.H{"error": "Milestones can only be added while the task is open or in progress"})
+		return
+	}
+
+	now := time.Now()
+	milestone := models.Milestone{
+		ID:          primitive.NewObjectID(),
+		TaskID:      task.ID,
+		Title:       input.Title,
+		Description: input.Description,
+		Amount:      input.Amount,
+		DueDate:     dueDate,
+		Status:      models.MilestoneStatusPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := ctrl.milestones.Create(ctx, &milestone); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create milestone"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "Milestone created successfully",
+		"milestone": milestone,
+	})
+}
+
+// UpdateMilestone edits a milestone that has not been funded yet.
+func (ctrl *MilestoneController) UpdateMilestone(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input MilestoneInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dueDate, err := parseDate(input.DueDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	milestone, task, ok := ctrl.load(ctx, c)
+	if !ok {
+		return
+	}
+
+	if !isTaskOwner(task, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update milestones of your own tasks"})
+		return
+	}
+
+	if milestone.Status != models.MilestoneStatusPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only milestones that have not been funded can be updated"})
+		return
+	}
+
+	milestone.Title = input.Title
+	milestone.Description = input.Description
+	milestone.Amount = input.Amount
+	milestone.DueDate = dueDate
+	milestone.UpdatedAt = time.Now()
+
+	if err := ctrl.milestones.UpdateIfStatus(ctx, milestone, models.MilestoneStatusPending); err != nil {
+		milestoneSaveError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Milestone updated successfully",
+		"milestone": milestone,
+	})
+}
+
+// DeleteMilestone removes a milestone that has not been funded yet.
+func (ctrl *MilestoneController) DeleteMilestone(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	milestone, task, ok := ctrl.load(ctx, c)
+	if !ok {
+		return
+	}
+
+	if !isTaskOwner(task, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete milestones of your own tasks"})
+		return
+	}
+
+	if milestone.Status != models.MilestoneStatusPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only milestones that have not been funded can be deleted"})
+		return
+	}
+
+	if err := ctrl.milestones.Delete(ctx, milestone.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete milestone"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Milestone deleted successfully"})
+}
+
+type FundMilestoneInput struct {
+	// PaymentSource is the gateway token charged for the milestone amount.
+	PaymentSource string `json:"payment_source"`
+}
+
+// FundMilestone charges the milestone amount into escrow. The task must have
+// an assigned freelancer; if the charge fails the milestone stays pending.
+func (ctrl *MilestoneController) FundMilestone(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input FundMilestoneInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	milestone, task, ok := ctrl.load(ctx, c)
+	if !ok {
+		return
+	}
+
+	if !isTaskOwner(task, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the task owner can fund milestones"})
+		return
+	}
+
+	if task.Status != models.TaskStatusInProgress && task.Status != models.TaskStatusSubmitted {
+		c.JSON(http.StatusConflict, gin.H{"error": "Milestones can only be funded while the task is in progress"})
+		return
+	}
+
+	if err := milestone.Transition(models.MilestoneEventFund, &userID, "", time.Now()); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot fund a milestone that is " + milestone.Status})
+		return
+	}
+
+	payment, err := ctrl.escrow.FundMilestone(ctx, task, milestone, input.PaymentSource)
+	if err != nil {
+		fundingError(c, err)
+		return
+	}
+
+	// A concurrent funding of the same milestone loses here and is refunded
+	if err := ctrl.milestones.UpdateIfStatus(ctx, milestone, models.MilestoneStatusPending); err != nil {
+		if refundErr := ctrl.escrow.RefundPayment(ctx, payment); refundErr != nil {
+			log.Printf("Failed to refund payment %s after a failed milestone funding: %v", payment.ID.Hex(), refundErr)
+		}
+		milestoneSaveError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Milestone funded successfully",
+		"milestone": milestone,
+		"payment":   payment,
+	})
+}
+
+// SubmitMilestone hands in the work for a funded milestone.
+func (ctrl *MilestoneController) SubmitMilestone(c *gin.Context) {
+	ctrl.transition(c, models.MilestoneEventSubmit, isAssignedFreelancer, "Only the assigned freelancer can submit milestones")
+}
+
+// RequestMilestoneRevision sends a submitted milestone back to the freelancer.
+func (ctrl *MilestoneController) RequestMilestoneRevision(c *gin.Context) {
+	ctrl.transition(c, models.MilestoneEventRequestRevision, isTaskOwner, "Only the task owner can review milestones")
+}
+
+// ApproveMilestone accepts a milestone and schedules the release of its
+// escrowed funds.
+func (ctrl *MilestoneController) ApproveMilestone(c *gin.Context) {
+	ctrl.transition(c, models.MilestoneEventApprove, isTaskOwner, "Only the task owner can review milestones")
+}
+
+// transition applies a milestone event on behalf of the current user, who
+// must pass allowed. The optional JSON body carries a note for the history.
+func (ctrl *MilestoneController) transition(c *gin.Context, event string, allowed func(*models.Task, primitive.ObjectID) bool, forbidden string) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input TaskTransitionInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	milestone, task, ok := ctrl.load(ctx, c)
+	if !ok {
+		return
+	}
+
+	if !allowed(task, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": forbidden})
+		return
+	}
+
+	if task.Status != models.TaskStatusInProgress && task.Status != models.TaskStatusSubmitted {
+		c.JSON(http.StatusConflict, gin.H{"error": "Milestones can only change while the task is in progress"})
+		return
+	}
+
+	previousStatus := milestone.Status
+	if err := milestone.Transition(event, &userID, input.Note, time.Now()); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot " + strings.ReplaceAll(event, "_", " ") + " a milestone that is " + previousStatus})
+		return
+	}
+
+	err = ctrl.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := ctrl.milestones.UpdateIfStatus(ctx, milestone, previousStatus); err != nil {
+			return err
+		}
+		if event == models.MilestoneEventApprove {
+			return ctrl.escrow.ScheduleMilestoneSettlement(ctx, milestone)
+		}
+		return nil
+	})
+	if err != nil {
+		milestoneSaveError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Milestone updated successfully",
+		"milestone": milestone,
+	})
+}
+
+// load fetches the milestone named by the :id parameter and its task,
+// writing the error response itself when either is missing.
+func (ctrl *MilestoneController) load(ctx context.Context, c *gin.Context) (*models.Milestone, *models.Task, bool) {
+	milestoneID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid milestone ID"})
+		return nil, nil, false
+	}
+
+	milestone, err := ctrl.milestones.FindByID(ctx, milestoneID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Milestone not found"})
+		return nil, nil, false
+	}
+
+	task, err := ctrl.tasks.FindByID(ctx, milestone.TaskID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return nil, nil, false
+	}
+	return milestone, task, true
+}
+
+func milestoneSaveError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Milestone was modified concurrently, please retry"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update milestone"})
+}
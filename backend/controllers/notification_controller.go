@@ -0,0 +1,267 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/notify"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// streamHeartbeat keeps idle notification streams from being closed by
+// proxies that time out silent connections.
+const streamHeartbeat = 25 * time.Second
+
+type NotificationController struct {
+	notifications repository.NotificationRepository
+	preferences   repository.NotificationPreferenceRepository
+	notifier      *notify.Service
+}
+
+func NewNotificationController(notifications repository.NotificationRepository, preferences repository.NotificationPreferenceRepository, notifier *notify.Service) *NotificationController {
+	return &NotificationController{notifications: notifications, preferences: preferences, notifier: notifier}
+}
+
+// GetNotifications pages through the caller's notifications, newest first;
+// unread=true leaves out the ones already read.
+func (ctrl *NotificationController) GetNotifications(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	page, err := pageParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := repository.NotificationFilter{UserID: userID, UnreadOnly: c.Query("unread") == "true"}
+	notifications, err := ctrl.notifications.Find(ctx, filter, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+// GetUnreadCount returns how many of the caller's notifications are unread.
+func (ctrl *NotificationController) GetUnreadCount(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	count, err := ctrl.notifications.CountUnread(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread": count})
+}
+
+// MarkNotificationRead marks one of the caller's notifications as read.
+func (ctrl *NotificationController) MarkNotificationRead(c *gin.Context) {
+	notificationID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ctrl.notifications.MarkRead(ctx, userID, notificationID, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notification as read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+// MarkAllNotificationsRead marks every unread notification of the caller as read.
+func (ctrl *NotificationController) MarkAllNotificationsRead(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	marked, err := ctrl.notifications.MarkAllRead(ctx, userID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notifications as read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"marked": marked})
+}
+
+// GetNotificationPreferences returns how the caller's notification emails are
+// delivered.
+func (ctrl *NotificationController) GetNotificationPreferences(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	preference, err := ctrl.preference(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": preference})
+}
+
+type UpdateNotificationPreferencesInput struct {
+	Email string `json:"email" binding:"required,oneof=instant daily_digest off"`
+}
+
+// UpdateNotificationPreferences switches the caller between instant emails,
+// a daily digest and no emails. A new digest subscriber's first digest only
+// covers events from the moment they subscribed; leaving the digest drops
+// anything still queued for it.
+func (ctrl *NotificationController) UpdateNotificationPreferences(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input UpdateNotificationPreferencesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	preference, err := ctrl.preference(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification preferences"})
+		return
+	}
+
+	now := time.Now()
+	previous := preference.Email
+	preference.Email = input.Email
+	if input.Email == models.EmailDailyDigest && previous != models.EmailDailyDigest {
+		preference.LastDigestAt = &now
+	}
+	if preference.CreatedAt.IsZero() {
+		preference.CreatedAt = now
+	}
+	preference.UpdatedAt = now
+
+	if err := ctrl.preferences.Save(ctx, preference); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification preferences"})
+		return
+	}
+	if previous == models.EmailDailyDigest && input.Email != models.EmailDailyDigest {
+		if err := ctrl.notifications.ClearDigestPending(ctx, userID, now); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification preferences"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Notification preferences updated",
+		"preferences": preference,
+	})
+}
+
+// preference loads the user's preference, or the default for users who
+// never saved one.
+func (ctrl *NotificationController) preference(ctx context.Context, userID primitive.ObjectID) (*models.NotificationPreference, error) {
+	preference, err := ctrl.preferences.FindByUser(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.DefaultNotificationPreference(userID), nil
+	}
+	return preference, err
+}
+
+// StreamNotifications pushes the caller's new notifications as server-sent
+// events. The stream opens with an "unread" event carrying the unread count,
+// then sends a "notification" event for each new notification. Clients that
+// reconnect should refetch the list, since notifications created while they
+// were away are not replayed.
+func (ctrl *NotificationController) StreamNotifications(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	// Subscribe before counting so nothing created in between is missed
+	notifications, unsubscribe := ctrl.notifier.Subscribe(userID)
+	defer unsubscribe()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	unread, err := ctrl.notifications.CountUnread(ctx, userID)
+	cancel()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count notifications"})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Stops nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+
+	c.SSEvent("unread", gin.H{"unread": unread})
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case notification, ok := <-notifications:
+			if !ok {
+				return false
+			}
+			c.SSEvent("notification", notification)
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -1,18 +1,46 @@
 package controllers
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"time"
 
-	"github.com/Vivekpdy/tasklanceweb/backend/config"
+	"github.com/Vivekpdy/tasklanceweb/backend/escrow"
 	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func GetTaskPayments(c *gin.Context) {
-	taskID := c.Param("taskId")
+type PaymentController struct {
+	payments repository.PaymentRepository
+	tasks    repository.TaskRepository
+	escrow   *escrow.Service
+}
+
+func NewPaymentController(payments repository.PaymentRepository, tasks repository.TaskRepository, escrow *escrow.Service) *PaymentController {
+	return &PaymentController{payments: payments, tasks: tasks, escrow: escrow}
+}
 
-	var payments []models.Payment
-	if err := config.DB.Preload("Client").Preload("Freelancer").Where("task_id = ?", taskID).Find(&payments).Error; err != nil {
+func (ctrl *PaymentController) GetTaskPayments(c *gin.Context) {
+	taskID, err := primitive.ObjectIDFromHex(c.Param("taskId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	page, err := pageParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	payments, err := ctrl.payments.FindByTask(ctx, taskID, page)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch payments"})
 		return
 	}
@@ -21,13 +49,20 @@ func GetTaskPayments(c *gin.Context) {
 }
 
 type CreatePaymentInput struct {
-	TaskID        uint    `json:"task_id" binding:"required"`
-	Amount        float64 `json:"amount" binding:"required,gt=0"`
-	PaymentMethod string  `json:"payment_method" binding:"required"`
+	TaskID string  `json:"task_id" binding:"required"`
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+	// PaymentSource is the gateway token to charge, such as a tokenized card.
+	PaymentSource string `json:"payment_source" binding:"required"`
 }
 
-func CreatePayment(c *gin.Context) {
-	userID := c.GetUint("userID")
+// CreatePayment adds funds to the escrow of a task that is being worked on.
+// The bid amount is escrowed automatically when the bid is accepted.
+func (ctrl *PaymentController) CreatePayment(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
 
 	var input CreatePaymentInput
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -35,9 +70,18 @@ func CreatePayment(c *gin.Context) {
 		return
 	}
 
+	taskID, err := primitive.ObjectIDFromHex(input.TaskID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
 	// Get task
-	var task models.Task
-	if err := config.DB.First(&task, input.TaskID).Error; err != nil {
+	task, err := ctrl.tasks.FindByID(ctx, taskID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 		return
 	}
@@ -52,17 +96,14 @@ func CreatePayment(c *gin.Context) {
 		return
 	}
 
-	payment := models.Payment{
-		TaskID:        input.TaskID,
-		ClientID:      userID,
-		FreelancerID:  *task.FreelancerID,
-		Amount:        input.Amount,
-		PaymentMethod: input.PaymentMethod,
-		Status:        "pending",
+	if task.Status != models.TaskStatusInProgress && task.Status != models.TaskStatusSubmitted {
+		c.JSON(http.StatusConflict, gin.H{"error": "Escrow can only be funded while the task is in progress"})
+		return
 	}
 
-	if err := config.DB.Create(&payment).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create payment"})
+	payment, err := ctrl.escrow.Fund(ctx, task, input.Amount, input.PaymentSource)
+	if err != nil {
+		fundingError(c, err)
 		return
 	}
 
@@ -72,33 +113,50 @@ func CreatePayment(c *gin.Context) {
 	})
 }
 
-func UpdatePayment(c *gin.Context) {
-	id := c.Param("id")
+type UpdatePaymentInput struct {
+	Status string `json:"status" binding:"required,oneof=held completed failed refunded"`
+	// Reference is the provider's charge, payout or refund ID, as for webhooks.
+	Reference string `json:"reference"`
+	Reason    string `json:"reason"`
+}
 
-	var payment models.Payment
-	if err := config.DB.First(&payment, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+// UpdatePayment lets an admin record a payment outcome the provider's
+// webhooks did not deliver. It takes the same path as a webhook: only legal
+// transitions are accepted and the matching ledger entry is posted, so
+// statuses always agree with the ledger. No money moves at the provider.
+func (ctrl *PaymentController) UpdatePayment(c *gin.Context) {
+	paymentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment ID"})
 		return
 	}
 
-	type UpdatePaymentInput struct {
-		Status        string `json:"status" binding:"required,oneof=pending completed failed refunded"`
-		TransactionID string `json:"transaction_id"`
-	}
-
 	var input UpdatePaymentInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	payment.Status = input.Status
-	if input.TransactionID != "" {
-		payment.TransactionID = input.TransactionID
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	payment, err := ctrl.payments.FindByID(ctx, paymentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+		return
 	}
 
-	if err := config.DB.Save(&payment).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update payment"})
+	current := payment.Status
+	change := escrow.StatusChange{Status: input.Status, Reference: input.Reference, Reason: input.Reason}
+	if err := ctrl.escrow.ApplyStatus(ctx, payment, change); err != nil {
+		switch {
+		case errors.Is(err, models.ErrIllegalPaymentTransition):
+			c.JSON(http.StatusConflict, gin.H{"error": "Cannot move a " + current + " payment to " + input.Status})
+		case errors.Is(err, repository.ErrConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "Payment was modified concurrently, please retry"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update payment"})
+		}
 		return
 	}
 
@@ -107,3 +165,23 @@ func UpdatePayment(c *gin.Context) {
 		"payment": payment,
 	})
 }
+
+// GetBalance returns the current user's balances derived from the ledger.
+func (ctrl *PaymentController) GetBalance(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	balance, err := ctrl.escrow.Balance(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch balance"})
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/recommend"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxRecommendations caps the limit query parameter of the recommendation
+// endpoints.
+const maxRecommendations = 50
+
+type RecommendationController struct {
+	recommender *recommend.Service
+	tasks       repository.TaskRepository
+	users       repository.UserRepository
+}
+
+func NewRecommendationController(recommender *recommend.Service, tasks repository.TaskRepository, users repository.UserRepository) *RecommendationController {
+	return &RecommendationController{recommender: recommender, tasks: tasks, users: users}
+}
+
+type RecommendationInput struct {
+	Limit int `form:"limit" binding:"omitempty,gte=1"`
+}
+
+// recommendationLimit binds the limit query parameter, 10 by default and
+// at most maxRecommendations.
+func recommendationLimit(c *gin.Context) (int, bool) {
+	var input RecommendationInput
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return 0, false
+	}
+	if input.Limit == 0 {
+		input.Limit = 10
+	}
+	return min(input.Limit, maxRecommendations), true
+}
+
+// GetRecommendedTasks ranks the open tasks the calling freelancer can still
+// bid on by how well they match the freelancer.
+func (ctrl *RecommendationController) GetRecommendedTasks(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	limit, ok := recommendationLimit(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := ctrl.users.FindByID(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	matches, err := ctrl.recommender.RecommendTasks(ctx, user, time.Now(), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to recommend tasks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": matches})
+}
+
+// GetSuggestedFreelancers ranks freelancers by how well they match the
+// task, for its owner.
+func (ctrl *RecommendationController) GetSuggestedFreelancers(c *gin.Context) {
+	taskID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	limit, ok := recommendationLimit(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	task, err := ctrl.tasks.FindByID(ctx, taskID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	if task.ClientID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only task owner can see suggested freelancers"})
+		return
+	}
+
+	matches, err := ctrl.recommender.SuggestFreelancers(ctx, task, time.Now(), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suggest freelancers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"task_id": task.ID,
+		"items":   matches,
+	})
+}
@@ -1,18 +1,46 @@
 package controllers
 
 import (
+	"context"
 	"net/http"
+	"time"
 
-	"github.com/Vivekpdy/tasklanceweb/backend/config"
 	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/notify"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func GetUserReviews(c *gin.Context) {
-	userID := c.Param("userId")
+type ReviewController struct {
+	reviews  repository.ReviewRepository
+	tasks    repository.TaskRepository
+	users    repository.UserRepository
+	notifier *notify.Service
+}
+
+func NewReviewController(reviews repository.ReviewRepository, tasks repository.TaskRepository, users repository.UserRepository, notifier *notify.Service) *ReviewController {
+	return &ReviewController{reviews: reviews, tasks: tasks, users: users, notifier: notifier}
+}
+
+func (ctrl *ReviewController) GetUserReviews(c *gin.Context) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	page, err := pageParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	var reviews []models.Review
-	if err := config.DB.Preload("Reviewer").Preload("Task").Where("reviewed_user_id = ?", userID).Find(&reviews).Error; err != nil {
+	reviews, err := ctrl.reviews.FindByReviewedUser(ctx, userID, page)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reviews"})
 		return
 	}
@@ -21,14 +49,18 @@ func GetUserReviews(c *gin.Context) {
 }
 
 type CreateReviewInput struct {
-	TaskID         uint   `json:"task_id" binding:"required"`
-	ReviewedUserID uint   `json:"reviewed_user_id" binding:"required"`
+	TaskID         string `json:"task_id" binding:"required"`
+	ReviewedUserID string `json:"reviewed_user_id" binding:"required"`
 	Rating         int    `json:"rating" binding:"required,min=1,max=5"`
 	Comment        string `json:"comment"`
 }
 
-func CreateReview(c *gin.Context) {
-	userID := c.GetUint("userID")
+func (ctrl *ReviewController) CreateReview(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
 
 	var input CreateReviewInput
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -36,38 +68,74 @@ func CreateReview(c *gin.Context) {
 		return
 	}
 
+	taskID, err := primitive.ObjectIDFromHex(input.TaskID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	reviewedUserID, err := primitive.ObjectIDFromHex(input.ReviewedUserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reviewed user ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
 	// Check if task exists and is completed
-	var task models.Task
-	if err := config.DB.First(&task, input.TaskID).Error; err != nil {
+	task, err := ctrl.tasks.FindByID(ctx, taskID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 		return
 	}
 
-	if task.Status != "completed" {
+	if task.Status != models.TaskStatusCompleted {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Can only review completed tasks"})
 		return
 	}
 
 	review := models.Review{
-		TaskID:         input.TaskID,
+		ID:             primitive.NewObjectID(),
+		TaskID:         taskID,
 		ReviewerID:     userID,
-		ReviewedUserID: input.ReviewedUserID,
+		ReviewedUserID: reviewedUserID,
 		Rating:         input.Rating,
 		Comment:        input.Comment,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
 	}
 
-	if err := config.DB.Create(&review).Error; err != nil {
+	if err := ctrl.reviews.Create(ctx, &review); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create review"})
 		return
 	}
+	ctrl.notifier.Notify(ctx, notify.ReviewReceived(task, &review))
 
 	// Update user rating
-	var avgRating float64
-	config.DB.Model(&models.Review{}).Where("reviewed_user_id = ?", input.ReviewedUserID).Select("AVG(rating)").Scan(&avgRating)
-	config.DB.Model(&models.User{}).Where("id = ?", input.ReviewedUserID).Update("rating", avgRating)
+	if err := refreshUserRating(ctx, ctrl.reviews, ctrl.users, reviewedUserID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute rating"})
+		return
+	}
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Review created successfully",
 		"review":  review,
 	})
 }
+
+// refreshUserRating recomputes User.Rating from the user's visible reviews.
+func refreshUserRating(ctx context.Context, reviews repository.ReviewRepository, users repository.UserRepository, userID primitive.ObjectID) error {
+	avgRating, err := reviews.AverageRating(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	user, err := users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	user.Rating = avgRating
+	user.UpdatedAt = time.Now()
+	return users.Update(ctx, user)
+}
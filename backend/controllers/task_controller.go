@@ -1,29 +1,50 @@
 package controllers
 
 import (
+	"context"
+	"errors"
+	"io"
 	"net/http"
+	"strings"
+	"time"
 
-	"github.com/Vivekpdy/tasklanceweb/backend/config"
+	"github.com/Vivekpdy/tasklanceweb/backend/escrow"
 	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func GetTasks(c *gin.Context) {
-	var tasks []models.Task
-	
-	query := config.DB.Preload("Client").Preload("Freelancer")
-	
-	// Filter by status
-	if status := c.Query("status"); status != "" {
-		query = query.Where("status = ?", status)
+type TaskController struct {
+	tasks      repository.TaskRepository
+	bids       repository.BidRepository
+	milestones repository.MilestoneRepository
+	tx         repository.Transactor
+	escrow     *escrow.Service
+}
+
+func NewTaskController(tasks repository.TaskRepository, bids repository.BidRepository, milestones repository.MilestoneRepository, tx repository.Transactor, escrow *escrow.Service) *TaskController {
+	return &TaskController{tasks: tasks, bids: bids, milestones: milestones, tx: tx, escrow: escrow}
+}
+
+func (ctrl *TaskController) GetTasks(c *gin.Context) {
+	page, err := pageParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	
-	// Filter by category
-	if category := c.Query("category"); category != "" {
-		query = query.Where("category = ?", category)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := repository.TaskFilter{
+		Status:   c.Query("status"),
+		Category: c.Query("category"),
 	}
 
-	if err := query.Find(&tasks).Error; err != nil {
+	tasks, err := ctrl.tasks.Find(ctx, filter, page)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
 		return
 	}
@@ -31,33 +52,163 @@ func GetTasks(c *gin.Context) {
 	c.JSON(http.StatusOK, tasks)
 }
 
-func GetTask(c *gin.Context) {
-	id := c.Param("id")
+type TaskSearchInput struct {
+	Query          string   `form:"q"`
+	Status         string   `form:"status"`
+	Category       string   `form:"category"`
+	Skills         []string `form:"skills"`
+	SkillsMatch    string   `form:"skills_match" binding:"omitempty,oneof=any all"`
+	MinBudget      *float64 `form:"min_budget" binding:"omitempty,gte=0"`
+	MaxBudget      *float64 `form:"max_budget" binding:"omitempty,gte=0"`
+	DeadlineAfter  string   `form:"deadline_after"`
+	DeadlineBefore string   `form:"deadline_before"`
+	Sort           string   `form:"sort" binding:"omitempty,oneof=relevance newest deadline budget_high budget_low"`
+}
+
+// SearchTasks runs a full-text search over task titles and descriptions with
+// optional skill, budget and deadline filters, and returns facet counts per
+// category and skill for the matching tasks. Results are paged with a
+// cursor that is only valid for the same sort.
+func (ctrl *TaskController) SearchTasks(c *gin.Context) {
+	var input TaskSearchInput
+	if err := c.ShouldBindQuery(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	page, err := pageParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	search := repository.TaskSearch{
+		Query:          strings.TrimSpace(input.Query),
+		Status:         input.Status,
+		Category:       input.Category,
+		MatchAllSkills: input.SkillsMatch == "all",
+		MinBudget:      input.MinBudget,
+		MaxBudget:      input.MaxBudget,
+		Sort:           input.Sort,
+		Page:           page,
+	}
+	// Skills may be repeated or comma separated: skills=go&skills=react or skills=go,react
+	for _, value := range input.Skills {
+		for _, skill := range strings.Split(value, ",") {
+			if skill = strings.TrimSpace(skill); skill != "" {
+				search.Skills = append(search.Skills, skill)
+			}
+		}
+	}
+	if search.MinBudget != nil && search.MaxBudget != nil && *search.MinBudget > *search.MaxBudget {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "min_budget cannot be greater than max_budget"})
+		return
+	}
+	if input.DeadlineAfter != "" {
+		after, err := parseDate(input.DeadlineAfter)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "deadline_after: " + err.Error()})
+			return
+		}
+		search.DeadlineAfter = &after
+	}
+	if input.DeadlineBefore != "" {
+		before, err := parseDate(input.DeadlineBefore)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "deadline_before: " + err.Error()})
+			return
+		}
+		search.DeadlineBefore = &before
+	}
+	if search.Sort == "" {
+		search.Sort = repository.TaskSortNewest
+		if search.Query != "" {
+			search.Sort = repository.TaskSortRelevance
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := ctrl.tasks.Search(ctx, search)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search tasks"})
+		return
+	}
 
-	var task models.Task
-	if err := config.DB.Preload("Client").Preload("Freelancer").Preload("Bids").First(&task, id).Error; err != nil {
+	c.JSON(http.StatusOK, result)
+}
+
+func (ctrl *TaskController) GetTask(c *gin.Context) {
+	taskID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	task, err := ctrl.tasks.FindByID(ctx, taskID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 		return
 	}
 
+	milestones, err := ctrl.milestones.FindByTask(ctx, task.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch milestones"})
+		return
+	}
+	task.Progress = models.MilestoneProgress(milestones)
+
 	c.JSON(http.StatusOK, task)
 }
 
 type CreateTaskInput struct {
-	Title          string  `json:"title" binding:"required"`
-	Description    string  `json:"description" binding:"required"`
-	Budget         float64 `json:"budget" binding:"required,gt=0"`
-	Deadline       string  `json:"deadline" binding:"required"`
-	Category       string  `json:"category"`
-	RequiredSkills string  `json:"required_skills"`
+	Title          string   `json:"title" binding:"required"`
+	Description    string   `json:"description" binding:"required"`
+	Budget         float64  `json:"budget" binding:"required,gt=0"`
+	Deadline       string   `json:"deadline" binding:"required"`
+	Category       string   `json:"category"`
+	RequiredSkills []string `json:"required_skills"`
+	// BiddingMode defaults to open on create and is left unchanged on
+	// update when empty. Sealed and reverse-auction tasks need
+	// BiddingClosesAt, before the deadline.
+	BiddingMode     string `json:"bidding_mode" binding:"omitempty,oneof=open sealed reverse_auction"`
+	BiddingClosesAt string `json:"bidding_closes_at"`
 }
 
-func CreateTask(c *gin.Context) {
-	userID := c.GetUint("userID")
-	userType := c.GetString("userType")
+// biddingSchedule validates the bidding mode and closing time of a task due
+// at deadline. Open tasks have no closing time.
+func biddingSchedule(mode, closesAt string, deadline, now time.Time) (*time.Time, error) {
+	if mode == models.BiddingOpen {
+		if closesAt != "" {
+			return nil, errors.New("bidding_closes_at only applies to sealed and reverse_auction tasks")
+		}
+		return nil, nil
+	}
+
+	if closesAt == "" {
+		return nil, errors.New("bidding_closes_at is required for sealed and reverse_auction tasks")
+	}
+	closing, err := parseDate(closesAt)
+	if err != nil {
+		return nil, err
+	}
+	if !closing.After(now) || !closing.Before(deadline) {
+		return nil, errors.New("bidding_closes_at must be in the future and before the deadline")
+	}
+	return &closing, nil
+}
 
-	if userType != "client" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only clients can create tasks"})
+func (ctrl *TaskController) CreateTask(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
 		return
 	}
 
@@ -67,17 +218,47 @@ func CreateTask(c *gin.Context) {
 		return
 	}
 
+	deadline, err := parseDate(input.Deadline)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.BiddingMode == "" {
+		input.BiddingMode = models.BiddingOpen
+	}
+	biddingClosesAt, err := biddingSchedule(input.BiddingMode, input.BiddingClosesAt, deadline, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
 	task := models.Task{
-		Title:          input.Title,
-		Description:    input.Description,
-		Budget:         input.Budget,
-		Category:       input.Category,
-		RequiredSkills: input.RequiredSkills,
-		ClientID:       userID,
-		Status:         "open",
+		ID:              primitive.NewObjectID(),
+		Title:           input.Title,
+		Description:     input.Description,
+		Budget:          input.Budget,
+		Deadline:        deadline,
+		Category:        input.Category,
+		RequiredSkills:  input.RequiredSkills,
+		BiddingMode:     input.BiddingMode,
+		BiddingClosesAt: biddingClosesAt,
+		ClientID:        userID,
+		Status:          models.TaskStatusOpen,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 	}
+	task.StatusHistory = []models.TaskStatusChange{{
+		To:      models.TaskStatusOpen,
+		Event:   models.TaskEventCreate,
+		ActorID: &userID,
+		At:      task.CreatedAt,
+	}}
 
-	if err := config.DB.Create(&task).Error; err != nil {
+	if err := ctrl.tasks.Create(ctx, &task); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task"})
 		return
 	}
@@ -88,12 +269,23 @@ func CreateTask(c *gin.Context) {
 	})
 }
 
-func UpdateTask(c *gin.Context) {
-	id := c.Param("id")
-	userID := c.GetUint("userID")
+func (ctrl *TaskController) UpdateTask(c *gin.Context) {
+	taskID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	var task models.Task
-	if err := config.DB.First(&task, id).Error; err != nil {
+	task, err := ctrl.tasks.FindByID(ctx, taskID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 		return
 	}
@@ -103,19 +295,46 @@ func UpdateTask(c *gin.Context) {
 		return
 	}
 
+	if task.Status != models.TaskStatusOpen {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only open tasks can be edited"})
+		return
+	}
+
 	var input CreateTaskInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	deadline, err := parseDate(input.Deadline)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lastUpdatedAt := task.UpdatedAt
+	if !ctrl.updateBidding(c, ctx, task, input, deadline) {
+		return
+	}
+
 	task.Title = input.Title
 	task.Description = input.Description
 	task.Budget = input.Budget
+	if !deadline.Equal(task.Deadline) {
+		task.Reminders = nil
+	}
+	task.Deadline = deadline
 	task.Category = input.Category
 	task.RequiredSkills = input.RequiredSkills
+	task.UpdatedAt = time.Now()
 
-	if err := config.DB.Save(&task).Error; err != nil {
+	// Conditional so an edit cannot undo a concurrent bid acceptance or
+	// auction close
+	if err := ctrl.tasks.UpdateIfUnchanged(ctx, task, lastUpdatedAt); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Task was changed by someone else, reload it and retry"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task"})
 		return
 	}
@@ -126,12 +345,23 @@ func UpdateTask(c *gin.Context) {
 	})
 }
 
-func DeleteTask(c *gin.Context) {
-	id := c.Param("id")
-	userID := c.GetUint("userID")
+func (ctrl *TaskController) DeleteTask(c *gin.Context) {
+	taskID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
 
-	var task models.Task
-	if err := config.DB.First(&task, id).Error; err != nil {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	task, err := ctrl.tasks.FindByID(ctx, taskID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 		return
 	}
@@ -141,10 +371,168 @@ func DeleteTask(c *gin.Context) {
 		return
 	}
 
-	if err := config.DB.Delete(&task).Error; err != nil {
+	if task.Status != models.TaskStatusOpen && task.Status != models.TaskStatusCancelled && task.Status != models.TaskStatusExpired {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only open, cancelled or expired tasks can be deleted"})
+		return
+	}
+
+	if err := ctrl.tasks.Delete(ctx, taskID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete task"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Task deleted successfully"})
 }
+
+type TaskTransitionInput struct {
+	Note string `json:"note"`
+}
+
+// SubmitWork lets the assigned freelancer hand in the work for review.
+func (ctrl *TaskController) SubmitWork(c *gin.Context) {
+	ctrl.transition(c, models.TaskEventSubmitWork, isAssignedFreelancer, "Only the assigned freelancer can submit work")
+}
+
+// RequestRevision sends submitted work back to the freelancer.
+func (ctrl *TaskController) RequestRevision(c *gin.Context) {
+	ctrl.transition(c, models.TaskEventRequestRevision, isTaskOwner, "Only the task owner can request a revision")
+}
+
+// ApproveCompletion accepts submitted work, completes the task and schedules
+// the release of the escrowed funds to the freelancer.
+func (ctrl *TaskController) ApproveCompletion(c *gin.Context) {
+	ctrl.transition(c, models.TaskEventApprove, isTaskOwner, "Only the task owner can approve completion")
+}
+
+// CancelTask lets the owner cancel a task that has not been submitted yet.
+// A refund of any escrowed funds is scheduled.
+func (ctrl *TaskController) CancelTask(c *gin.Context) {
+	ctrl.transition(c, models.TaskEventCancel, isTaskOwner, "Only the task owner can cancel the task")
+}
+
+// transition fires a lifecycle event on behalf of the current user. The save
+// is conditional on the status it was read with, so a concurrent transition
+// results in 409 rather than a lost update. Approving or cancelling queues
+// the escrow settlement in the same transaction, so only one of them can
+// ever settle it and a failed payout or refund is retried by the job queue.
+func (ctrl *TaskController) transition(c *gin.Context, event string, allowed func(*models.Task, primitive.ObjectID) bool, forbidden string) {
+	taskID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var input TaskTransitionInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	task, err := ctrl.tasks.FindByID(ctx, taskID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	if !allowed(task, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": forbidden})
+		return
+	}
+
+	previousStatus := task.Status
+	if err := task.Transition(event, &userID, input.Note, time.Now()); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot " + strings.ReplaceAll(event, "_", " ") + " while the task is " + previousStatus})
+		return
+	}
+
+	settles := event == models.TaskEventApprove || event == models.TaskEventCancel
+	err = ctrl.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := ctrl.tasks.UpdateIfStatus(ctx, task, previousStatus); err != nil {
+			return err
+		}
+		if settles {
+			return ctrl.escrow.ScheduleSettlement(ctx, task, &userID)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Task was modified concurrently, please retry"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Task updated successfully",
+		"task":    task,
+	})
+}
+
+func isTaskOwner(task *models.Task, userID primitive.ObjectID) bool {
+	return task.ClientID == userID
+}
+
+func isAssignedFreelancer(task *models.Task, userID primitive.ObjectID) bool {
+	return task.FreelancerID != nil && *task.FreelancerID == userID
+}
+
+// updateBidding applies a task update's bidding mode and closing time,
+// writing the error response and returning false when they are invalid.
+// They can only change while nobody has bid, so bidders keep the rules
+// they bid under, and the deadline must stay after the closing time.
+func (ctrl *TaskController) updateBidding(c *gin.Context, ctx context.Context, task *models.Task, input CreateTaskInput, deadline time.Time) bool {
+	current := task.BiddingMode
+	if current == "" {
+		current = models.BiddingOpen
+	}
+	mode := input.BiddingMode
+	if mode == "" {
+		mode = current
+	}
+
+	unchanged := mode == current
+	if input.BiddingClosesAt != "" {
+		closesAt, err := parseDate(input.BiddingClosesAt)
+		unchanged = unchanged && err == nil && task.BiddingClosesAt != nil && closesAt.Equal(*task.BiddingClosesAt)
+	}
+	if unchanged {
+		if task.AwaitingClose() && !task.BiddingClosesAt.Before(deadline) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "The deadline must be after bidding closes"})
+			return false
+		}
+		return true
+	}
+
+	if task.BiddingClosedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Bidding on this task has already closed"})
+		return false
+	}
+	stats, err := ctrl.bids.OpenBidStats(ctx, task.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bids"})
+		return false
+	}
+	if stats.Count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "The bidding mode and closing time cannot change once the task has bids"})
+		return false
+	}
+
+	biddingClosesAt, err := biddingSchedule(mode, input.BiddingClosesAt, deadline, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	task.BiddingMode = mode
+	task.BiddingClosesAt = biddingClosesAt
+	return true
+}
@@ -5,27 +5,32 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/Vivekpdy/tasklanceweb/backend/config"
-	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"github.com/Vivekpdy/tasklanceweb/backend/utils"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func GetCurrentUser(c *gin.Context) {
-	userID := c.GetString("userID")
-	objectID, err := primitive.ObjectIDFromHex(userID)
+type UserController struct {
+	users    repository.UserRepository
+	sessions repository.SessionRepository
+}
+
+func NewUserController(users repository.UserRepository, sessions repository.SessionRepository) *UserController {
+	return &UserController{users: users, sessions: sessions}
+}
+
+func (ctrl *UserController) GetCurrentUser(c *gin.Context) {
+	objectID, err := currentUserID(c)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
 		return
 	}
 
-	collection := config.MongoDB.Collection("users")
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	var user models.User
-	err = collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
+	user, err := ctrl.users.FindByID(ctx, objectID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
@@ -34,20 +39,17 @@ func GetCurrentUser(c *gin.Context) {
 	c.JSON(http.StatusOK, user.ToResponse())
 }
 
-func GetUser(c *gin.Context) {
-	id := c.Param("id")
-	objectID, err := primitive.ObjectIDFromHex(id)
+func (ctrl *UserController) GetUser(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
 		return
 	}
 
-	collection := config.MongoDB.Collection("users")
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	var user models.User
-	err = collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
+	user, err := ctrl.users.FindByID(ctx, objectID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
@@ -64,9 +66,8 @@ type UpdateUserInput struct {
 	ProfileImage string   `json:"profile_image"`
 }
 
-func UpdateUser(c *gin.Context) {
-	userID := c.GetString("userID")
-	objectID, err := primitive.ObjectIDFromHex(userID)
+func (ctrl *UserController) UpdateUser(c *gin.Context) {
+	objectID, err := currentUserID(c)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
 		return
@@ -78,49 +79,99 @@ func UpdateUser(c *gin.Context) {
 		return
 	}
 
-	collection := config.MongoDB.Collection("users")
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Build update document
-	update := bson.M{
-		"$set": bson.M{
-			"updated_at": time.Now(),
-		},
+	user, err := ctrl.users.FindByID(ctx, objectID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
 	}
 
 	if input.FirstName != "" {
-		update["$set"].(bson.M)["first_name"] = input.FirstName
+		user.FirstName = input.FirstName
 	}
 	if input.LastName != "" {
-		update["$set"].(bson.M)["last_name"] = input.LastName
+		user.LastName = input.LastName
 	}
 	if input.Bio != "" {
-		update["$set"].(bson.M)["bio"] = input.Bio
+		user.Bio = input.Bio
 	}
 	if input.Skills != nil {
-		update["$set"].(bson.M)["skills"] = input.Skills
+		user.Skills = input.Skills
 	}
 	if input.ProfileImage != "" {
-		update["$set"].(bson.M)["profile_image"] = input.ProfileImage
+		user.ProfileImage = input.ProfileImage
 	}
+	user.UpdatedAt = time.Now()
 
-	_, err = collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
-	if err != nil {
+	if err := ctrl.users.Update(ctx, user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
 		return
 	}
 
-	// Get updated user
-	var user models.User
-	err = collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch updated user"})
-		return
-	}
-
 	c.JSON(http.StatusOK, gin.H{
 		"message": "User updated successfully",
 		"user":    user.ToResponse(),
 	})
 }
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+// ChangePassword updates the password after re-checking the current one and
+// signs out every other session of the user.
+func (ctrl *UserController) ChangePassword(c *gin.Context) {
+	objectID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	sessionID, err := primitive.ObjectIDFromHex(c.GetString("sessionID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var input ChangePasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := ctrl.users.FindByID(ctx, objectID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if !utils.CheckPasswordHash(input.CurrentPassword, user.Password) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(input.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	user.Password = hashedPassword
+	user.UpdatedAt = time.Now()
+	if err := ctrl.users.Update(ctx, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		return
+	}
+
+	if _, err := ctrl.sessions.RevokeOthers(ctx, user.ID, sessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
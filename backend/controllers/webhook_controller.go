@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/escrow"
+	"github.com/Vivekpdy/tasklanceweb/backend/gateway"
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBytes bounds the webhook body read before the signature is checked.
+const maxWebhookBytes = 64 << 10
+
+type WebhookController struct {
+	payments repository.PaymentRepository
+	events   repository.PaymentEventRepository
+	gateway  gateway.PaymentGateway
+	escrow   *escrow.Service
+}
+
+func NewWebhookController(payments repository.PaymentRepository, events repository.PaymentEventRepository, gateway gateway.PaymentGateway, escrow *escrow.Service) *WebhookController {
+	return &WebhookController{payments: payments, events: events, gateway: gateway, escrow: escrow}
+}
+
+// PaymentWebhook receives signed event notifications from the payment
+// provider. Every verified event is stored with its raw body before it is
+// applied, and redeliveries of an event that was already handled are
+// acknowledged without being applied again. Events that cannot be applied
+// (an unknown payment or an illegal transition) are acknowledged too, since
+// the provider retrying them would not change the outcome; only storage
+// failures return an error so that the provider delivers the event again.
+func (ctrl *WebhookController) PaymentWebhook(c *gin.Context) {
+	provider := c.Param("provider")
+	if provider != ctrl.gateway.Name() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown payment provider"})
+		return
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read webhook body"})
+		return
+	}
+
+	event, err := ctrl.gateway.ParseWebhook(payload, c.GetHeader(gateway.SignatureHeader), time.Now())
+	if err != nil {
+		if errors.Is(err, gateway.ErrInvalidWebhook) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook payload"})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	record := &models.PaymentEvent{
+		Provider:   provider,
+		EventID:    event.ID,
+		Type:       event.Type,
+		Payload:    string(payload),
+		Status:     models.PaymentEventReceived,
+		ReceivedAt: time.Now(),
+	}
+	if err := ctrl.events.Create(ctx, record); err != nil {
+		if !errors.Is(err, repository.ErrDuplicate) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record webhook"})
+			return
+		}
+		record, err = ctrl.events.FindByEventID(ctx, provider, event.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record webhook"})
+			return
+		}
+		// An earlier delivery that failed part-way is retried in full
+		if record.Status != models.PaymentEventReceived {
+			c.JSON(http.StatusOK, gin.H{"status": record.Status, "duplicate": true})
+			return
+		}
+	}
+
+	if err := ctrl.apply(ctx, event, record); err != nil {
+		log.Printf("Failed to apply %s webhook %s: %v", provider, event.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process webhook"})
+		return
+	}
+
+	now := time.Now()
+	record.ProcessedAt = &now
+	if err := ctrl.events.Update(ctx, record); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": record.Status})
+}
+
+// apply drives the event's payment through escrow and sets the outcome on
+// record. It only returns errors worth a redelivery.
+func (ctrl *WebhookController) apply(ctx context.Context, event *gateway.WebhookEvent, record *models.PaymentEvent) error {
+	change, ok := escrow.EventStatusChange(event)
+	if !ok {
+		record.Status = models.PaymentEventIgnored
+		record.Error = "unsupported event type"
+		return nil
+	}
+
+	payment, err := ctrl.paymentFor(ctx, event)
+	if errors.Is(err, repository.ErrNotFound) {
+		record.Status = models.PaymentEventIgnored
+		record.Error = "no matching payment"
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	record.PaymentID = &payment.ID
+
+	err = ctrl.escrow.ApplyStatus(ctx, payment, change)
+	if errors.Is(err, models.ErrIllegalPaymentTransition) {
+		record.Status = models.PaymentEventRejected
+		record.Error = "cannot move a " + payment.Status + " payment to " + change.Status
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	record.Status = models.PaymentEventProcessed
+	record.Error = ""
+	return nil
+}
+
+// paymentFor finds the payment an event refers to, by charge where the event
+// names one and by payout otherwise.
+func (ctrl *WebhookController) paymentFor(ctx context.Context, event *gateway.WebhookEvent) (*models.Payment, error) {
+	if event.ChargeID != "" {
+		return ctrl.payments.FindByTransactionID(ctx, event.ChargeID)
+	}
+	if event.Type == gateway.EventPayoutPaid && event.TransferID != "" {
+		return ctrl.payments.FindByPayoutID(ctx, event.TransferID)
+	}
+	return nil, repository.ErrNotFound
+}
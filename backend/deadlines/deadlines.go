@@ -0,0 +1,305 @@
+// Package deadlines enforces task deadlines. A recurring job expires open
+// tasks whose deadline passed, flags overdue work, sends reminders ahead of
+// deadlines and rejects open bids on tasks that are no longer open.
+// Every change is appended to the task's history.
+package deadlines
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/jobs"
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/notify"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+)
+
+// JobDeadlineScan is the recurring job that runs Scan.
+const JobDeadlineScan = "task_deadline_scan"
+
+const (
+	scanInterval = 5 * time.Minute
+	// scanBatch caps the tasks handled by each step of one scan; the rest
+	// are picked up by the next scan.
+	scanBatch = 200
+)
+
+// Config controls the deadline scheduler.
+type Config struct {
+	Reminders []time.Duration // how long before a deadline reminders go out
+}
+
+// ConfigFromEnv reads DEADLINE_REMINDERS, a comma-separated list of
+// durations such as "24h,1h". An empty value turns reminders off.
+func ConfigFromEnv() Config {
+	value, ok := os.LookupEnv("DEADLINE_REMINDERS")
+	if !ok {
+		value = "24h,1h"
+	}
+
+	config := Config{}
+	for _, field := range strings.Split(value, ",") {
+		offset, err := time.ParseDuration(strings.TrimSpace(field))
+		if err == nil && offset > 0 && !slices.Contains(config.Reminders, offset) {
+			config.Reminders = append(config.Reminders, offset)
+		}
+	}
+	return config
+}
+
+type Service struct {
+	tasks    repository.TaskRepository
+	bids     repository.BidRepository
+	notifier *notify.Service
+	config   Config
+}
+
+// NewService builds the scheduler and schedules its scan on queue.
+func NewService(tasks repository.TaskRepository, bids repository.BidRepository, notifier *notify.Service, queue *jobs.Queue, config Config) *Service {
+	// Largest offset first, so a task due sooner than several offsets gets
+	// a single reminder.
+	config.Reminders = append([]time.Duration(nil), config.Reminders...)
+	sort.Slice(config.Reminders, func(i, j int) bool { return config.Reminders[i] > config.Reminders[j] })
+
+	s := &Service{tasks: tasks, bids: bids, notifier: notifier, config: config}
+	queue.Register(JobDeadlineScan, func(ctx context.Context, job *models.Job) error {
+		return s.Scan(ctx, time.Now())
+	})
+	queue.Every("task-deadlines", scanInterval, JobDeadlineScan, nil)
+	return s
+}
+
+// Scan applies every deadline rule as of now. A step that fails does not
+// stop the others; their errors are returned together.
+func (s *Service) Scan(ctx context.Context, now time.Time) error {
+	// MongoDB keeps milliseconds; saves conditional on UpdatedAt must
+	// compare against what was stored.
+	now = now.Truncate(time.Millisecond)
+	return errors.Join(
+		s.expireOpenTasks(ctx, now),
+		s.flagOverdueTasks(ctx, now),
+		s.sendReminders(ctx, now),
+		s.rejectStaleBids(ctx, now),
+	)
+}
+
+// expireOpenTasks closes open tasks whose deadline passed. Accepting a bid
+// moves a task out of open, so these never had a bid accepted.
+func (s *Service) expireOpenTasks(ctx context.Context, now time.Time) error {
+	tasks, err := s.tasks.FindByDeadline(ctx, repository.TaskDeadlineFilter{
+		Statuses: []string{models.TaskStatusOpen},
+		Before:   now,
+		Limit:    scanBatch,
+	})
+	if err != nil {
+		return fmt.Errorf("find expired tasks: %w", err)
+	}
+
+	for i := range tasks {
+		task := &tasks[i]
+		lastUpdatedAt := task.UpdatedAt
+		if err := task.Transition(models.TaskEventExpire, nil, "Deadline passed without an accepted bid", now); err != nil {
+			continue
+		}
+		if saved, err := s.save(ctx, task, lastUpdatedAt); !saved {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		s.notifier.Notify(ctx, notify.TaskExpired(task))
+
+		if err := s.rejectOpenBids(ctx, task, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flagOverdueTasks marks in-progress tasks whose deadline passed. They stay
+// in progress; the client decides whether to wait or cancel.
+func (s *Service) flagOverdueTasks(ctx context.Context, now time.Time) error {
+	tasks, err := s.tasks.FindByDeadline(ctx, repository.TaskDeadlineFilter{
+		Statuses:   []string{models.TaskStatusInProgress},
+		Before:     now,
+		NotOverdue: true,
+		Limit:      scanBatch,
+	})
+	if err != nil {
+		return fmt.Errorf("find overdue tasks: %w", err)
+	}
+
+	for i := range tasks {
+		task := &tasks[i]
+		lastUpdatedAt := task.UpdatedAt
+		task.OverdueAt = &now
+		task.Record(models.TaskEventMarkOverdue, nil, "Deadline passed before the work was submitted", now)
+		if saved, err := s.save(ctx, task, lastUpdatedAt); !saved {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		s.notifier.Notify(ctx, notify.TaskOverdue(task, task.ClientID))
+		if task.FreelancerID != nil {
+			s.notifier.Notify(ctx, notify.TaskOverdue(task, *task.FreelancerID))
+		}
+	}
+	return nil
+}
+
+// sendReminders reminds the party who has to act before a deadline: the
+// client of an open task, the freelancer of a task in progress. Each
+// configured offset is sent once per deadline; offsets that are already
+// past when a task is found are folded into a single reminder.
+func (s *Service) sendReminders(ctx context.Context, now time.Time) error {
+	if len(s.config.Reminders) == 0 {
+		return nil
+	}
+	largest, smallest := s.config.Reminders[0], s.config.Reminders[len(s.config.Reminders)-1]
+
+	tasks, err := s.tasks.FindByDeadline(ctx, repository.TaskDeadlineFilter{
+		Statuses:    []string{models.TaskStatusOpen, models.TaskStatusInProgress},
+		After:       now,
+		Before:      now.Add(largest),
+		NotReminded: smallest,
+		Limit:       scanBatch,
+	})
+	if err != nil {
+		return fmt.Errorf("find tasks to remind: %w", err)
+	}
+
+	for i := range tasks {
+		task := &tasks[i]
+		remaining := task.Deadline.Sub(now)
+
+		var due []time.Duration
+		for _, offset := range s.config.Reminders {
+			if offset >= remaining && !slices.Contains(task.Reminders, offset) {
+				due = append(due, offset)
+			}
+		}
+		if len(due) == 0 {
+			continue
+		}
+
+		lastUpdatedAt := task.UpdatedAt
+		task.Reminders = append(task.Reminders, due...)
+		task.Record(models.TaskEventDeadlineReminder, nil, "Reminder sent, due in "+remaining.Round(time.Minute).String(), now)
+		if saved, err := s.save(ctx, task, lastUpdatedAt); !saved {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		recipient := task.ClientID
+		if task.Status == models.TaskStatusInProgress && task.FreelancerID != nil {
+			recipient = *task.FreelancerID
+		}
+		s.notifier.Notify(ctx, notify.DeadlineSoon(task, recipient, remaining))
+	}
+	return nil
+}
+
+// rejectStaleBids rejects the open bids of every task that is no longer
+// open, whether it was assigned, cancelled, expired or deleted.
+func (s *Service) rejectStaleBids(ctx context.Context, now time.Time) error {
+	taskIDs, err := s.bids.FindOpenBidTaskIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("find tasks with open bids: %w", err)
+	}
+
+	for _, taskID := range taskIDs {
+		task, err := s.tasks.FindByID(ctx, taskID)
+		if errors.Is(err, repository.ErrNotFound) {
+			task = &models.Task{ID: taskID}
+		} else if err != nil {
+			return fmt.Errorf("load task %s: %w", taskID.Hex(), err)
+		}
+
+		if task.Status == models.TaskStatusOpen {
+			continue
+		}
+		if err := s.rejectOpenBids(ctx, task, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rejectOpenBids rejects the task's pending and countered bids, tells their
+// freelancers why and records how many were rejected in the task's history.
+// A task without a status has been deleted and has no history to record in.
+func (s *Service) rejectOpenBids(ctx context.Context, task *models.Task, now time.Time) error {
+	bids, err := s.bids.FindByTaskAndStatus(ctx, task.ID, models.OpenBidStatuses...)
+	if err != nil {
+		return fmt.Errorf("find open bids of task %s: %w", task.ID.Hex(), err)
+	}
+
+	reason := rejectionReason(task.Status)
+	rejected := 0
+	for i := range bids {
+		bid := &bids[i]
+		lastUpdatedAt := bid.UpdatedAt
+		if err := bid.Transition(models.BidEventReject, nil, "Rejected because "+reason, now); err != nil {
+			continue
+		}
+		if err := s.bids.UpdateIfUnchanged(ctx, bid, lastUpdatedAt); err != nil {
+			if errors.Is(err, repository.ErrConflict) {
+				continue
+			}
+			return fmt.Errorf("reject bid %s: %w", bid.ID.Hex(), err)
+		}
+		rejected++
+		if task.Status != "" {
+			s.notifier.Notify(ctx, notify.BidRejected(task, bid, reason))
+		}
+	}
+
+	if rejected == 0 || task.Status == "" {
+		return nil
+	}
+	lastUpdatedAt := task.UpdatedAt
+	task.Record(models.TaskEventRejectBids, nil, fmt.Sprintf("Rejected %d open bid(s) because %s", rejected, reason), now)
+	if _, err := s.save(ctx, task, lastUpdatedAt); err != nil {
+		return err
+	}
+	return nil
+}
+
+func rejectionReason(status string) string {
+	switch status {
+	case models.TaskStatusExpired:
+		return "the task expired"
+	case models.TaskStatusCancelled:
+		return "the task was cancelled"
+	case "":
+		return "the task was deleted"
+	default:
+		return "another bid was accepted"
+	}
+}
+
+// save stores a change made by the scheduler unless someone else saved the
+// task since it was loaded, in which case the change is dropped and retried
+// by a later scan if it still applies.
+func (s *Service) save(ctx context.Context, task *models.Task, lastUpdatedAt time.Time) (bool, error) {
+	err := s.tasks.UpdateIfUnchanged(ctx, task, lastUpdatedAt)
+	if errors.Is(err, repository.ErrConflict) {
+		log.Printf("Deadline scheduler: task %s changed concurrently, skipping", task.ID.Hex())
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("save task %s: %w", task.ID.Hex(), err)
+	}
+	return true, nil
+}
@@ -0,0 +1,474 @@
+// Package escrow moves task payments through the payment gateway and the
+// double-entry ledger. Funds are charged and held in a per-task escrow
+// account when a bid is accepted, paid out to the freelancer (less the
+// platform fee) when the work is approved, and refunded to the client when
+// the task is cancelled. Release and refund run as background jobs that are
+// retried until the escrow is settled. Every payment status change notifies
+// the client or freelancer it concerns.
+package escrow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/gateway"
+	"github.com/Vivekpdy/tasklanceweb/backend/jobs"
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/notify"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultFeePercent is the platform's cut of released funds when
+// PLATFORM_FEE_PERCENT is not set.
+const DefaultFeePercent = 10.0
+
+var (
+	// ErrNoFreelancer is returned when funding a task nobody has been assigned to.
+	ErrNoFreelancer = errors.New("task has no assigned freelancer")
+	// ErrPaymentFailed wraps errors from the payment gateway.
+	ErrPaymentFailed = errors.New("payment failed")
+)
+
+type Service struct {
+	payments   repository.PaymentRepository
+	ledger     repository.LedgerRepository
+	tasks      repository.TaskRepository
+	milestones repository.MilestoneRepository
+	gateway    gateway.PaymentGateway
+	notifier   *notify.Service
+	queue      *jobs.Queue
+	feePercent float64
+}
+
+// NewService builds the escrow service and registers its settlement jobs with queue.
+func NewService(payments repository.PaymentRepository, ledger repository.LedgerRepository, tasks repository.TaskRepository, milestones repository.MilestoneRepository, gateway gateway.PaymentGateway, notifier *notify.Service, queue *jobs.Queue, feePercent float64) *Service {
+	s := &Service{
+		payments:   payments,
+		ledger:     ledger,
+		tasks:      tasks,
+		milestones: milestones,
+		gateway:    gateway,
+		notifier:   notifier,
+		queue:      queue,
+		feePercent: feePercent,
+	}
+	queue.Register(JobSettleTask, s.runSettlementJob)
+	queue.Register(JobSettleMilestone, s.runMilestoneSettlementJob)
+	return s
+}
+
+// FeePercentFromEnv reads PLATFORM_FEE_PERCENT, falling back to DefaultFeePercent.
+func FeePercentFromEnv() float64 {
+	if value := os.Getenv("PLATFORM_FEE_PERCENT"); value != "" {
+		if percent, err := strconv.ParseFloat(value, 64); err == nil && percent >= 0 && percent <= 100 {
+			return percent
+		}
+	}
+	return DefaultFeePercent
+}
+
+// Fund charges the client's payment source through the gateway and holds the
+// captured amount in the task's escrow account. The payment is created
+// pending and only marked held once the ledger entry is posted; a gateway
+// failure marks it failed and returns an error wrapping ErrPaymentFailed. If
+// the capture succeeds but cannot be recorded, the charge is refunded, the
+// payment is marked failed and the recording error is returned.
+func (s *Service) Fund(ctx context.Context, task *models.Task, amount float64, source string) (*models.Payment, error) {
+	return s.fund(ctx, task, nil, amount, source, "Escrow for task "+task.Title)
+}
+
+// FundMilestone charges the milestone's amount into escrow, tagging the
+// payment with the milestone so it can be released on its own.
+func (s *Service) FundMilestone(ctx context.Context, task *models.Task, milestone *models.Milestone, source string) (*models.Payment, error) {
+	return s.fund(ctx, task, &milestone.ID, milestone.Amount, source, "Escrow for milestone "+milestone.Title+" of task "+task.Title)
+}
+
+func (s *Service) fund(ctx context.Context, task *models.Task, milestoneID *primitive.ObjectID, amount float64, source, description string) (*models.Payment, error) {
+	if task.FreelancerID == nil {
+		return nil, ErrNoFreelancer
+	}
+
+	now := time.Now()
+	payment := &models.Payment{
+		ID:             primitive.NewObjectID(),
+		TaskID:         task.ID,
+		MilestoneID:    milestoneID,
+		ClientID:       task.ClientID,
+		FreelancerID:   *task.FreelancerID,
+		Amount:         amount,
+		PaymentGateway: s.gateway.Name(),
+		Status:         models.PaymentStatusPending,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.payments.Create(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	charge, err := s.gateway.CreateCharge(ctx, gateway.ChargeRequest{
+		Amount:         amount,
+		Source:         source,
+		Description:    description,
+		IdempotencyKey: payment.ID.Hex(),
+	})
+	if err == nil {
+		payment.TransactionID = charge.ID
+		payment.PaymentMethod = charge.PaymentMethod
+		_, err = s.gateway.Capture(ctx, charge.ID)
+	}
+	if err != nil {
+		return payment, s.fail(ctx, payment, err)
+	}
+
+	if err := s.recordFunding(ctx, payment); err != nil && !s.recordedElsewhere(ctx, payment, err) {
+		return payment, s.unwind(ctx, payment, err)
+	}
+	return payment, nil
+}
+
+// unwind refunds a captured charge whose funding could not be recorded, so
+// no money stays captured against a payment that never reached escrow. The
+// payment is marked failed, and a funding entry that did get posted before
+// the failure is reversed. It returns cause for the caller to report.
+func (s *Service) unwind(ctx context.Context, payment *models.Payment, cause error) error {
+	cause = fmt.Errorf("record funding of payment %s: %w", payment.ID.Hex(), cause)
+
+	refund, err := s.gateway.Refund(ctx, payment.TransactionID, payment.Amount, payment.ID.Hex()+":refund")
+	if err != nil {
+		log.Printf("Failed to refund charge %s after its funding could not be recorded: %v", payment.TransactionID, err)
+		return cause
+	}
+
+	entries, err := s.ledger.FindByTask(ctx, payment.TaskID)
+	if err != nil {
+		log.Printf("Failed to check the ledger for payment %s after refunding it: %v", payment.ID.Hex(), err)
+	}
+	for _, entry := range entries {
+		if entry.PaymentID != payment.ID || entry.Type != models.LedgerEntryFund {
+			continue
+		}
+		cents := models.ToCents(payment.Amount)
+		reversal := s.entry(models.LedgerEntryRefund, payment, time.Now(),
+			posting(models.LedgerAccountEscrow, &payment.TaskID, -cents),
+			posting(models.LedgerAccountClient, &payment.ClientID, cents),
+		)
+		if err := s.post(ctx, reversal); err != nil {
+			log.Printf("Failed to reverse the funding of payment %s: %v", payment.ID.Hex(), err)
+		}
+		break
+	}
+
+	payment.RefundID = refund.ID
+	payment.Status = models.PaymentStatusFailed
+	payment.FailureReason = "funding could not be recorded, the charge was refunded"
+	payment.UpdatedAt = time.Now()
+	if err := s.save(ctx, payment, models.PaymentStatusPending); err != nil {
+		log.Printf("Failed to mark payment %s failed after refunding it: %v", payment.ID.Hex(), err)
+	}
+	return cause
+}
+
+// Release pays every held payment of the task out to the freelancer.
+func (s *Service) Release(ctx context.Context, task *models.Task) ([]models.Payment, error) {
+	return s.settle(ctx, task, nil, s.release)
+}
+
+// ReleaseMilestone pays the held payments of one milestone out to the freelancer.
+func (s *Service) ReleaseMilestone(ctx context.Context, task *models.Task, milestoneID primitive.ObjectID) ([]models.Payment, error) {
+	return s.settle(ctx, task, &milestoneID, s.release)
+}
+
+// Refund returns every held payment of the task to the client.
+func (s *Service) Refund(ctx context.Context, task *models.Task) ([]models.Payment, error) {
+	return s.settle(ctx, task, nil, s.RefundPayment)
+}
+
+// settle empties the task's escrow one held payment at a time. Gateway calls
+// use idempotency keys and each payment gets its own ledger entry, so a retry
+// after a partial failure never moves the same money twice. A non-nil
+// milestoneID limits settlement to that milestone's payments.
+func (s *Service) settle(ctx context.Context, task *models.Task, milestoneID *primitive.ObjectID, settle func(context.Context, *models.Payment) error) ([]models.Payment, error) {
+	held, err := s.payments.FindByTaskAndStatus(ctx, task.ID, models.PaymentStatusHeld)
+	if err != nil {
+		return nil, err
+	}
+
+	settled := make([]models.Payment, 0, len(held))
+	for i := range held {
+		if milestoneID != nil && (held[i].MilestoneID == nil || *held[i].MilestoneID != *milestoneID) {
+			continue
+		}
+		if err := settle(ctx, &held[i]); err != nil {
+			return settled, err
+		}
+		settled = append(settled, held[i])
+	}
+	return settled, nil
+}
+
+// release pays a held payment out to the freelancer, less the platform fee.
+func (s *Service) release(ctx context.Context, payment *models.Payment) error {
+	cents := models.ToCents(payment.Amount)
+	fee := s.fee(cents)
+
+	payout, err := s.gateway.Payout(ctx, gateway.PayoutRequest{
+		Amount:         models.FromCents(cents - fee),
+		Destination:    payment.FreelancerID.Hex(),
+		Description:    "Payout for task " + payment.TaskID.Hex(),
+		IdempotencyKey: payment.ID.Hex() + ":payout",
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrPaymentFailed, err)
+	}
+
+	if err := s.recordRelease(ctx, payment, payout.ID); err != nil && !s.recordedElsewhere(ctx, payment, err) {
+		return err
+	}
+	return nil
+}
+
+// RefundPayment returns one held payment to the client through the gateway.
+func (s *Service) RefundPayment(ctx context.Context, payment *models.Payment) error {
+	refund, err := s.gateway.Refund(ctx, payment.TransactionID, payment.Amount, payment.ID.Hex()+":refund")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrPaymentFailed, err)
+	}
+
+	if err := s.recordRefund(ctx, payment, refund.ID); err != nil && !s.recordedElsewhere(ctx, payment, err) {
+		return err
+	}
+	return nil
+}
+
+// StatusChange is a payment outcome reported from outside the escrow flow,
+// by a provider webhook or an admin.
+type StatusChange struct {
+	Status string
+	// Reference is the provider's charge ID when funds are held, its payout
+	// ID on completion or its refund ID on refund.
+	Reference string
+	// Reason explains a failure.
+	Reason string
+}
+
+// EventStatusChange maps a verified webhook onto the payment status it
+// reports. ok is false for event types the platform does not act on.
+func EventStatusChange(event *gateway.WebhookEvent) (change StatusChange, ok bool) {
+	switch event.Type {
+	case gateway.EventChargeSucceeded:
+		return StatusChange{Status: models.PaymentStatusHeld, Reference: event.ChargeID}, true
+	case gateway.EventChargeFailed:
+		return StatusChange{Status: models.PaymentStatusFailed, Reason: event.Reason}, true
+	case gateway.EventChargeRefunded:
+		return StatusChange{Status: models.PaymentStatusRefunded, Reference: event.TransferID}, true
+	case gateway.EventPayoutPaid:
+		return StatusChange{Status: models.PaymentStatusCompleted, Reference: event.TransferID}, true
+	}
+	return StatusChange{}, false
+}
+
+// ApplyStatus moves a payment to the reported status, posting the same
+// ledger entry the escrow flow would have, so statuses and balances never
+// disagree. A payment already in that status is left alone, since providers
+// confirm outcomes the escrow flow has usually recorded already. Anything
+// else that is not a legal transition returns
+// models.ErrIllegalPaymentTransition.
+func (s *Service) ApplyStatus(ctx context.Context, payment *models.Payment, change StatusChange) error {
+	if payment.Status == change.Status {
+		return nil
+	}
+	if !payment.CanTransition(change.Status) {
+		return models.ErrIllegalPaymentTransition
+	}
+
+	switch change.Status {
+	case models.PaymentStatusHeld:
+		if change.Reference != "" {
+			payment.TransactionID = change.Reference
+		}
+		return s.recordFunding(ctx, payment)
+	case models.PaymentStatusFailed:
+		payment.Status = models.PaymentStatusFailed
+		payment.FailureReason = change.Reason
+		payment.UpdatedAt = time.Now()
+		return s.save(ctx, payment, models.PaymentStatusPending)
+	case models.PaymentStatusCompleted:
+		return s.recordRelease(ctx, payment, change.Reference)
+	case models.PaymentStatusRefunded:
+		return s.recordRefund(ctx, payment, change.Reference)
+	}
+	return models.ErrIllegalPaymentTransition
+}
+
+// recordFunding posts a captured pending payment into escrow and marks it held.
+func (s *Service) recordFunding(ctx context.Context, payment *models.Payment) error {
+	cents := models.ToCents(payment.Amount)
+	now := time.Now()
+	entry := s.entry(models.LedgerEntryFund, payment, now,
+		posting(models.LedgerAccountClient, &payment.ClientID, -cents),
+		posting(models.LedgerAccountEscrow, &payment.TaskID, cents),
+	)
+	if err := s.post(ctx, entry); err != nil {
+		return err
+	}
+
+	payment.Status = models.PaymentStatusHeld
+	payment.UpdatedAt = now
+	return s.save(ctx, payment, models.PaymentStatusPending)
+}
+
+// recordRelease posts a paid-out held payment to the freelancer and platform
+// accounts and marks it completed.
+func (s *Service) recordRelease(ctx context.Context, payment *models.Payment, payoutID string) error {
+	cents := models.ToCents(payment.Amount)
+	fee := s.fee(cents)
+
+	postings := []models.LedgerPosting{
+		posting(models.LedgerAccountEscrow, &payment.TaskID, -cents),
+		posting(models.LedgerAccountFreelancer, &payment.FreelancerID, cents-fee),
+	}
+	if fee > 0 {
+		postings = append(postings, posting(models.LedgerAccountPlatform, nil, fee))
+	}
+	now := time.Now()
+	if err := s.post(ctx, s.entry(models.LedgerEntryRelease, payment, now, postings...)); err != nil {
+		return err
+	}
+
+	payment.PayoutID = payoutID
+	payment.PlatformFee = models.FromCents(fee)
+	payment.Status = models.PaymentStatusCompleted
+	payment.UpdatedAt = now
+	return s.save(ctx, payment, models.PaymentStatusHeld)
+}
+
+// recordRefund posts a refunded held payment back to the client and marks it refunded.
+func (s *Service) recordRefund(ctx context.Context, payment *models.Payment, refundID string) error {
+	cents := models.ToCents(payment.Amount)
+	now := time.Now()
+	entry := s.entry(models.LedgerEntryRefund, payment, now,
+		posting(models.LedgerAccountEscrow, &payment.TaskID, -cents),
+		posting(models.LedgerAccountClient, &payment.ClientID, cents),
+	)
+	if err := s.post(ctx, entry); err != nil {
+		return err
+	}
+
+	payment.RefundID = refundID
+	payment.Status = models.PaymentStatusRefunded
+	payment.UpdatedAt = now
+	return s.save(ctx, payment, models.PaymentStatusHeld)
+}
+
+// save stores a payment's new status if its stored status is still
+// expectedStatus, then tells the party the change concerns.
+func (s *Service) save(ctx context.Context, payment *models.Payment, expectedStatus string) error {
+	if err := s.payments.UpdateIfStatus(ctx, payment, expectedStatus); err != nil {
+		return err
+	}
+	if notification, ok := notify.PaymentStatusChanged(payment); ok {
+		s.notifier.Notify(ctx, notification)
+	}
+	return nil
+}
+
+// recordedElsewhere reports whether err is a conflict caused by a webhook
+// recording the same outcome first, in which case payment is refreshed from
+// the stored copy.
+func (s *Service) recordedElsewhere(ctx context.Context, payment *models.Payment, err error) bool {
+	if !errors.Is(err, repository.ErrConflict) {
+		return false
+	}
+	stored, findErr := s.payments.FindByID(ctx, payment.ID)
+	if findErr != nil || stored.Status != payment.Status {
+		return false
+	}
+	*payment = *stored
+	return true
+}
+
+// fail records a gateway error on a pending payment and wraps it in ErrPaymentFailed.
+func (s *Service) fail(ctx context.Context, payment *models.Payment, cause error) error {
+	payment.Status = models.PaymentStatusFailed
+	payment.FailureReason = cause.Error()
+	payment.UpdatedAt = time.Now()
+	if err := s.save(ctx, payment, models.PaymentStatusPending); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrPaymentFailed, cause)
+}
+
+// Balance is a user's position derived from the ledger, in currency units.
+type Balance struct {
+	UserID primitive.ObjectID `json:"user_id"`
+	// Funded is what the user has paid in as a client, net of refunds.
+	Funded float64 `json:"funded"`
+	// InEscrow is held on tasks the user owns.
+	InEscrow float64 `json:"in_escrow"`
+	// Earned is what has been released to the user as a freelancer.
+	Earned float64 `json:"earned"`
+	// Pending is held in escrow for work assigned to the user.
+	Pending float64 `json:"pending"`
+}
+
+// Balance sums the user's client, freelancer and escrow postings.
+func (s *Service) Balance(ctx context.Context, userID primitive.ObjectID) (*Balance, error) {
+	client, err := s.ledger.Balance(ctx, repository.LedgerFilter{Account: models.LedgerAccountClient, OwnerID: userID})
+	if err != nil {
+		return nil, err
+	}
+	inEscrow, err := s.ledger.Balance(ctx, repository.LedgerFilter{Account: models.LedgerAccountEscrow, ClientID: userID})
+	if err != nil {
+		return nil, err
+	}
+	earned, err := s.ledger.Balance(ctx, repository.LedgerFilter{Account: models.LedgerAccountFreelancer, OwnerID: userID})
+	if err != nil {
+		return nil, err
+	}
+	pending, err := s.ledger.Balance(ctx, repository.LedgerFilter{Account: models.LedgerAccountEscrow, FreelancerID: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Balance{
+		UserID:   userID,
+		Funded:   models.FromCents(-client),
+		InEscrow: models.FromCents(inEscrow),
+		Earned:   models.FromCents(earned),
+		Pending:  models.FromCents(pending),
+	}, nil
+}
+
+func (s *Service) fee(cents int64) int64 {
+	return int64(float64(cents)*s.feePercent/100 + 0.5)
+}
+
+func (s *Service) entry(entryType string, payment *models.Payment, at time.Time, postings ...models.LedgerPosting) *models.LedgerEntry {
+	return &models.LedgerEntry{
+		Type:         entryType,
+		PaymentID:    payment.ID,
+		TaskID:       payment.TaskID,
+		ClientID:     payment.ClientID,
+		FreelancerID: payment.FreelancerID,
+		Postings:     postings,
+		CreatedAt:    at,
+	}
+}
+
+// post appends entry, treating an entry already posted for the payment as done.
+func (s *Service) post(ctx context.Context, entry *models.LedgerEntry) error {
+	if err := s.ledger.Post(ctx, entry); err != nil && !errors.Is(err, repository.ErrDuplicate) {
+		return err
+	}
+	return nil
+}
+
+func posting(account string, ownerID *primitive.ObjectID, amount int64) models.LedgerPosting {
+	return models.LedgerPosting{Account: account, OwnerID: ownerID, Amount: amount}
+}
@@ -0,0 +1,108 @@
+package escrow
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/gateway"
+	"github.com/Vivekpdy/tasklanceweb/backend/mailer"
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/notify"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+)
+
+var errStoreDown = errors.New("store unavailable")
+
+// failingLedger is a ledger whose posts fail.
+type failingLedger struct {
+	repository.LedgerRepository
+}
+
+func (l *failingLedger) Post(ctx context.Context, entry *models.LedgerEntry) error {
+	return errStoreDown
+}
+
+// failingPayments is a payment store whose first conditional updates fail.
+type failingPayments struct {
+	repository.PaymentRepository
+	failures int
+}
+
+func (p *failingPayments) UpdateIfStatus(ctx context.Context, payment *models.Payment, expectedStatus string) error {
+	if p.failures > 0 {
+		p.failures--
+		return errStoreDown
+	}
+	return p.PaymentRepository.UpdateIfStatus(ctx, payment, expectedStatus)
+}
+
+// refundCounter is the fake gateway counting its refunds.
+type refundCounter struct {
+	*gateway.Fake
+	refunds int
+}
+
+func (g *refundCounter) Refund(ctx context.Context, chargeID string, amount float64, idempotencyKey string) (*gateway.Transfer, error) {
+	g.refunds++
+	return g.Fake.Refund(ctx, chargeID, amount, idempotencyKey)
+}
+
+func TestFundRefundsCaptureThatCannotBeRecorded(t *testing.T) {
+	tests := []struct {
+		name    string
+		store   func(*repository.Repositories) (repository.PaymentRepository, repository.LedgerRepository)
+		entries int // a funding entry that got posted is reversed
+	}{
+		{"ledger post fails", func(repos *repository.Repositories) (repository.PaymentRepository, repository.LedgerRepository) {
+			return repos.Payments, &failingLedger{LedgerRepository: repos.Ledger}
+		}, 0},
+		{"marking the payment held fails", func(repos *repository.Repositories) (repository.PaymentRepository, repository.LedgerRepository) {
+			return &failingPayments{PaymentRepository: repos.Payments, failures: 1}, repos.Ledger
+		}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			gw := &refundCounter{Fake: gateway.NewFake(gateway.WebhookConfig{})}
+			st := newSettlementTest(gw)
+			payments, ledger := tt.store(st.repos)
+			notifier := notify.NewService(st.repos.Notifications, st.repos.NotificationPreferences, st.repos.Users, mailer.NewMemoryMailer(), st.queue, notify.EmailConfig{})
+			service := NewService(payments, ledger, st.repos.Tasks, st.repos.Milestones, gw, notifier, st.queue, 10)
+
+			task := st.createTask(t, models.TaskStatusInProgress)
+			payment, err := service.Fund(ctx, task, 500, gateway.FakeSourceSuccess)
+			if err == nil || errors.Is(err, ErrPaymentFailed) || !errors.Is(err, errStoreDown) {
+				t.Fatalf("Fund = %v, want the store error", err)
+			}
+			if payment == nil {
+				t.Fatal("Fund returned no payment for the captured charge")
+			}
+			if gw.refunds != 1 {
+				t.Errorf("%d gateway refunds, want 1", gw.refunds)
+			}
+			stored, err := st.repos.Payments.FindByID(ctx, payment.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Status != models.PaymentStatusFailed || stored.RefundID == "" {
+				t.Errorf("payment is %q with refund %q, want failed with the refund recorded", stored.Status, stored.RefundID)
+			}
+
+			entries, err := st.repos.Ledger.FindByTask(ctx, task.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != tt.entries {
+				t.Errorf("%d ledger entries, want %d", len(entries), tt.entries)
+			}
+			escrowed, err := st.repos.Ledger.Balance(ctx, repository.LedgerFilter{Account: models.LedgerAccountEscrow, TaskID: task.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if escrowed != 0 {
+				t.Errorf("escrow holds %d cents, want 0", escrowed)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package escrow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/jobs"
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Settlement job types run by the escrow service.
+const (
+	// JobSettleTask settles the escrow of a task that was completed or cancelled.
+	JobSettleTask = "escrow_settle_task"
+	// JobSettleMilestone releases the escrow of an approved milestone.
+	JobSettleMilestone = "escrow_settle_milestone"
+)
+
+// ErrNotSettleable is returned when settling a task that is neither
+// completed nor cancelled, or a milestone that is not approved.
+var ErrNotSettleable = errors.New("nothing to settle")
+
+type settlementJob struct {
+	TaskID  primitive.ObjectID  `bson:"task_id"`
+	ActorID *primitive.ObjectID `bson:"actor_id,omitempty"`
+}
+
+type milestoneSettlementJob struct {
+	MilestoneID primitive.ObjectID `bson:"milestone_id"`
+}
+
+// ScheduleSettlement queues the settlement of a task that was just completed
+// or cancelled. It should run in the transaction that saves the task's new
+// status, so the settlement is queued if and only if that change commits.
+// There is one settlement job per task, retried until the escrow is settled.
+func (s *Service) ScheduleSettlement(ctx context.Context, task *models.Task, actorID *primitive.ObjectID) error {
+	payload := settlementJob{TaskID: task.ID, ActorID: actorID}
+	_, err := s.queue.Enqueue(ctx, JobSettleTask, payload, jobs.UniqueKey("escrow-settle:"+task.ID.Hex()))
+	return err
+}
+
+// SettleTask closes the milestones of a completed or cancelled task, then
+// releases its held payments to the freelancer or refunds them to the
+// client. Every step skips what an earlier run already did, so it is safe
+// to run again after a partial failure.
+func (s *Service) SettleTask(ctx context.Context, taskID primitive.ObjectID, actorID *primitive.ObjectID) ([]models.Payment, error) {
+	task, err := s.tasks.FindByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch task.Status {
+	case models.TaskStatusCompleted:
+		if err := s.closeMilestones(ctx, task.ID, actorID, true); err != nil {
+			return nil, err
+		}
+		return s.Release(ctx, task)
+	case models.TaskStatusCancelled:
+		if err := s.closeMilestones(ctx, task.ID, actorID, false); err != nil {
+			return nil, err
+		}
+		return s.Refund(ctx, task)
+	}
+	return nil, fmt.Errorf("%w: task %s is %s", ErrNotSettleable, task.ID.Hex(), task.Status)
+}
+
+// ScheduleMilestoneSettlement queues the release of a milestone that was
+// just approved. Like ScheduleSettlement it belongs in the transaction that
+// saves the approval, and there is one job per milestone.
+func (s *Service) ScheduleMilestoneSettlement(ctx context.Context, milestone *models.Milestone) error {
+	payload := milestoneSettlementJob{MilestoneID: milestone.ID}
+	_, err := s.queue.Enqueue(ctx, JobSettleMilestone, payload, jobs.UniqueKey("escrow-settle-milestone:"+milestone.ID.Hex()))
+	return err
+}
+
+// SettleMilestone releases the held payments of an approved milestone to
+// the freelancer. Payments already released, including by the settlement
+// of the whole task, are skipped.
+func (s *Service) SettleMilestone(ctx context.Context, milestoneID primitive.ObjectID) ([]models.Payment, error) {
+	milestone, err := s.milestones.FindByID(ctx, milestoneID)
+	if err != nil {
+		return nil, err
+	}
+	if milestone.Status != models.MilestoneStatusApproved {
+		return nil, fmt.Errorf("%w: milestone %s is %s", ErrNotSettleable, milestone.ID.Hex(), milestone.Status)
+	}
+
+	task, err := s.tasks.FindByID(ctx, milestone.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ReleaseMilestone(ctx, task, milestone.ID)
+}
+
+func (s *Service) runSettlementJob(ctx context.Context, job *models.Job) error {
+	var payload settlementJob
+	if err := jobs.Decode(job, &payload); err != nil {
+		return jobs.Permanent(err)
+	}
+
+	_, err := s.SettleTask(ctx, payload.TaskID, payload.ActorID)
+	if errors.Is(err, repository.ErrNotFound) || errors.Is(err, ErrNotSettleable) {
+		return jobs.Permanent(err)
+	}
+	return err
+}
+
+func (s *Service) runMilestoneSettlementJob(ctx context.Context, job *models.Job) error {
+	var payload milestoneSettlementJob
+	if err := jobs.Decode(job, &payload); err != nil {
+		return jobs.Permanent(err)
+	}
+
+	_, err := s.SettleMilestone(ctx, payload.MilestoneID)
+	if errors.Is(err, repository.ErrNotFound) || errors.Is(err, ErrNotSettleable) {
+		return jobs.Permanent(err)
+	}
+	return err
+}
+
+// closeMilestones settles a task's open milestones when the task itself
+// completes or is cancelled. On completion, funded and submitted milestones
+// are approved, since the task's approval releases their funds; everything
+// else that is still open is cancelled.
+func (s *Service) closeMilestones(ctx context.Context, taskID primitive.ObjectID, actorID *primitive.ObjectID, completed bool) error {
+	all, err := s.milestones.FindByTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+
+	note := "Task cancelled"
+	if completed {
+		note = "Task completed"
+	}
+
+	now := time.Now()
+	for i := range all {
+		milestone := &all[i]
+		event := models.MilestoneEventCancel
+		if completed && milestone.Status != models.MilestoneStatusPending {
+			event = models.MilestoneEventApprove
+		}
+		previousStatus := milestone.Status
+		if err := milestone.Transition(event, actorID, note, now); err != nil {
+			continue // already approved or cancelled
+		}
+		if err := s.milestones.UpdateIfStatus(ctx, milestone, previousStatus); err != nil {
+			return err
+		}
+	}
+	return nil
+}
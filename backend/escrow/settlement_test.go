@@ -0,0 +1,218 @@
+package escrow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/gateway"
+	"github.com/Vivekpdy/tasklanceweb/backend/jobs"
+	"github.com/Vivekpdy/tasklanceweb/backend/mailer"
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/notify"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// flakyGateway is the fake gateway with its first payouts failing.
+type flakyGateway struct {
+	*gateway.Fake
+	failures int
+}
+
+func (g *flakyGateway) Payout(ctx context.Context, req gateway.PayoutRequest) (*gateway.Transfer, error) {
+	if g.failures > 0 {
+		g.failures--
+		return nil, gateway.ErrUnavailable
+	}
+	return g.Fake.Payout(ctx, req)
+}
+
+// laterJobs leases jobs as if the clock were offset ahead, so tests can get
+// past a retry's backoff.
+type laterJobs struct {
+	repository.JobRepository
+	offset time.Duration
+}
+
+func (j *laterJobs) Lease(ctx context.Context, types []string, owner string, now, until time.Time) (*models.Job, error) {
+	return j.JobRepository.Lease(ctx, types, owner, now.Add(j.offset), until.Add(j.offset))
+}
+
+// drain runs due jobs until none is left.
+func drain(t *testing.T, queue *jobs.Queue) {
+	t.Helper()
+	for i := 0; ; i++ {
+		if i > 50 {
+			t.Fatal("job queue did not drain")
+		}
+		ran, err := queue.RunNext(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ran {
+			return
+		}
+	}
+}
+
+// settlementTest is an escrow service over the in-memory stores whose
+// queue can be moved forward in time.
+type settlementTest struct {
+	repos   *repository.Repositories
+	jobs    *laterJobs
+	queue   *jobs.Queue
+	service *Service
+}
+
+func newSettlementTest(gw gateway.PaymentGateway) *settlementTest {
+	repos := repository.NewMemoryRepositories()
+	jobRepo := &laterJobs{JobRepository: repos.Jobs}
+	queue := jobs.NewQueue(jobRepo, jobs.Config{Visibility: time.Minute, MaxAttempts: 5})
+	notifier := notify.NewService(repos.Notifications, repos.NotificationPreferences, repos.Users, mailer.NewMemoryMailer(), queue, notify.EmailConfig{})
+	service := NewService(repos.Payments, repos.Ledger, repos.Tasks, repos.Milestones, gw, notifier, queue, 10)
+	return &settlementTest{repos: repos, jobs: jobRepo, queue: queue, service: service}
+}
+
+// createTask stores an assigned task with the given status.
+func (st *settlementTest) createTask(t *testing.T, status string) *models.Task {
+	t.Helper()
+	freelancerID := primitive.NewObjectID()
+	task := &models.Task{
+		ID:           primitive.NewObjectID(),
+		Title:        "Landing page",
+		ClientID:     primitive.NewObjectID(),
+		FreelancerID: &freelancerID,
+		Budget:       500,
+		Status:       status,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	if err := st.repos.Tasks.Create(context.Background(), task); err != nil {
+		t.Fatal(err)
+	}
+	return task
+}
+
+func (st *settlementTest) paymentStatus(t *testing.T, payment *models.Payment) string {
+	t.Helper()
+	found, err := st.repos.Payments.FindByID(context.Background(), payment.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return found.Status
+}
+
+func TestSettlementRetriesFailedPayout(t *testing.T) {
+	ctx := context.Background()
+	payouts := &flakyGateway{Fake: gateway.NewFake(gateway.WebhookConfig{}), failures: 1}
+	st := newSettlementTest(payouts)
+	repos, queue, service := st.repos, st.queue, st.service
+
+	task := st.createTask(t, models.TaskStatusSubmitted)
+	payment, err := service.Fund(ctx, task, 500, gateway.FakeSourceSuccess)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := task.Transition(models.TaskEventApprove, &task.ClientID, "", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Tasks.UpdateIfStatus(ctx, task, models.TaskStatusSubmitted); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.ScheduleSettlement(ctx, task, &task.ClientID); err != nil {
+		t.Fatal(err)
+	}
+	// Approving twice must not queue a second settlement.
+	if err := service.ScheduleSettlement(ctx, task, &task.ClientID); !errors.Is(err, repository.ErrDuplicate) {
+		t.Fatalf("second ScheduleSettlement = %v, want ErrDuplicate", err)
+	}
+
+	drain(t, queue)
+	if payouts.failures != 0 {
+		t.Fatal("settlement job did not run")
+	}
+	if status := st.paymentStatus(t, payment); status != models.PaymentStatusHeld {
+		t.Fatalf("payment is %q after the failed payout, want it still held", status)
+	}
+
+	st.jobs.offset = time.Hour
+	drain(t, queue)
+	if status := st.paymentStatus(t, payment); status != models.PaymentStatusCompleted {
+		t.Errorf("payment is %q after the retry, want completed", status)
+	}
+}
+
+func TestSettlementSkipsOpenTask(t *testing.T) {
+	st := newSettlementTest(gateway.NewFake(gateway.WebhookConfig{}))
+	task := st.createTask(t, models.TaskStatusInProgress)
+
+	if _, err := st.service.SettleTask(context.Background(), task.ID, nil); !errors.Is(err, ErrNotSettleable) {
+		t.Errorf("SettleTask = %v, want ErrNotSettleable", err)
+	}
+}
+
+func TestMilestoneSettlementRetriesFailedPayout(t *testing.T) {
+	ctx := context.Background()
+	payouts := &flakyGateway{Fake: gateway.NewFake(gateway.WebhookConfig{}), failures: 1}
+	st := newSettlementTest(payouts)
+	task := st.createTask(t, models.TaskStatusInProgress)
+
+	milestones := make([]*models.Milestone, 2)
+	payments := make([]*models.Payment, 2)
+	for i := range milestones {
+		milestones[i] = &models.Milestone{ID: primitive.NewObjectID(), TaskID: task.ID, Title: "Design", Amount: 250, Status: models.MilestoneStatusPending, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+		if err := st.repos.Milestones.Create(ctx, milestones[i]); err != nil {
+			t.Fatal(err)
+		}
+		payment, err := st.service.FundMilestone(ctx, task, milestones[i], gateway.FakeSourceSuccess)
+		if err != nil {
+			t.Fatal(err)
+		}
+		payments[i] = payment
+		if err := milestones[i].Transition(models.MilestoneEventFund, &task.ClientID, "", time.Now()); err != nil {
+			t.Fatal(err)
+		}
+		if err := st.repos.Milestones.UpdateIfStatus(ctx, milestones[i], models.MilestoneStatusPending); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A milestone that is not approved has nothing to release.
+	if _, err := st.service.SettleMilestone(ctx, milestones[0].ID); !errors.Is(err, ErrNotSettleable) {
+		t.Fatalf("SettleMilestone before approval = %v, want ErrNotSettleable", err)
+	}
+
+	approved := milestones[0]
+	if err := approved.Transition(models.MilestoneEventApprove, &task.ClientID, "", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.repos.Milestones.UpdateIfStatus(ctx, approved, models.MilestoneStatusFunded); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.service.ScheduleMilestoneSettlement(ctx, approved); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.service.ScheduleMilestoneSettlement(ctx, approved); !errors.Is(err, repository.ErrDuplicate) {
+		t.Fatalf("second ScheduleMilestoneSettlement = %v, want ErrDuplicate", err)
+	}
+
+	drain(t, st.queue)
+	if payouts.failures != 0 {
+		t.Fatal("milestone settlement job did not run")
+	}
+	if status := st.paymentStatus(t, payments[0]); status != models.PaymentStatusHeld {
+		t.Fatalf("payment is %q after the failed payout, want it still held", status)
+	}
+
+	st.jobs.offset = time.Hour
+	drain(t, st.queue)
+	if status := st.paymentStatus(t, payments[0]); status != models.PaymentStatusCompleted {
+		t.Errorf("approved milestone's payment is %q after the retry, want completed", status)
+	}
+	if status := st.paymentStatus(t, payments[1]); status != models.PaymentStatusHeld {
+		t.Errorf("other milestone's payment is %q, want it still held", status)
+	}
+}
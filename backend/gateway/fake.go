@@ -0,0 +1,195 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// FakeName is the provider name of the fake gateway.
+const FakeName = "fake"
+
+// Payment sources and payout destinations with a fixed outcome in the fake
+// gateway, in the spirit of provider test cards. Any other source or
+// destination succeeds.
+const (
+	FakeSourceSuccess           = "tok_success"
+	FakeSourceDeclined          = "tok_declined"
+	FakeSourceInsufficientFunds = "tok_insufficient_funds"
+	FakeSourceUnavailable       = "tok_unavailable"
+	FakeSourceCaptureFails      = "tok_capture_fails"
+	FakeSourceRefundFails       = "tok_refund_fails"
+	FakeDestinationPayoutFails  = "acct_payout_fails"
+)
+
+// Fake is an in-process PaymentGateway for local development and tests. It
+// keeps charges in memory, numbers IDs sequentially and decides outcomes
+// purely from the source or destination, so every scenario is reproducible.
+type Fake struct {
+	mu        sync.Mutex
+	seq       int
+	charges   map[string]*fakeCharge
+	transfers map[string]*Transfer
+	// keys maps idempotency keys to the charge or transfer they created
+	keys     map[string]string
+	webhooks WebhookConfig
+}
+
+type fakeCharge struct {
+	Charge
+	source   string
+	refunded float64
+}
+
+func NewFake(webhooks WebhookConfig) *Fake {
+	return &Fake{
+		charges:   make(map[string]*fakeCharge),
+		transfers: make(map[string]*Transfer),
+		keys:      make(map[string]string),
+		webhooks:  webhooks,
+	}
+}
+
+func (f *Fake) Name() string {
+	return FakeName
+}
+
+func (f *Fake) CreateCharge(ctx context.Context, req ChargeRequest) (*Charge, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if req.Amount <= 0 {
+		return nil, ErrInvalidRequest
+	}
+	switch req.Source {
+	case FakeSourceDeclined:
+		return nil, ErrDeclined
+	case FakeSourceInsufficientFunds:
+		return nil, ErrInsufficientFunds
+	case FakeSourceUnavailable:
+		return nil, ErrUnavailable
+	}
+
+	if charge, ok := f.charges[f.keys[req.IdempotencyKey]]; ok && req.IdempotencyKey != "" {
+		copied := charge.Charge
+		return &copied, nil
+	}
+
+	charge := &fakeCharge{
+		Charge: Charge{ID: f.nextID("ch"), Amount: req.Amount, PaymentMethod: "card"},
+		source: req.Source,
+	}
+	f.charges[charge.ID] = charge
+	if req.IdempotencyKey != "" {
+		f.keys[req.IdempotencyKey] = charge.ID
+	}
+	copied := charge.Charge
+	return &copied, nil
+}
+
+func (f *Fake) Capture(ctx context.Context, chargeID string) (*Charge, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	charge, ok := f.charges[chargeID]
+	if !ok {
+		return nil, ErrUnknownCharge
+	}
+	if charge.source == FakeSourceCaptureFails {
+		return nil, ErrDeclined
+	}
+	charge.Captured = true
+	copied := charge.Charge
+	return &copied, nil
+}
+
+func (f *Fake) Refund(ctx context.Context, chargeID string, amount float64, idempotencyKey string) (*Transfer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if transfer, ok := f.transfers[f.keys[idempotencyKey]]; ok && idempotencyKey != "" {
+		copied := *transfer
+		return &copied, nil
+	}
+
+	charge, ok := f.charges[chargeID]
+	if !ok {
+		return nil, ErrUnknownCharge
+	}
+	if charge.source == FakeSourceRefundFails {
+		return nil, ErrUnavailable
+	}
+	if !charge.Captured || amount <= 0 || charge.refunded+amount > charge.Amount+0.005 {
+		return nil, ErrInvalidRequest
+	}
+
+	charge.refunded += amount
+	return f.transfer("re", amount, idempotencyKey), nil
+}
+
+func (f *Fake) Payout(ctx context.Context, req PayoutRequest) (*Transfer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if transfer, ok := f.transfers[f.keys[req.IdempotencyKey]]; ok && req.IdempotencyKey != "" {
+		copied := *transfer
+		return &copied, nil
+	}
+	if req.Destination == FakeDestinationPayoutFails {
+		return nil, ErrUnavailable
+	}
+	if req.Amount < 0 {
+		return nil, ErrInvalidRequest
+	}
+	return f.transfer("po", req.Amount, req.IdempotencyKey), nil
+}
+
+// transfer records a refund or payout. Callers must hold the lock.
+func (f *Fake) transfer(prefix string, amount float64, idempotencyKey string) *Transfer {
+	transfer := &Transfer{ID: f.nextID(prefix), Amount: amount}
+	f.transfers[transfer.ID] = transfer
+	if idempotencyKey != "" {
+		f.keys[idempotencyKey] = transfer.ID
+	}
+	copied := *transfer
+	return &copied
+}
+
+// nextID returns a sequential provider ID. Callers must hold the lock.
+func (f *Fake) nextID(prefix string) string {
+	f.seq++
+	return fmt.Sprintf("fake_%s_%06d", prefix, f.seq)
+}
+
+// FakeWebhook is the body of a fake gateway webhook:
+//
+//	{"id": "evt_1", "type": "charge.succeeded", "data": {"charge_id": "fake_ch_000001"}}
+type FakeWebhook struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		ChargeID   string `json:"charge_id"`
+		TransferID string `json:"transfer_id"`
+		Reason     string `json:"reason"`
+	} `json:"data"`
+}
+
+func (f *Fake) ParseWebhook(payload []byte, signature string, now time.Time) (*WebhookEvent, error) {
+	if err := f.webhooks.VerifySignature(signature, payload, now); err != nil {
+		return nil, err
+	}
+
+	var webhook FakeWebhook
+	if err := json.Unmarshal(payload, &webhook); err != nil || webhook.ID == "" || webhook.Type == "" {
+		return nil, ErrInvalidWebhook
+	}
+	return &WebhookEvent{
+		ID:         webhook.ID,
+		Type:       webhook.Type,
+		ChargeID:   webhook.Data.ChargeID,
+		TransferID: webhook.Data.TransferID,
+		Reason:     webhook.Data.Reason,
+	}, nil
+}
@@ -0,0 +1,86 @@
+// Package gateway abstracts the external payment provider that moves real
+// money in and out of the platform: charging clients, capturing those charges,
+// refunding them and paying freelancers out.
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+var (
+	// ErrDeclined is returned when the provider refuses a charge.
+	ErrDeclined = errors.New("payment declined")
+	// ErrInsufficientFunds is returned when the payment source cannot cover the amount.
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrUnavailable is returned when the provider could not be reached.
+	ErrUnavailable = errors.New("payment provider unavailable")
+	// ErrUnknownCharge is returned for captures or refunds of a charge the provider does not know.
+	ErrUnknownCharge = errors.New("unknown charge")
+	// ErrInvalidRequest is returned when the provider rejects the request itself,
+	// such as refunding more than was captured.
+	ErrInvalidRequest = errors.New("invalid payment request")
+)
+
+// ChargeRequest asks the provider to authorize Amount against Source, a
+// provider-specific token for the client's card or account.
+type ChargeRequest struct {
+	Amount         float64
+	Source         string
+	Description    string
+	IdempotencyKey string
+}
+
+// PayoutRequest sends Amount to a freelancer. Destination identifies the
+// freelancer's connected account at the provider.
+type PayoutRequest struct {
+	Amount         float64
+	Destination    string
+	Description    string
+	IdempotencyKey string
+}
+
+// Charge is the provider's view of a client charge.
+type Charge struct {
+	ID            string
+	Amount        float64
+	PaymentMethod string
+	Captured      bool
+}
+
+// Transfer is the provider's record of a refund or payout.
+type Transfer struct {
+	ID     string
+	Amount float64
+}
+
+// PaymentGateway is implemented by every payment provider. Requests carrying
+// the same IdempotencyKey must return the original result instead of moving
+// money twice.
+type PaymentGateway interface {
+	// Name identifies the provider in Payment.PaymentGateway and webhook URLs.
+	Name() string
+	CreateCharge(ctx context.Context, req ChargeRequest) (*Charge, error)
+	Capture(ctx context.Context, chargeID string) (*Charge, error)
+	Refund(ctx context.Context, chargeID string, amount float64, idempotencyKey string) (*Transfer, error)
+	Payout(ctx context.Context, req PayoutRequest) (*Transfer, error)
+	// ParseWebhook verifies the signature header of a webhook request and
+	// decodes its body. now is checked against the signed timestamp.
+	ParseWebhook(payload []byte, signature string, now time.Time) (*WebhookEvent, error)
+}
+
+// NewFromEnv selects the provider named by PAYMENT_GATEWAY. Only the local
+// fake provider ships with the API, so it is also the default. Any other
+// name is an error rather than a silent fallback, so a misconfigured server
+// never accepts fake payments.
+func NewFromEnv() (PaymentGateway, error) {
+	switch name := os.Getenv("PAYMENT_GATEWAY"); name {
+	case "", FakeName:
+		return NewFake(WebhookConfigFromEnv()), nil
+	default:
+		return nil, fmt.Errorf("unknown PAYMENT_GATEWAY %q", name)
+	}
+}
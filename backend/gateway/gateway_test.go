@@ -0,0 +1,32 @@
+package gateway
+
+import "testing"
+
+func TestNewFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		wantErr bool
+	}{
+		{"unset", "", false},
+		{"fake", "fake", false},
+		{"unknown provider", "stripe", true},
+		{"misspelled", "Fake", true},
+		{"padded", " fake", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PAYMENT_GATEWAY", tt.env)
+			gw, err := NewFromEnv()
+			if tt.wantErr {
+				if err == nil || gw != nil {
+					t.Fatalf("NewFromEnv = %v, %v, want an error", gw, err)
+				}
+				return
+			}
+			if err != nil || gw.Name() != FakeName {
+				t.Fatalf("NewFromEnv = %v, %v, want the fake provider", gw, err)
+			}
+		})
+	}
+}
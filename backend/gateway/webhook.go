@@ -0,0 +1,141 @@
+package gateway
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries the webhook signature in the form
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">".
+const SignatureHeader = "Tasklance-Signature"
+
+// DefaultWebhookTolerance is how far a webhook's timestamp may drift from the
+// server clock when PAYMENT_WEBHOOK_TOLERANCE is not set.
+const DefaultWebhookTolerance = 5 * time.Minute
+
+// Webhook event types the platform acts on. Providers translate their own
+// event names into these.
+const (
+	EventChargeSucceeded = "charge.succeeded"
+	EventChargeFailed    = "charge.failed"
+	EventChargeRefunded  = "charge.refunded"
+	EventPayoutPaid      = "payout.paid"
+)
+
+var (
+	// ErrInvalidSignature is returned when a webhook is unsigned, signed with
+	// another secret or no secret is configured.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrStaleWebhook is returned when a webhook's timestamp is outside the
+	// tolerance, which stops captured requests from being replayed later.
+	ErrStaleWebhook = errors.New("webhook timestamp outside tolerance")
+	// ErrInvalidWebhook is returned when a correctly signed payload cannot be decoded.
+	ErrInvalidWebhook = errors.New("invalid webhook payload")
+)
+
+// WebhookEvent is a verified notification from the provider.
+type WebhookEvent struct {
+	// ID is the provider's event ID. Providers deliver at least once, so the
+	// same ID can arrive more than once.
+	ID   string
+	Type string
+	// ChargeID is the client charge the event concerns.
+	ChargeID string
+	// TransferID is the refund or payout the event concerns, if any.
+	TransferID string
+	// Reason explains a failed charge.
+	Reason string
+}
+
+// WebhookConfig holds the shared secret webhooks are signed with and the
+// allowed clock drift.
+type WebhookConfig struct {
+	Secret    string
+	Tolerance time.Duration
+}
+
+// WebhookConfigFromEnv reads PAYMENT_WEBHOOK_SECRET and PAYMENT_WEBHOOK_TOLERANCE.
+func WebhookConfigFromEnv() WebhookConfig {
+	config := WebhookConfig{
+		Secret:    os.Getenv("PAYMENT_WEBHOOK_SECRET"),
+		Tolerance: DefaultWebhookTolerance,
+	}
+	if value := os.Getenv("PAYMENT_WEBHOOK_TOLERANCE"); value != "" {
+		if tolerance, err := time.ParseDuration(value); err == nil && tolerance > 0 {
+			config.Tolerance = tolerance
+		}
+	}
+	return config
+}
+
+// SignPayload returns the SignatureHeader value for payload sent at the given time.
+func SignPayload(secret string, payload []byte, at time.Time) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", timestamp, signature(secret, timestamp, payload))
+}
+
+// VerifySignature checks a SignatureHeader value against payload. Several v1
+// signatures may be present while a secret is being rotated; any match is
+// accepted.
+func (config WebhookConfig) VerifySignature(header string, payload []byte, now time.Time) error {
+	if config.Secret == "" {
+		return ErrInvalidSignature
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	expected := signature(config.Secret, timestamp, payload)
+	matched := false
+	for _, candidate := range signatures {
+		if hmac.Equal([]byte(candidate), []byte(expected)) {
+			matched = true
+		}
+	}
+	if !matched {
+		return ErrInvalidSignature
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	drift := now.Sub(time.Unix(seconds, 0))
+	if drift < 0 {
+		drift = -drift
+	}
+	if drift > config.Tolerance {
+		return ErrStaleWebhook
+	}
+	return nil
+}
+
+func signature(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
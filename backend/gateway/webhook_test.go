@@ -0,0 +1,42 @@
+package gateway
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerifySignature(t *testing.T) {
+	config := WebhookConfig{Secret: "whsec_current", Tolerance: 5 * time.Minute}
+	payload := []byte(`{"id":"evt_1","type":"charge.succeeded","data":{"charge_id":"fake_ch_000001"}}`)
+	now := time.Unix(1717243200, 0)
+	valid := SignPayload(config.Secret, payload, now)
+
+	tests := []struct {
+		name    string
+		config  WebhookConfig
+		header  string
+		payload []byte
+		want    error
+	}{
+		{"valid", config, valid, payload, nil},
+		{"tampered body", config, valid, []byte(`{"id":"evt_1","type":"charge.refunded","data":{"charge_id":"fake_ch_000001"}}`), ErrInvalidSignature},
+		{"other secret", config, SignPayload("whsec_other", payload, now), payload, ErrInvalidSignature},
+		{"rotated secret", config, valid + ",v1=" + signature("whsec_old", "1717243200", payload), payload, nil},
+		{"within tolerance", config, SignPayload(config.Secret, payload, now.Add(-4*time.Minute)), payload, nil},
+		{"too old", config, SignPayload(config.Secret, payload, now.Add(-6*time.Minute)), payload, ErrStaleWebhook},
+		{"too far ahead", config, SignPayload(config.Secret, payload, now.Add(6*time.Minute)), payload, ErrStaleWebhook},
+		{"timestamp changed", config, "t=1717243100,v1=" + signature(config.Secret, "1717243200", payload), payload, ErrInvalidSignature},
+		{"missing signature", config, "t=1717243200", payload, ErrInvalidSignature},
+		{"empty header", config, "", payload, ErrInvalidSignature},
+		{"no secret configured", WebhookConfig{Tolerance: 5 * time.Minute}, SignPayload("", payload, now), payload, ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.VerifySignature(tt.header, tt.payload, now)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("VerifySignature = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
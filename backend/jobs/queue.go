@@ -0,0 +1,343 @@
+// Package jobs runs deferred work from a queue stored in MongoDB. Workers
+// lease due jobs for a visibility timeout, so a job whose worker dies is
+// picked up again once its lease runs out. Failed jobs are retried with
+// exponential backoff until they run out of attempts and are moved to the
+// dead-letter state, where an admin can inspect and retry them.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// backoffBase is the delay before the first retry; it doubles with
+	// every further attempt up to backoffMax.
+	backoffBase = 30 * time.Second
+	backoffMax  = time.Hour
+	// retention is how long succeeded jobs are kept before the TTL index
+	// purges them.
+	retention = 7 * 24 * time.Hour
+)
+
+// Handler runs one job. Its context expires with the job's lease. Returning
+// an error retries the job later unless the error is Permanent.
+type Handler func(ctx context.Context, job *models.Job) error
+
+// Config controls the workers.
+type Config struct {
+	Workers      int           // jobs run concurrently by this process
+	PollInterval time.Duration // wait between polls when the queue is empty
+	Visibility   time.Duration // lease length, and the deadline of each run
+	MaxAttempts  int           // default attempts before a job is dead
+}
+
+// ConfigFromEnv reads JOB_WORKERS, JOB_POLL_INTERVAL, JOB_VISIBILITY_TIMEOUT
+// and JOB_MAX_ATTEMPTS.
+func ConfigFromEnv() Config {
+	config := Config{Workers: 2, PollInterval: 2 * time.Second, Visibility: 5 * time.Minute, MaxAttempts: 5}
+	if value, err := strconv.Atoi(os.Getenv("JOB_WORKERS")); err == nil && value > 0 {
+		config.Workers = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("JOB_POLL_INTERVAL")); err == nil && value > 0 {
+		config.PollInterval = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("JOB_VISIBILITY_TIMEOUT")); err == nil && value > 0 {
+		config.Visibility = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("JOB_MAX_ATTEMPTS")); err == nil && value > 0 {
+		config.MaxAttempts = value
+	}
+	return config
+}
+
+// schedule is a recurring job enqueued once per interval.
+type schedule struct {
+	name     string
+	interval time.Duration
+	jobType  string
+	payload  interface{}
+}
+
+type Queue struct {
+	jobs   repository.JobRepository
+	config Config
+	owner  string
+
+	mu        sync.RWMutex
+	handlers  map[string]Handler
+	schedules []schedule
+}
+
+func NewQueue(jobs repository.JobRepository, config Config) *Queue {
+	return &Queue{
+		jobs:     jobs,
+		config:   config,
+		owner:    workerID(),
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Register sets the handler for jobType. Workers only lease job types that
+// have a handler.
+func (q *Queue) Register(jobType string, handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = handler
+}
+
+// Every enqueues a jobType job at the start of every interval, counted from
+// midnight UTC, under the given schedule name. Each period's job has a
+// unique key, so any number of servers can run the same schedule and the
+// job is still enqueued once.
+func (q *Queue) Every(name string, interval time.Duration, jobType string, payload interface{}) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.schedules = append(q.schedules, schedule{name: name, interval: interval, jobType: jobType, payload: payload})
+}
+
+// Option adjusts a job before it is enqueued.
+type Option func(*models.Job)
+
+// At delays the job until the given time.
+func At(runAt time.Time) Option {
+	return func(job *models.Job) { job.RunAt = runAt }
+}
+
+// UniqueKey makes Enqueue fail with repository.ErrDuplicate when a job with
+// the same key was already enqueued.
+func UniqueKey(key string) Option {
+	return func(job *models.Job) { job.UniqueKey = key }
+}
+
+// MaxAttempts overrides the configured number of attempts.
+func MaxAttempts(attempts int) Option {
+	return func(job *models.Job) { job.MaxAttempts = attempts }
+}
+
+// Enqueue stores a jobType job carrying payload, which must marshal to a
+// BSON document; handlers read it back with Decode.
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload interface{}, opts ...Option) (*models.Job, error) {
+	now := time.Now()
+	job := &models.Job{
+		Type:        jobType,
+		Status:      models.JobQueued,
+		MaxAttempts: q.config.MaxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if payload != nil {
+		raw, err := bson.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("encode %s payload: %w", jobType, err)
+		}
+		if err := bson.Unmarshal(raw, &job.Payload); err != nil {
+			return nil, fmt.Errorf("encode %s payload: %w", jobType, err)
+		}
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+
+	if err := q.jobs.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Decode reads the job's payload into v.
+func Decode(job *models.Job, v interface{}) error {
+	raw, err := bson.Marshal(job.Payload)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(raw, v)
+}
+
+// permanentError marks a failure that retrying cannot fix.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the job goes straight to the dead-letter state
+// instead of being retried.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// Backoff is the delay before retrying a job that failed its attempt-th run.
+func Backoff(attempt int) time.Duration {
+	delay := backoffBase
+	for i := 1; i < attempt && delay < backoffMax; i++ {
+		delay *= 2
+	}
+	if delay > backoffMax {
+		delay = backoffMax
+	}
+	return delay
+}
+
+// Run starts the scheduler and the workers and blocks until ctx is cancelled.
+func (q *Queue) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	wg.Add(q.config.Workers + 1)
+
+	go func() {
+		defer wg.Done()
+		q.schedule(ctx)
+	}()
+	for i := 0; i < q.config.Workers; i++ {
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+
+	log.Printf("Job queue: %d workers as %s", q.config.Workers, q.owner)
+	wg.Wait()
+}
+
+func (q *Queue) work(ctx context.Context) {
+	for {
+		ran, err := q.RunNext(ctx)
+		if err != nil {
+			log.Printf("Job queue: %v", err)
+		}
+		if ran {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(q.config.PollInterval):
+		}
+	}
+}
+
+func (q *Queue) schedule(ctx context.Context) {
+	ticker := time.NewTicker(q.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		q.EnqueueScheduled(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// EnqueueScheduled enqueues the current period's job of every schedule that
+// does not have one yet.
+func (q *Queue) EnqueueScheduled(ctx context.Context, now time.Time) {
+	q.mu.RLock()
+	schedules := append([]schedule(nil), q.schedules...)
+	q.mu.RUnlock()
+
+	for _, s := range schedules {
+		period := now.UTC().Truncate(s.interval)
+		key := fmt.Sprintf("schedule:%s:%d", s.name, period.Unix())
+		_, err := q.Enqueue(ctx, s.jobType, s.payload, At(period), UniqueKey(key))
+		if err != nil && !errors.Is(err, repository.ErrDuplicate) {
+			log.Printf("Job queue: failed to enqueue scheduled %s: %v", s.name, err)
+		}
+	}
+}
+
+// RunNext leases one due job and runs it, reporting whether there was one.
+func (q *Queue) RunNext(ctx context.Context) (bool, error) {
+	now := time.Now()
+	job, err := q.jobs.Lease(ctx, q.types(), q.owner, now, now.Add(q.config.Visibility))
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("lease job: %w", err)
+	}
+
+	// A job leased more often than it may run kept outliving its lease,
+	// most likely by crashing its worker, so it is not run again.
+	if job.Attempts > job.MaxAttempts {
+		return true, q.finish(ctx, job, fmt.Errorf("lease expired on all %d attempts", job.MaxAttempts))
+	}
+
+	return true, q.finish(ctx, job, q.run(ctx, job))
+}
+
+func (q *Queue) run(ctx context.Context, job *models.Job) (err error) {
+	q.mu.RLock()
+	handler := q.handlers[job.Type]
+	q.mu.RUnlock()
+
+	runCtx, cancel := context.WithDeadline(ctx, *job.LeasedUntil)
+	defer cancel()
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return handler(runCtx, job)
+}
+
+// finish records the outcome of a run. Losing the lease in the meantime is
+// not an error: the job was handed to another worker, whose outcome counts.
+func (q *Queue) finish(ctx context.Context, job *models.Job, runErr error) error {
+	now := time.Now()
+
+	var err error
+	var permanent permanentError
+	switch {
+	case runErr == nil:
+		err = q.jobs.Complete(ctx, job.ID, q.owner, now, now.Add(retention))
+	case errors.As(runErr, &permanent) || job.Attempts >= job.MaxAttempts:
+		log.Printf("Job queue: %s job %s is dead: %v", job.Type, job.ID.Hex(), runErr)
+		err = q.jobs.Bury(ctx, job.ID, q.owner, runErr.Error(), now)
+	default:
+		err = q.jobs.Release(ctx, job.ID, q.owner, runErr.Error(), now.Add(Backoff(job.Attempts)), now)
+	}
+
+	if errors.Is(err, repository.ErrConflict) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("record %s job %s: %w", job.Type, job.ID.Hex(), err)
+	}
+	return nil
+}
+
+func (q *Queue) types() []string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	types := make([]string, 0, len(q.handlers))
+	for jobType := range q.handlers {
+		types = append(types, jobType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// workerID names this process in job leases.
+func workerID() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
@@ -0,0 +1,288 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+)
+
+// clockJobs leases jobs as if the clock were offset ahead, so tests can get
+// past backoffs and expired leases without sleeping.
+type clockJobs struct {
+	repository.JobRepository
+	offset time.Duration
+}
+
+func (j *clockJobs) Lease(ctx context.Context, types []string, owner string, now, until time.Time) (*models.Job, error) {
+	return j.JobRepository.Lease(ctx, types, owner, now.Add(j.offset), until.Add(j.offset))
+}
+
+const testJob = "test_job"
+
+// newTestQueue returns a queue over the in-memory store whose test_job
+// handler returns the errors in results one run at a time, then nil.
+func newTestQueue(results ...error) (*Queue, *clockJobs, *int) {
+	store := &clockJobs{JobRepository: repository.NewMemoryJobRepository()}
+	queue := NewQueue(store, Config{Workers: 1, Visibility: time.Minute, MaxAttempts: 3})
+	runs := 0
+	queue.Register(testJob, func(ctx context.Context, job *models.Job) error {
+		runs++
+		if runs <= len(results) {
+			return results[runs-1]
+		}
+		return nil
+	})
+	return queue, store, &runs
+}
+
+func runNext(t *testing.T, queue *Queue) bool {
+	t.Helper()
+	ran, err := queue.RunNext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ran
+}
+
+func findJob(t *testing.T, store repository.JobRepository, job *models.Job) *models.Job {
+	t.Helper()
+	found, err := store.FindByID(context.Background(), job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return found
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{50, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.attempt); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestRunNextSucceeds(t *testing.T) {
+	queue, store, runs := newTestQueue()
+	job, err := queue.Enqueue(context.Background(), testJob, map[string]string{"task": "t1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !runNext(t, queue) || *runs != 1 {
+		t.Fatalf("first RunNext ran %d jobs, want 1", *runs)
+	}
+	if runNext(t, queue) {
+		t.Error("a succeeded job was leased again")
+	}
+	done := findJob(t, store, job)
+	if done.Status != models.JobSucceeded || done.FinishedAt == nil || done.ExpiresAt == nil || done.LeaseOwner != "" {
+		t.Errorf("job = %+v, want succeeded with its lease released", done)
+	}
+}
+
+func TestRunNextRetriesWithBackoff(t *testing.T) {
+	queue, store, runs := newTestQueue(errors.New("gateway down"), errors.New("gateway still down"))
+	job, err := queue.Enqueue(context.Background(), testJob, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		before := time.Now()
+		runNext(t, queue)
+		retried := findJob(t, store, job)
+		if retried.Status != models.JobQueued || retried.Attempts != attempt || retried.LastError == "" {
+			t.Fatalf("after attempt %d job = %+v, want queued with the error", attempt, retried)
+		}
+		if delay := retried.RunAt.Sub(before); delay < Backoff(attempt) || delay > Backoff(attempt)+time.Second {
+			t.Errorf("retry %d is due in %v, want %v", attempt, delay, Backoff(attempt))
+		}
+
+		// Not due until its backoff has passed.
+		if runNext(t, queue) {
+			t.Fatalf("retry %d ran before its backoff", attempt)
+		}
+		store.offset += Backoff(attempt) + time.Second
+	}
+
+	runNext(t, queue)
+	if done := findJob(t, store, job); done.Status != models.JobSucceeded || *runs != 3 {
+		t.Errorf("job is %q after %d runs, want succeeded after 3", done.Status, *runs)
+	}
+}
+
+func TestRunNextBuriesAfterMaxAttempts(t *testing.T) {
+	failure := errors.New("gateway down")
+	queue, store, runs := newTestQueue(failure, failure, failure)
+	job, err := queue.Enqueue(context.Background(), testJob, nil, MaxAttempts(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runNext(t, queue)
+	store.offset = time.Hour
+	runNext(t, queue)
+
+	dead := findJob(t, store, job)
+	if dead.Status != models.JobDead || dead.Attempts != 2 || dead.LastError != failure.Error() {
+		t.Fatalf("job = %+v, want dead after 2 attempts", dead)
+	}
+	store.offset = 24 * time.Hour
+	if runNext(t, queue) || *runs != 2 {
+		t.Errorf("dead job ran again, %d runs", *runs)
+	}
+
+	// An admin retry starts the attempts over.
+	requeued, err := store.Requeue(context.Background(), job.ID, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requeued.Status != models.JobQueued || requeued.Attempts != 0 {
+		t.Errorf("requeued job = %+v, want queued with no attempts", requeued)
+	}
+	runNext(t, queue)
+	if retried := findJob(t, store, job); *runs != 3 || retried.Status != models.JobQueued || retried.Attempts != 1 {
+		t.Errorf("requeued job is %q with %d attempts after %d runs, want queued for a retry after its third run", retried.Status, retried.Attempts, *runs)
+	}
+}
+
+func TestRunNextBuriesPermanentErrors(t *testing.T) {
+	cause := errors.New("payload cannot be decoded")
+	queue, store, runs := newTestQueue(Permanent(cause))
+	job, err := queue.Enqueue(context.Background(), testJob, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runNext(t, queue)
+	dead := findJob(t, store, job)
+	if dead.Status != models.JobDead || dead.Attempts != 1 || dead.LastError != cause.Error() {
+		t.Errorf("job = %+v, want dead after its first attempt", dead)
+	}
+	store.offset = time.Hour
+	if runNext(t, queue) || *runs != 1 {
+		t.Errorf("permanently failed job ran again, %d runs", *runs)
+	}
+	if !errors.Is(Permanent(cause), cause) {
+		t.Error("Permanent does not unwrap to its cause")
+	}
+}
+
+func TestRunNextRetriesPanics(t *testing.T) {
+	store := &clockJobs{JobRepository: repository.NewMemoryJobRepository()}
+	queue := NewQueue(store, Config{Visibility: time.Minute, MaxAttempts: 3})
+	queue.Register(testJob, func(ctx context.Context, job *models.Job) error {
+		panic("nil map")
+	})
+	job, err := queue.Enqueue(context.Background(), testJob, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runNext(t, queue)
+	if retried := findJob(t, store, job); retried.Status != models.JobQueued || retried.LastError != "panic: nil map" {
+		t.Errorf("job = %+v, want queued for a retry", retried)
+	}
+}
+
+func TestRunNextReclaimsExpiredLease(t *testing.T) {
+	queue, store, runs := newTestQueue()
+	job, err := queue.Enqueue(context.Background(), testJob, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A worker that leases the job and dies without finishing it.
+	now := time.Now()
+	if _, err := store.JobRepository.Lease(context.Background(), []string{testJob}, "crashed-worker", now, now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if runNext(t, queue) {
+		t.Fatal("a job was taken over while its lease was still held")
+	}
+
+	store.offset = 2 * time.Minute
+	if !runNext(t, queue) || *runs != 1 {
+		t.Fatal("the expired lease was not taken over")
+	}
+	done := findJob(t, store, job)
+	if done.Status != models.JobSucceeded || done.Attempts != 2 {
+		t.Errorf("job = %+v, want succeeded on its second lease", done)
+	}
+
+	// The crashed worker can no longer record an outcome.
+	err = store.Complete(context.Background(), job.ID, "crashed-worker", now, now)
+	if !errors.Is(err, repository.ErrConflict) {
+		t.Errorf("Complete by the old owner = %v, want ErrConflict", err)
+	}
+}
+
+func TestRunNextBuriesJobThatKeepsLosingItsLease(t *testing.T) {
+	queue, store, runs := newTestQueue()
+	job, err := queue.Enqueue(context.Background(), testJob, nil, MaxAttempts(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	if _, err := store.JobRepository.Lease(context.Background(), []string{testJob}, "crashed-worker", now, now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	store.offset = 2 * time.Minute
+	runNext(t, queue)
+
+	dead := findJob(t, store, job)
+	if dead.Status != models.JobDead || *runs != 0 {
+		t.Errorf("job is %q after %d runs, want dead without running again", dead.Status, *runs)
+	}
+}
+
+func TestEnqueueUniqueKey(t *testing.T) {
+	queue, _, _ := newTestQueue()
+	ctx := context.Background()
+
+	if _, err := queue.Enqueue(ctx, testJob, nil, UniqueKey("settle:t1")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := queue.Enqueue(ctx, testJob, nil, UniqueKey("settle:t1")); !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("second Enqueue = %v, want ErrDuplicate", err)
+	}
+	if _, err := queue.Enqueue(ctx, testJob, nil, UniqueKey("settle:t2")); err != nil {
+		t.Errorf("Enqueue with another key = %v", err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	type payload struct {
+		TaskID string `bson:"task_id"`
+		Amount int    `bson:"amount"`
+	}
+
+	queue, _, _ := newTestQueue()
+	job, err := queue.Enqueue(context.Background(), testJob, payload{TaskID: "t1", Amount: 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got payload
+	if err := Decode(job, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != (payload{TaskID: "t1", Amount: 500}) {
+		t.Errorf("Decode = %+v", got)
+	}
+}
@@ -0,0 +1,49 @@
+package mailer
+
+import (
+	"context"
+	"log"
+	"os"
+)
+
+// Message is a single outgoing email. HTMLBody is optional; when present the
+// message is sent as multipart/alternative with TextBody as the fallback.
+type Message struct {
+	To       string
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// Mailer delivers outgoing email.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewFromEnv selects a Mailer based on MAIL_DRIVER: "smtp" sends through the
+// configured SMTP server, anything else writes messages to MAIL_DIR for local
+// development.
+func NewFromEnv() Mailer {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "Tasklance <no-reply@tasklance.local>"
+	}
+
+	switch os.Getenv("MAIL_DRIVER") {
+	case "smtp":
+		return NewSMTPMailer(SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		})
+	default:
+		dir := os.Getenv("MAIL_DIR")
+		if dir == "" {
+			dir = "tmp/mail"
+		}
+		log.Printf("Mail driver: writing outgoing email to %s", dir)
+		return NewFileMailer(dir, from)
+	}
+}
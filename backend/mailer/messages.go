@@ -0,0 +1,30 @@
+package mailer
+
+import (
+	"fmt"
+	"html"
+)
+
+// VerificationEmail builds the message asking a new user to confirm their address.
+func VerificationEmail(to, firstName, link string) Message {
+	return Message{
+		To:      to,
+		Subject: "Verify your Tasklance email address",
+		TextBody: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nIf you did not create a Tasklance account you can ignore this email.\n",
+			firstName, link),
+		HTMLBody: fmt.Sprintf(`<p>Hi %s,</p><p>Please confirm your email address by clicking the link below:</p><p><a href="%s">Verify my email</a></p><p>If you did not create a Tasklance account you can ignore this email.</p>`,
+			html.EscapeString(firstName), html.EscapeString(link)),
+	}
+}
+
+// PasswordResetEmail builds the message carrying a single-use password reset link.
+func PasswordResetEmail(to, firstName, link string) Message {
+	return Message{
+		To:      to,
+		Subject: "Reset your Tasklance password",
+		TextBody: fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\nThe link can be used once and expires soon. If you did not request a reset you can ignore this email.\n",
+			firstName, link),
+		HTMLBody: fmt.Sprintf(`<p>Hi %s,</p><p>We received a request to reset your password. Click the link below to choose a new one:</p><p><a href="%s">Reset my password</a></p><p>The link can be used once and expires soon. If you did not request a reset you can ignore this email.</p>`,
+			html.EscapeString(firstName), html.EscapeString(link)),
+	}
+}
@@ -0,0 +1,64 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+// Each email template has an HTML version, rendered with html/template so
+// user-supplied text is escaped, and a plain-text fallback.
+var (
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templateFiles, "templates/*.html"))
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templateFiles, "templates/*.txt"))
+)
+
+// NotificationItem is one event listed in a notification email.
+type NotificationItem struct {
+	Title string
+	Body  string
+	// Link opens the related page in the web app; optional.
+	Link string
+	At   time.Time
+}
+
+// NotificationData fills the notification and digest templates.
+type NotificationData struct {
+	FirstName       string
+	Items           []NotificationItem
+	PreferencesLink string
+}
+
+// NotificationEmail builds the message sent the moment a single event happens.
+func NotificationEmail(to string, data NotificationData) (Message, error) {
+	if len(data.Items) != 1 {
+		return Message{}, fmt.Errorf("notification email needs exactly one item, got %d", len(data.Items))
+	}
+	return render(to, data.Items[0].Title, "notification", data)
+}
+
+// DigestEmail builds the daily summary of every event since the last digest.
+func DigestEmail(to string, data NotificationData) (Message, error) {
+	subject := "Your Tasklance daily digest: 1 update"
+	if len(data.Items) != 1 {
+		subject = fmt.Sprintf("Your Tasklance daily digest: %d updates", len(data.Items))
+	}
+	return render(to, subject, "digest", data)
+}
+
+func render(to, subject, name string, data NotificationData) (Message, error) {
+	var text, html bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&text, name+".txt", data); err != nil {
+		return Message{}, err
+	}
+	if err := htmlTemplates.ExecuteTemplate(&html, name+".html", data); err != nil {
+		return Message{}, err
+	}
+	return Message{To: to, Subject: subject, TextBody: text.String(), HTMLBody: html.String()}, nil
+}
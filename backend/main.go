@@ -1,10 +1,14 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
 
+	"github.com/Vivekpdy/tasklanceweb/backend/bootstrap"
 	"github.com/Vivekpdy/tasklanceweb/backend/config"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
 	"github.com/Vivekpdy/tasklanceweb/backend/routes"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -19,6 +23,16 @@ func main() {
 	// Initialize database
 	config.InitDB()
 
+	// Create the first admin account if one is configured
+	if adminEmail := os.Getenv("ADMIN_EMAIL"); adminEmail != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := bootstrap.EnsureAdmin(ctx, repository.NewMongoUserRepository(config.MongoDB), adminEmail, os.Getenv("ADMIN_PASSWORD"))
+		cancel()
+		if err != nil {
+			log.Fatalf("Failed to bootstrap admin account: %v", err)
+		}
+	}
+
 	// Get port from environment or use default
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -31,8 +45,13 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	// Initialize router
-	router := routes.SetupRouter()
+	// Initialize router and start the background job workers
+	deps, err := routes.ProductionDependencies()
+	if err != nil {
+		log.Fatalf("Failed to configure server: %v", err)
+	}
+	go deps.Queue.Run(context.Background())
+	router := routes.NewRouter(deps)
 
 	// Start server
 	log.Printf("Server starting on port %s", port)
@@ -1,15 +1,22 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
 	"github.com/Vivekpdy/tasklanceweb/backend/utils"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware validates the bearer access token and rejects it if the
+// session it was issued for has been revoked or has expired, or if the
+// account has been suspended.
+func AuthMiddleware(sessions repository.SessionRepository, users repository.UserRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -26,21 +33,79 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		token := parts[1]
-		jwtSecret := os.Getenv("JWT_SECRET")
-		
-		claims, err := utils.ValidateToken(token, jwtSecret)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		if !authenticate(c, sessions, users, parts[1]) {
 			c.Abort()
 			return
 		}
+		c.Next()
+	}
+}
 
-		// Set user information in context
-		c.Set("userID", claims.UserID)
-		c.Set("userEmail", claims.Email)
-		c.Set("userType", claims.UserType)
+// StreamAuthMiddleware is AuthMiddleware for WebSocket and server-sent event
+// streams. Browsers cannot set headers on those requests, so the access token
+// may also be passed as the access_token query parameter.
+func StreamAuthMiddleware(sessions repository.SessionRepository, users repository.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Query("access_token")
+		if parts := strings.Split(c.GetHeader("Authorization"), " "); len(parts) == 2 && parts[0] == "Bearer" {
+			token = parts[1]
+		}
+		if token == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Access token required"})
+			c.Abort()
+			return
+		}
 
+		if !authenticate(c, sessions, users, token) {
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
+
+// authenticate validates token and its session and stores the user on the
+// context. It writes the error response and returns false on failure.
+func authenticate(c *gin.Context, sessions repository.SessionRepository, users repository.UserRepository, token string) bool {
+	jwtSecret := os.Getenv("JWT_SECRET")
+
+	claims, err := utils.ValidateToken(token, jwtSecret)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		return false
+	}
+
+	sessionID, err := primitive.ObjectIDFromHex(claims.SessionID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	session, err := sessions.FindByID(ctx, sessionID)
+	if err != nil || !session.IsActive(time.Now()) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+		return false
+	}
+
+	user, err := users.FindByID(ctx, session.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return false
+	}
+
+	if user.IsSuspended() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account suspended"})
+		return false
+	}
+
+	// Set user information in context; the role comes from the stored
+	// user so that role changes apply without waiting for a new token
+	c.Set("userID", user.ID.Hex())
+	c.Set("userEmail", user.Email)
+	c.Set("userType", user.UserType)
+	c.Set("sessionID", claims.SessionID)
+	return true
+}
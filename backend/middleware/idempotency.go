@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header clients set to make a retry safe.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed from a stored key.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// IdempotencyKeyTTL is how long keys are remembered, taken from
+// IDEMPOTENCY_KEY_TTL.
+func IdempotencyKeyTTL() time.Duration {
+	if value := os.Getenv("IDEMPOTENCY_KEY_TTL"); value != "" {
+		if ttl, err := time.ParseDuration(value); err == nil && ttl > 0 {
+			return ttl
+		}
+	}
+	return 24 * time.Hour
+}
+
+// Idempotency makes a handler safe to retry when the client sends an
+// Idempotency-Key header. The first request with a key runs normally and its
+// response is stored for ttl; a retry with the same key and body receives the
+// stored response without running the handler again. Reusing a key for a
+// different request is rejected with 422, and a retry that arrives while the
+// original is still running gets 409. Server errors are not stored, so the
+// request can be retried with the same key. Requests without the header pass
+// straight through. It must run after AuthMiddleware.
+func Idempotency(keys repository.IdempotencyKeyRepository, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 255 characters"})
+			c.Abort()
+			return
+		}
+
+		userID, err := primitive.ObjectIDFromHex(c.GetString("userID"))
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		now := time.Now()
+		record := &models.IdempotencyKey{
+			UserID:      userID,
+			Key:         key,
+			Fingerprint: requestFingerprint(c.Request, body),
+			ExpiresAt:   now.Add(ttl),
+			CreatedAt:   now,
+		}
+		if err := keys.Create(ctx, record); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				replay(c, keys, record)
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record idempotency key"})
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// The handler has finished, so its own timeout no longer applies
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			if err := keys.Delete(ctx, record.ID); err != nil {
+				log.Printf("Failed to release idempotency key %q: %v", key, err)
+			}
+			return
+		}
+		record.ResponseStatus = recorder.Status()
+		record.ContentType = recorder.Header().Get("Content-Type")
+		record.ResponseBody = recorder.body.Bytes()
+		if err := keys.Update(ctx, record); err != nil {
+			log.Printf("Failed to store response for idempotency key %q: %v", key, err)
+		}
+	}
+}
+
+// replay answers a request whose key is already taken from the stored record.
+func replay(c *gin.Context, keys repository.IdempotencyKeyRepository, attempt *models.IdempotencyKey) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stored, err := keys.Find(ctx, attempt.UserID, attempt.Key)
+	if err != nil {
+		// Expired or released between the insert and the lookup
+		c.JSON(http.StatusConflict, gin.H{"error": "Idempotency-Key is being reused, please retry"})
+		c.Abort()
+		return
+	}
+
+	switch {
+	case stored.Fingerprint != attempt.Fingerprint:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used for a different request"})
+	case !stored.Completed():
+		c.JSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is still in progress"})
+	default:
+		c.Header(IdempotentReplayedHeader, "true")
+		c.Data(stored.ResponseStatus, stored.ContentType, stored.ResponseBody)
+	}
+	c.Abort()
+}
+
+// requestFingerprint identifies a request by its method, path and body, so a
+// key cannot be replayed against another endpoint either.
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder copies everything the handler writes so it can be stored.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// idempotentServer serves POST /charges behind Idempotency for a fixed user.
+// The handler answers with the status in status, counting its runs, and
+// waits for release when it is set.
+type idempotentServer struct {
+	router  *gin.Engine
+	runs    atomic.Int32
+	status  atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func newIdempotentServer() *idempotentServer {
+	gin.SetMode(gin.TestMode)
+	s := &idempotentServer{router: gin.New()}
+	s.status.Store(http.StatusCreated)
+
+	userID := primitive.NewObjectID().Hex()
+	s.router.POST("/charges", func(c *gin.Context) {
+		c.Set("userID", userID)
+	}, Idempotency(repository.NewMemoryIdempotencyKeyRepository(), time.Hour), func(c *gin.Context) {
+		run := s.runs.Add(1)
+		if s.started != nil {
+			s.started <- struct{}{}
+			<-s.release
+		}
+		c.JSON(int(s.status.Load()), gin.H{"run": run})
+	})
+	return s
+}
+
+func (s *idempotentServer) post(key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/charges", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysStoredResponse(t *testing.T) {
+	s := newIdempotentServer()
+
+	first := s.post("key-1", `{"amount":100}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first request = %d, want 201", first.Code)
+	}
+	second := s.post("key-1", `{"amount":100}`)
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s, want 201 %s", second.Code, second.Body.String(), first.Body.String())
+	}
+	if second.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("replay is missing the %s header", IdempotentReplayedHeader)
+	}
+	if runs := s.runs.Load(); runs != 1 {
+		t.Errorf("handler ran %d times, want 1", runs)
+	}
+
+	// Requests without a key are never deduplicated.
+	s.post("", `{"amount":100}`)
+	s.post("", `{"amount":100}`)
+	if runs := s.runs.Load(); runs != 3 {
+		t.Errorf("handler ran %d times, want 3", runs)
+	}
+}
+
+func TestIdempotencyRejectsReusedKey(t *testing.T) {
+	s := newIdempotentServer()
+
+	s.post("key-1", `{"amount":100}`)
+	if w := s.post("key-1", `{"amount":200}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("same key with another body = %d, want 422", w.Code)
+	}
+	if runs := s.runs.Load(); runs != 1 {
+		t.Errorf("handler ran %d times, want 1", runs)
+	}
+}
+
+func TestIdempotencyRejectsRequestInFlight(t *testing.T) {
+	s := newIdempotentServer()
+	s.started = make(chan struct{})
+	s.release = make(chan struct{})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- s.post("key-1", `{"amount":100}`) }()
+	<-s.started
+
+	if w := s.post("key-1", `{"amount":100}`); w.Code != http.StatusConflict {
+		t.Errorf("retry while in flight = %d, want 409", w.Code)
+	}
+
+	close(s.release)
+	if w := <-done; w.Code != http.StatusCreated {
+		t.Errorf("original request = %d, want 201", w.Code)
+	}
+	if runs := s.runs.Load(); runs != 1 {
+		t.Errorf("handler ran %d times, want 1", runs)
+	}
+}
+
+func TestIdempotencyReleasesKeyAfterServerError(t *testing.T) {
+	s := newIdempotentServer()
+
+	s.status.Store(http.StatusServiceUnavailable)
+	if w := s.post("key-1", `{"amount":100}`); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("failing request = %d, want 503", w.Code)
+	}
+
+	s.status.Store(http.StatusCreated)
+	w := s.post("key-1", `{"amount":100}`)
+	if w.Code != http.StatusCreated || w.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Errorf("retry after a server error = %d replayed=%q, want a fresh 201", w.Code, w.Header().Get(IdempotentReplayedHeader))
+	}
+	if runs := s.runs.Load(); runs != 2 {
+		t.Errorf("handler ran %d times, want 2", runs)
+	}
+}
+
+func TestIdempotencyKeepsClientErrors(t *testing.T) {
+	s := newIdempotentServer()
+
+	s.status.Store(http.StatusBadRequest)
+	s.post("key-1", `{"amount":100}`)
+	s.status.Store(http.StatusCreated)
+	if w := s.post("key-1", `{"amount":100}`); w.Code != http.StatusBadRequest {
+		t.Errorf("retry after a client error = %d, want the stored 400", w.Code)
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/v1/notifications/stream", "/api/v1/notifications/stream"},
+		{"/api/v1/notifications/stream?access_token=eyJ.abc.def", "/api/v1/notifications/stream?access_token=REDACTED"},
+		{"/api/v1/chat/ws?conversation=42&access_token=eyJ.abc.def&x=1", "/api/v1/chat/ws?conversation=42&access_token=REDACTED&x=1"},
+		{"/api/v1/auth/verify?token=eyJ.abc.def", "/api/v1/auth/verify?token=REDACTED"},
+		{"/api/v1/auth/verify?%74oken=eyJ.abc.def", "/api/v1/auth/verify?%74oken=REDACTED"},
+		{"/api/v1/stream?access_token", "/api/v1/stream?access_token=REDACTED"},
+		{"/api/v1/tasks/search?q=token&limit=10", "/api/v1/tasks/search?q=token&limit=10"},
+	}
+	for _, tt := range tests {
+		if got := redactQuery(tt.path); got != tt.want {
+			t.Errorf("redactQuery(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLoggerOmitsAccessToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var out bytes.Buffer
+	router := gin.New()
+	router.Use(gin.LoggerWithConfig(gin.LoggerConfig{Formatter: logFormatter, Output: &out}))
+	router.GET("/stream", func(c *gin.Context) {
+		c.String(http.StatusOK, c.Query("access_token"))
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stream?access_token=secret-jwt", nil))
+
+	if w.Body.String() != "secret-jwt" {
+		t.Errorf("handler saw access_token %q, want it untouched", w.Body.String())
+	}
+	if strings.Contains(out.String(), "secret-jwt") || !strings.Contains(out.String(), "access_token=REDACTED") {
+		t.Errorf("log line = %q, want the token redacted", out.String())
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/policy"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole allows the request through only if the authenticated user has
+// one of the given roles. It must run after AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userType := c.GetString("userType")
+		for _, role := range roles {
+			if userType == role {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to perform this action"})
+		c.Abort()
+	}
+}
+
+// RequirePermission allows the request through only if the authenticated
+// user's role is granted permission by the policy table. It must run after
+// AuthMiddleware.
+func RequirePermission(permission policy.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !policy.Allowed(c.GetString("userType"), permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to perform this action"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
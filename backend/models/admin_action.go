@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Moderation actions recorded in AdminAction.Action.
+const (
+	AdminActionSuspendUser   = "suspend_user"
+	AdminActionReinstateUser = "reinstate_user"
+	AdminActionCancelTask    = "cancel_task"
+	AdminActionHideReview    = "hide_review"
+	AdminActionUnhideReview  = "unhide_review"
+	AdminActionRetryJob      = "retry_job"
+)
+
+// AdminAction is an append-only audit record of a moderation decision.
+type AdminAction struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	AdminID    primitive.ObjectID `bson:"admin_id" json:"admin_id"`
+	Action     string             `bson:"action" json:"action"`
+	TargetType string             `bson:"target_type" json:"target_type"` // user, task, review, job
+	TargetID   primitive.ObjectID `bson:"target_id" json:"target_id"`
+	Reason     string             `bson:"reason" json:"reason"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Bid statuses.
+const (
+	BidStatusPending   = "pending"
+	BidStatusCountered = "countered"
+	BidStatusAccepted  = "accepted"
+	BidStatusRejected  = "rejected"
+	BidStatusWithdrawn = "withdrawn"
+)
+
+// OpenBidStatuses are the statuses of bids still awaiting a decision.
+var OpenBidStatuses = []string{BidStatusPending, BidStatusCountered}
+
+// Bid negotiation events. Each event is a named transition in bidTransitions.
+const (
+	BidEventSubmit         = "submit"
+	BidEventRevise         = "revise"
+	BidEventCounter        = "counter"
+	BidEventAcceptCounter  = "accept_counter"
+	BidEventDeclineCounter = "decline_counter"
+	BidEventWithdraw       = "withdraw"
+	BidEventAccept         = "accept"
+	BidEventReject         = "reject"
+)
+
+// ErrIllegalBidTransition is returned when an event is not allowed from the
+// bid's current status.
+var ErrIllegalBidTransition = errors.New("illegal bid status transition")
+
+type bidTransition struct {
+	from []string
+	to   string
+}
+
+// bidTransitions is the bid state machine. A counter-offer waits for the
+// freelancer's answer; either answer returns the bid to pending, on the
+// client's terms if accepted, so the client can then accept the bid.
+var bidTransitions = map[string]bidTransition{
+	BidEventRevise:         {from: []string{BidStatusPending}, to: BidStatusPending},
+	BidEventCounter:        {from: []string{BidStatusPending}, to: BidStatusCountered},
+	BidEventAcceptCounter:  {from: []string{BidStatusCountered}, to: BidStatusPending},
+	BidEventDeclineCounter: {from: []string{BidStatusCountered}, to: BidStatusPending},
+	BidEventWithdraw:       {from: []string{BidStatusPending, BidStatusCountered}, to: BidStatusWithdrawn},
+	BidEventAccept:         {from: []string{BidStatusPending}, to: BidStatusAccepted},
+	BidEventReject:         {from: []string{BidStatusPending, BidStatusCountered}, to: BidStatusRejected},
+}
+
+// BidRevision is one entry of a bid's negotiation thread. Amount and
+// ProposedDeadline are the terms the entry put on the table: the client's
+// for a counter-offer, otherwise the bid's own terms after the event.
+type BidRevision struct {
+	Event            string              `bson:"event" json:"event"`
+	From             string              `bson:"from,omitempty" json:"from,omitempty"`
+	To               string              `bson:"to" json:"to"`
+	ActorID          *primitive.ObjectID `bson:"actor_id,omitempty" json:"actor_id,omitempty"`
+	Amount           float64             `bson:"amount" json:"amount"`
+	ProposedDeadline time.Time           `bson:"proposed_deadline" json:"proposed_deadline"`
+	Note             string              `bson:"note,omitempty" json:"note,omitempty"`
+	At               time.Time           `bson:"at" json:"at"`
+}
+
+// Submit starts the negotiation thread of a new bid.
+func (b *Bid) Submit(actorID primitive.ObjectID, at time.Time) {
+	b.Status = BidStatusPending
+	b.Negotiation = []BidRevision{{
+		Event:            BidEventSubmit,
+		To:               BidStatusPending,
+		ActorID:          &actorID,
+		Amount:           b.Amount,
+		ProposedDeadline: b.ProposedDeadline,
+		At:               at,
+	}}
+	b.CreatedAt = at
+	b.UpdatedAt = at
+}
+
+// CanTransition reports whether event is allowed from the bid's current status.
+func (b *Bid) CanTransition(event string) bool {
+	transition, ok := bidTransitions[event]
+	if !ok {
+		return false
+	}
+	for _, from := range transition.from {
+		if b.Status == from {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition applies event to the bid, appending an entry to its
+// negotiation thread. Accepting a counter-offer adopts its terms. Use
+// Counter for counter-offers. actorID is nil for transitions made by the
+// system.
+func (b *Bid) Transition(event string, actorID *primitive.ObjectID, note string, at time.Time) error {
+	if event == BidEventCounter || !b.CanTransition(event) {
+		return ErrIllegalBidTransition
+	}
+
+	if event == BidEventAcceptCounter {
+		counter := b.PendingCounter()
+		if counter == nil {
+			return ErrIllegalBidTransition
+		}
+		b.Amount = counter.Amount
+		b.ProposedDeadline = counter.ProposedDeadline
+	}
+	b.append(event, actorID, b.Amount, b.ProposedDeadline, note, at)
+	return nil
+}
+
+// Counter records the client's counter-offer. The bid keeps its own terms
+// until the freelancer accepts the offer.
+func (b *Bid) Counter(actorID primitive.ObjectID, amount float64, deadline time.Time, note string, at time.Time) error {
+	if !b.CanTransition(BidEventCounter) {
+		return ErrIllegalBidTransition
+	}
+	b.append(BidEventCounter, &actorID, amount, deadline, note, at)
+	return nil
+}
+
+// PendingCounter returns the counter-offer awaiting the freelancer's answer,
+// or nil when there is none.
+func (b *Bid) PendingCounter() *BidRevision {
+	if b.Status != BidStatusCountered {
+		return nil
+	}
+	for i := len(b.Negotiation) - 1; i >= 0; i-- {
+		if b.Negotiation[i].Event == BidEventCounter {
+			return &b.Negotiation[i]
+		}
+	}
+	return nil
+}
+
+func (b *Bid) append(event string, actorID *primitive.ObjectID, amount float64, deadline time.Time, note string, at time.Time) {
+	to := bidTransitions[event].to
+	b.Negotiation = append(b.Negotiation, BidRevision{
+		Event:            event,
+		From:             b.Status,
+		To:               to,
+		ActorID:          actorID,
+		Amount:           amount,
+		ProposedDeadline: deadline,
+		Note:             note,
+		At:               at,
+	})
+	b.Status = to
+	b.UpdatedAt = at
+}
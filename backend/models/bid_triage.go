@@ -0,0 +1,50 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// ErrNotShortlistable is returned when shortlisting a bid that is no longer
+// awaiting a decision.
+var ErrNotShortlistable = errors.New("only open bids can be shortlisted")
+
+// ClientBid is a bid as the task owner sees it, with the owner's private
+// triage fields that are hidden from everybody else.
+type ClientBid struct {
+	Bid
+	Archived   bool   `json:"archived"`
+	ClientNote string `json:"client_note,omitempty"`
+}
+
+// ForClient returns the task owner's view of the bid.
+func (b *Bid) ForClient() ClientBid {
+	return ClientBid{Bid: *b, Archived: b.Archived, ClientNote: b.ClientNote}
+}
+
+// Triage applies the task owner's triage to the bid; nil values are left
+// unchanged. Shortlisting takes a bid out of the archive and archiving
+// takes it off the shortlist, so a bid is never in both.
+func (b *Bid) Triage(shortlisted, archived *bool, note *string, at time.Time) error {
+	if shortlisted != nil {
+		if *shortlisted && !b.Shortlisted && !slices.Contains(OpenBidStatuses, b.Status) {
+			return ErrNotShortlistable
+		}
+		b.Shortlisted = *shortlisted
+		if b.Shortlisted {
+			b.Archived = false
+		}
+	}
+	if archived != nil {
+		b.Archived = *archived
+		if b.Archived {
+			b.Shortlisted = false
+		}
+	}
+	if note != nil {
+		b.ClientNote = *note
+	}
+	b.UpdatedAt = at
+	return nil
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Bidding modes in Task.BiddingMode.
+const (
+	// BiddingOpen lets everyone see every bid, and the client accept one at
+	// any time.
+	BiddingOpen = "open"
+	// BiddingSealed hides bids from other bidders until bidding closes,
+	// when the lowest are shortlisted for the client.
+	BiddingSealed = "sealed"
+	// BiddingReverseAuction shows bidders the lowest bid to beat until
+	// bidding closes, when the lowest bid wins.
+	BiddingReverseAuction = "reverse_auction"
+)
+
+// IsAuction reports whether bidding on the task closes at BiddingClosesAt.
+func (t *Task) IsAuction() bool {
+	return t.BiddingMode == BiddingSealed || t.BiddingMode == BiddingReverseAuction
+}
+
+// AcceptsBids reports whether freelancers may bid on the task, or revise
+// their bids, at now.
+func (t *Task) AcceptsBids(now time.Time) bool {
+	if t.Status != TaskStatusOpen {
+		return false
+	}
+	if !t.IsAuction() {
+		return true
+	}
+	return t.BiddingClosedAt == nil && t.BiddingClosesAt != nil && now.Before(*t.BiddingClosesAt)
+}
+
+// AwaitingClose reports whether the task is an auction whose bids have not
+// been picked yet. The client cannot accept or counter bids until then.
+func (t *Task) AwaitingClose() bool {
+	return t.IsAuction() && t.BiddingClosedAt == nil
+}
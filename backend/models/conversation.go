@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxMessageLength bounds the body of a single chat message, in bytes.
+const MaxMessageLength = 4000
+
+// Conversation is the private chat between a task's owner and one freelancer
+// who bid on it or was assigned to it. TaskID and FreelancerID are unique
+// together, so each pair has a single thread per task.
+type Conversation struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TaskID        primitive.ObjectID `bson:"task_id" json:"task_id"`
+	ClientID      primitive.ObjectID `bson:"client_id" json:"client_id"`
+	FreelancerID  primitive.ObjectID `bson:"freelancer_id" json:"freelancer_id"`
+	LastMessageAt *time.Time         `bson:"last_message_at,omitempty" json:"last_message_at,omitempty"`
+	Unread        int64              `bson:"-" json:"unread"` // computed for the caller
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// HasParticipant reports whether userID is one of the two sides of the conversation.
+func (c *Conversation) HasParticipant(userID primitive.ObjectID) bool {
+	return c.ClientID == userID || c.FreelancerID == userID
+}
+
+// Message is one chat message. ReadAt is set once the other participant has
+// read it, which is what read receipts report.
+type Message struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ConversationID primitive.ObjectID `bson:"conversation_id" json:"conversation_id"`
+	SenderID       primitive.ObjectID `bson:"sender_id" json:"sender_id"`
+	Body           string             `bson:"body" json:"body"`
+	ReadAt         *time.Time         `bson:"read_at,omitempty" json:"read_at,omitempty"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+}
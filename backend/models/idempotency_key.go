@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IdempotencyKey remembers a mutating request made with an Idempotency-Key
+// header so that a retry gets the original response instead of repeating
+// the request. Keys are scoped to the user who sent them.
+type IdempotencyKey struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty"`
+	UserID primitive.ObjectID `bson:"user_id"`
+	Key    string             `bson:"key"`
+	// Fingerprint is a hash of the method, path and body the key was first used with.
+	Fingerprint string `bson:"fingerprint"`
+	// ResponseStatus is zero while the original request is still running.
+	ResponseStatus int       `bson:"response_status"`
+	ContentType    string    `bson:"content_type,omitempty"`
+	ResponseBody   []byte    `bson:"response_body,omitempty"`
+	ExpiresAt      time.Time `bson:"expires_at"`
+	CreatedAt      time.Time `bson:"created_at"`
+}
+
+// Completed reports whether the original request has finished and its
+// response can be replayed.
+func (k *IdempotencyKey) Completed() bool {
+	return k.ResponseStatus != 0
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Job statuses. A queued job waits for RunAt; a running job is leased by a
+// worker until LeasedUntil, after which another worker may take it over; a
+// dead job used up its attempts and waits for an admin to retry it.
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobDead      = "dead"
+)
+
+// Job is a unit of deferred work run by the background workers.
+type Job struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Type        string             `bson:"type" json:"type"`
+	Payload     bson.M             `bson:"payload,omitempty" json:"payload,omitempty"`
+	Status      string             `bson:"status" json:"status"`
+	Attempts    int                `bson:"attempts" json:"attempts"` // leases taken so far
+	MaxAttempts int                `bson:"max_attempts" json:"max_attempts"`
+	RunAt       time.Time          `bson:"run_at" json:"run_at"`                                 // earliest time a worker may lease it
+	LeaseOwner  string             `bson:"lease_owner,omitempty" json:"lease_owner,omitempty"`   // worker holding the lease
+	LeasedUntil *time.Time         `bson:"leased_until,omitempty" json:"leased_until,omitempty"` // visibility timeout
+	LastError   string             `bson:"last_error,omitempty" json:"last_error,omitempty"`     // error of the latest failed attempt
+	UniqueKey   string             `bson:"unique_key,omitempty" json:"unique_key,omitempty"`     // prevents enqueuing the same job twice
+	FinishedAt  *time.Time         `bson:"finished_at,omitempty" json:"finished_at,omitempty"`   // when it succeeded or died
+	ExpiresAt   *time.Time         `bson:"expires_at,omitempty" json:"-"`                        // TTL for succeeded jobs
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Ledger account types. Client and freelancer accounts belong to a user, an
+// escrow account belongs to a task and there is a single platform account.
+const (
+	LedgerAccountClient     = "client"
+	LedgerAccountEscrow     = "escrow"
+	LedgerAccountFreelancer = "freelancer"
+	LedgerAccountPlatform   = "platform"
+)
+
+// Ledger entry types, one per money movement in the escrow flow.
+const (
+	LedgerEntryFund    = "fund"
+	LedgerEntryRelease = "release"
+	LedgerEntryRefund  = "refund"
+)
+
+// ErrUnbalancedEntry is returned for an entry whose postings do not sum to zero.
+var ErrUnbalancedEntry = errors.New("ledger entry postings must balance")
+
+// LedgerPosting moves Amount (in cents) into one account; negative amounts
+// move money out of it.
+type LedgerPosting struct {
+	Account string              `bson:"account" json:"account"`
+	OwnerID *primitive.ObjectID `bson:"owner_id,omitempty" json:"owner_id,omitempty"`
+	Amount  int64               `bson:"amount" json:"amount"`
+}
+
+// LedgerEntry is an immutable double-entry transaction. Its postings always
+// sum to zero, so money is only ever moved between accounts, never created.
+type LedgerEntry struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Type         string             `bson:"type" json:"type"`
+	PaymentID    primitive.ObjectID `bson:"payment_id" json:"payment_id"`
+	TaskID       primitive.ObjectID `bson:"task_id" json:"task_id"`
+	ClientID     primitive.ObjectID `bson:"client_id" json:"client_id"`
+	FreelancerID primitive.ObjectID `bson:"freelancer_id" json:"freelancer_id"`
+	Postings     []LedgerPosting    `bson:"postings" json:"postings"`
+	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// Validate checks that the entry has at least two postings and balances.
+func (e *LedgerEntry) Validate() error {
+	if len(e.Postings) < 2 {
+		return ErrUnbalancedEntry
+	}
+	var sum int64
+	for _, posting := range e.Postings {
+		sum += posting.Amount
+	}
+	if sum != 0 {
+		return ErrUnbalancedEntry
+	}
+	return nil
+}
+
+// ToCents converts a currency amount to the integer cents the ledger stores.
+func ToCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// FromCents converts ledger cents back to a currency amount.
+func FromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
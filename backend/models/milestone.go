@@ -0,0 +1,135 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Milestone statuses. A milestone is paid for on its own: the client funds
+// it into escrow, the freelancer submits the work and approval releases the
+// funds.
+const (
+	MilestoneStatusPending   = "pending"
+	MilestoneStatusFunded    = "funded"
+	MilestoneStatusSubmitted = "submitted"
+	MilestoneStatusApproved  = "approved"
+	MilestoneStatusCancelled = "cancelled"
+)
+
+// Milestone lifecycle events, applied through milestoneTransitions.
+const (
+	MilestoneEventFund            = "fund"
+	MilestoneEventSubmit          = "submit"
+	MilestoneEventRequestRevision = "request_revision"
+	MilestoneEventApprove         = "approve"
+	MilestoneEventCancel          = "cancel"
+)
+
+// milestoneTransitions is the milestone state machine. Approving a funded
+// milestone that was never submitted releases it early, which is the
+// client's call to make.
+var milestoneTransitions = map[string]taskTransition{
+	MilestoneEventFund:            {from: []string{MilestoneStatusPending}, to: MilestoneStatusFunded},
+	MilestoneEventSubmit:          {from: []string{MilestoneStatusFunded}, to: MilestoneStatusSubmitted},
+	MilestoneEventRequestRevision: {from: []string{MilestoneStatusSubmitted}, to: MilestoneStatusFunded},
+	MilestoneEventApprove:         {from: []string{MilestoneStatusFunded, MilestoneStatusSubmitted}, to: MilestoneStatusApproved},
+	MilestoneEventCancel:          {from: []string{MilestoneStatusPending, MilestoneStatusFunded, MilestoneStatusSubmitted}, to: MilestoneStatusCancelled},
+}
+
+// Milestone is one separately paid stage of a task.
+type Milestone struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TaskID        primitive.ObjectID `bson:"task_id" json:"task_id"`
+	Title         string             `bson:"title" json:"title"`
+	Description   string             `bson:"description,omitempty" json:"description,omitempty"`
+	Amount        float64            `bson:"amount" json:"amount"`
+	DueDate       time.Time          `bson:"due_date" json:"due_date"`
+	Status        string             `bson:"status" json:"status"` // pending, funded, submitted, approved, cancelled
+	StatusHistory []TaskStatusChange `bson:"status_history,omitempty" json:"status_history,omitempty"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// ProposedMilestone is a milestone a freelancer proposes with a bid. Accepting
+// the bid turns its proposals into the task's milestones.
+type ProposedMilestone struct {
+	Title   string    `bson:"title" json:"title"`
+	Amount  float64   `bson:"amount" json:"amount"`
+	DueDate time.Time `bson:"due_date" json:"due_date"`
+}
+
+// CanTransition reports whether event is allowed from the milestone's current status.
+func (m *Milestone) CanTransition(event string) bool {
+	transition, ok := milestoneTransitions[event]
+	if !ok {
+		return false
+	}
+	for _, from := range transition.from {
+		if m.Status == from {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition applies event to the milestone and records it in the status history.
+func (m *Milestone) Transition(event string, actorID *primitive.ObjectID, note string, at time.Time) error {
+	if !m.CanTransition(event) {
+		return ErrIllegalTransition
+	}
+
+	to := milestoneTransitions[event].to
+	m.StatusHistory = append(m.StatusHistory, TaskStatusChange{
+		From:    m.Status,
+		To:      to,
+		Event:   event,
+		ActorID: actorID,
+		Note:    note,
+		At:      at,
+	})
+	m.Status = to
+	m.UpdatedAt = at
+	return nil
+}
+
+// TaskProgress summarizes a task's milestones. Cancelled milestones are left out.
+type TaskProgress struct {
+	Milestones     int     `json:"milestones"`
+	Approved       int     `json:"approved"`
+	TotalAmount    float64 `json:"total_amount"`
+	ApprovedAmount float64 `json:"approved_amount"`
+	// Percent is the share of the milestones' value that has been approved, 0-100.
+	Percent float64 `json:"percent"`
+}
+
+// MilestoneProgress computes task progress from its milestones, or returns
+// nil if the task has none.
+func MilestoneProgress(milestones []Milestone) *TaskProgress {
+	progress := &TaskProgress{}
+	var total, approved int64
+	for _, milestone := range milestones {
+		if milestone.Status == MilestoneStatusCancelled {
+			continue
+		}
+		cents := ToCents(milestone.Amount)
+		progress.Milestones++
+		total += cents
+		if milestone.Status == MilestoneStatusApproved {
+			progress.Approved++
+			approved += cents
+		}
+	}
+	if progress.Milestones == 0 {
+		return nil
+	}
+
+	progress.TotalAmount = FromCents(total)
+	progress.ApprovedAmount = FromCents(approved)
+	if total > 0 {
+		progress.Percent = float64(approved*10000/total) / 100
+	} else {
+		progress.Percent = float64(progress.Approved*10000/progress.Milestones) / 100
+	}
+	return progress
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Notification types, one per event users are told about.
+const (
+	NotificationBidReceived      = "bid_received"
+	NotificationBidAccepted      = "bid_accepted"
+	NotificationPaymentHeld      = "payment_held"
+	NotificationPaymentFailed    = "payment_failed"
+	NotificationPaymentCompleted = "payment_completed"
+	NotificationPaymentRefunded  = "payment_refunded"
+	NotificationReviewReceived   = "review_received"
+	NotificationBidRejected      = "bid_rejected"
+	NotificationTaskExpired      = "task_expired"
+	NotificationTaskOverdue      = "task_overdue"
+	NotificationDeadlineSoon     = "deadline_soon"
+	NotificationBidWithdrawn     = "bid_withdrawn"
+	NotificationBidCountered     = "bid_countered"
+	NotificationCounterAccepted  = "counter_accepted"
+	NotificationCounterDeclined  = "counter_declined"
+	NotificationBiddingClosed    = "bidding_closed"
+	NotificationBidShortlisted   = "bid_shortlisted"
+)
+
+// Notification tells a user about something that happened to their tasks,
+// bids or payments. ResourceID is the bid, payment or review it is about.
+type Notification struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID        primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	Type          string              `bson:"type" json:"type"`
+	Title         string              `bson:"title" json:"title"`
+	Body          string              `bson:"body" json:"body"`
+	TaskID        *primitive.ObjectID `bson:"task_id,omitempty" json:"task_id,omitempty"`
+	ResourceID    *primitive.ObjectID `bson:"resource_id,omitempty" json:"resource_id,omitempty"`
+	ReadAt        *time.Time          `bson:"read_at,omitempty" json:"read_at,omitempty"`
+	DigestPending bool                `bson:"digest_pending,omitempty" json:"-"` // waiting for the next daily digest
+	CreatedAt     time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// IsRead reports whether the user has marked the notification as read.
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
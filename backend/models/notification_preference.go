@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Email delivery modes in NotificationPreference.Email.
+const (
+	// EmailInstant sends an email as soon as each event happens.
+	EmailInstant = "instant"
+	// EmailDailyDigest collects events into one email a day.
+	EmailDailyDigest = "daily_digest"
+	// EmailOff sends no notification emails; in-app notifications continue.
+	EmailOff = "off"
+)
+
+// emailedNotifications are the notification types important enough to email.
+var emailedNotifications = map[string]bool{
+	NotificationBidReceived:      true,
+	NotificationBidAccepted:      true,
+	NotificationPaymentCompleted: true,
+	NotificationReviewReceived:   true,
+	NotificationTaskOverdue:      true,
+	NotificationDeadlineSoon:     true,
+	NotificationBidCountered:     true,
+	NotificationBiddingClosed:    true,
+	NotificationBidShortlisted:   true,
+}
+
+// IsEmailed reports whether notifications of the given type are also sent by email.
+func IsEmailed(notificationType string) bool {
+	return emailedNotifications[notificationType]
+}
+
+// NotificationPreference is a user's choice of how notification emails are
+// delivered. Users without one get EmailInstant.
+type NotificationPreference struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID       primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Email        string             `bson:"email" json:"email"`                                       // instant, daily_digest, off
+	LastDigestAt *time.Time         `bson:"last_digest_at,omitempty" json:"last_digest_at,omitempty"` // last digest sent, or when digests were chosen
+	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// DefaultNotificationPreference is the preference of a user who never chose one.
+func DefaultNotificationPreference(userID primitive.ObjectID) *NotificationPreference {
+	return &NotificationPreference{UserID: userID, Email: EmailInstant}
+}
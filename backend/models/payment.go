@@ -1,21 +1,58 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Payment statuses. Funds sit in escrow while a payment is held and move to
+// the freelancer when it completes or back to the client when refunded.
+const (
+	PaymentStatusPending   = "pending"
+	PaymentStatusHeld      = "held"
+	PaymentStatusCompleted = "completed"
+	PaymentStatusFailed    = "failed"
+	PaymentStatusRefunded  = "refunded"
+)
+
+// ErrIllegalPaymentTransition is returned when a status change would skip or
+// undo a step of the escrow flow.
+var ErrIllegalPaymentTransition = errors.New("illegal payment status transition")
+
+// paymentTransitions lists the statuses each status may move to. Completed,
+// failed and refunded payments are final.
+var paymentTransitions = map[string][]string{
+	PaymentStatusPending: {PaymentStatusHeld, PaymentStatusFailed},
+	PaymentStatusHeld:    {PaymentStatusCompleted, PaymentStatusRefunded},
+}
+
 type Payment struct {
-	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Amount         float64            `bson:"amount" json:"amount"`
-	Status         string             `bson:"status" json:"status"` // pending, completed, failed, refunded
-	PaymentMethod  string             `bson:"payment_method" json:"payment_method"`
-	TransactionID  string             `bson:"transaction_id,omitempty" json:"transaction_id,omitempty"`
-	PaymentGateway string             `bson:"payment_gateway,omitempty" json:"payment_gateway,omitempty"`
-	TaskID         primitive.ObjectID `bson:"task_id" json:"task_id"`
-	ClientID       primitive.ObjectID `bson:"client_id" json:"client_id"`
-	FreelancerID   primitive.ObjectID `bson:"freelancer_id" json:"freelancer_id"`
-	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
+	ID             primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Amount         float64             `bson:"amount" json:"amount"`
+	Status         string              `bson:"status" json:"status"` // pending, held, completed, failed, refunded
+	PaymentMethod  string              `bson:"payment_method" json:"payment_method"`
+	TransactionID  string              `bson:"transaction_id,omitempty" json:"transaction_id,omitempty"`
+	PaymentGateway string              `bson:"payment_gateway,omitempty" json:"payment_gateway,omitempty"`
+	PlatformFee    float64             `bson:"platform_fee,omitempty" json:"platform_fee,omitempty"`
+	PayoutID       string              `bson:"payout_id,omitempty" json:"payout_id,omitempty"`
+	RefundID       string              `bson:"refund_id,omitempty" json:"refund_id,omitempty"`
+	FailureReason  string              `bson:"failure_reason,omitempty" json:"failure_reason,omitempty"`
+	TaskID         primitive.ObjectID  `bson:"task_id" json:"task_id"`
+	MilestoneID    *primitive.ObjectID `bson:"milestone_id,omitempty" json:"milestone_id,omitempty"`
+	ClientID       primitive.ObjectID  `bson:"client_id" json:"client_id"`
+	FreelancerID   primitive.ObjectID  `bson:"freelancer_id" json:"freelancer_id"`
+	CreatedAt      time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// CanTransition reports whether the payment may move to status.
+func (p *Payment) CanTransition(status string) bool {
+	for _, next := range paymentTransitions[p.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
 }
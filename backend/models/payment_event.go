@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Outcomes recorded in PaymentEvent.Status.
+const (
+	// PaymentEventReceived events are stored but not yet applied; a redelivery
+	// processes them again.
+	PaymentEventReceived = "received"
+	// PaymentEventProcessed events changed, or confirmed, a payment's status.
+	PaymentEventProcessed = "processed"
+	// PaymentEventIgnored events have a type the platform does not act on or
+	// refer to no known payment.
+	PaymentEventIgnored = "ignored"
+	// PaymentEventRejected events asked for an illegal status transition.
+	PaymentEventRejected = "rejected"
+)
+
+// PaymentEvent is the audit record of a verified payment provider webhook.
+// Provider and EventID are unique together, so redeliveries are detected.
+type PaymentEvent struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Provider  string              `bson:"provider" json:"provider"`
+	EventID   string              `bson:"event_id" json:"event_id"`
+	Type      string              `bson:"type" json:"type"`
+	PaymentID *primitive.ObjectID `bson:"payment_id,omitempty" json:"payment_id,omitempty"`
+	// Payload is the request body exactly as it was signed.
+	Payload     string     `bson:"payload" json:"payload"`
+	Status      string     `bson:"status" json:"status"` // received, processed, ignored, rejected
+	Error       string     `bson:"error,omitempty" json:"error,omitempty"`
+	ReceivedAt  time.Time  `bson:"received_at" json:"received_at"`
+	ProcessedAt *time.Time `bson:"processed_at,omitempty" json:"processed_at,omitempty"`
+}
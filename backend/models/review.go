@@ -13,6 +13,7 @@ type Review struct {
 	TaskID         primitive.ObjectID `bson:"task_id" json:"task_id"`
 	ReviewerID     primitive.ObjectID `bson:"reviewer_id" json:"reviewer_id"`
 	ReviewedUserID primitive.ObjectID `bson:"reviewed_user_id" json:"reviewed_user_id"`
+	IsHidden       bool               `bson:"is_hidden" json:"is_hidden"` // hidden by a moderator, excluded from ratings
 	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
 }
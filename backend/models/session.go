@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Session is a single signed-in device. Access tokens carry the session ID so
+// they can be revoked server-side; the refresh token is stored only as a hash
+// and rotated on every use.
+type Session struct {
+	ID                  primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID              primitive.ObjectID `bson:"user_id" json:"user_id"`
+	RefreshTokenHash    string             `bson:"refresh_token_hash" json:"-"`
+	PreviousTokenHashes []string           `bson:"previous_token_hashes,omitempty" json:"-"`
+	UserAgent           string             `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
+	IPAddress           string             `bson:"ip_address,omitempty" json:"ip_address,omitempty"`
+	ExpiresAt           time.Time          `bson:"expires_at" json:"expires_at"`
+	RevokedAt           *time.Time         `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	CreatedAt           time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt           time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// IsActive reports whether the session can still be used at the given time.
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,20 @@
+package models
+
+import "strings"
+
+// NormalizeSkills returns skills trimmed, lowercased and without blanks or
+// duplicates, in their original order, so skill lists typed by different
+// users can be compared.
+func NormalizeSkills(skills []string) []string {
+	normalized := make([]string, 0, len(skills))
+	seen := make(map[string]bool, len(skills))
+	for _, skill := range skills {
+		skill = strings.ToLower(strings.TrimSpace(skill))
+		if skill == "" || seen[skill] {
+			continue
+		}
+		seen[skill] = true
+		normalized = append(normalized, skill)
+	}
+	return normalized
+}
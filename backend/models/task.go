@@ -7,17 +7,25 @@ import (
 )
 
 type Task struct {
-	ID             primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
-	Title          string              `bson:"title" json:"title"`
-	Description    string              `bson:"description" json:"description"`
-	Budget         float64             `bson:"budget" json:"budget"`
-	Deadline       time.Time           `bson:"deadline" json:"deadline"`
-	Status         string              `bson:"status" json:"status"` // open, in_progress, completed, cancelled
-	Category       string              `bson:"category" json:"category"`
-	RequiredSkills []string            `bson:"required_skills,omitempty" json:"required_skills,omitempty"`
-	Attachments    []string            `bson:"attachments,omitempty" json:"attachments,omitempty"`
-	ClientID       primitive.ObjectID  `bson:"client_id" json:"client_id"`
-	FreelancerID   *primitive.ObjectID `bson:"freelancer_id,omitempty" json:"freelancer_id,omitempty"`
-	CreatedAt      time.Time           `bson:"created_at" json:"created_at"`
-	UpdatedAt      time.Time           `bson:"updated_at" json:"updated_at"`
+	ID              primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Title           string              `bson:"title" json:"title"`
+	Description     string              `bson:"description" json:"description"`
+	Budget          float64             `bson:"budget" json:"budget"`
+	Deadline        time.Time           `bson:"deadline" json:"deadline"`
+	Status          string              `bson:"status" json:"status"` // open, in_progress, submitted, completed, cancelled, expired
+	Category        string              `bson:"category" json:"category"`
+	RequiredSkills  []string            `bson:"required_skills,omitempty" json:"required_skills,omitempty"`
+	Attachments     []string            `bson:"attachments,omitempty" json:"attachments,omitempty"`
+	ClientID        primitive.ObjectID  `bson:"client_id" json:"client_id"`
+	FreelancerID    *primitive.ObjectID `bson:"freelancer_id,omitempty" json:"freelancer_id,omitempty"`
+	StatusHistory   []TaskStatusChange  `bson:"status_history,omitempty" json:"status_history,omitempty"`
+	CompletedAt     *time.Time          `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+	OverdueAt       *time.Time          `bson:"overdue_at,omitempty" json:"overdue_at,omitempty"`     // deadline passed while in progress
+	Reminders       []time.Duration     `bson:"reminders,omitempty" json:"-"`                         // reminder offsets already sent for Deadline
+	BiddingMode     string              `bson:"bidding_mode,omitempty" json:"bidding_mode,omitempty"` // open, sealed, reverse_auction; empty is open
+	BiddingClosesAt *time.Time          `bson:"bidding_closes_at,omitempty" json:"bidding_closes_at,omitempty"`
+	BiddingClosedAt *time.Time          `bson:"bidding_closed_at,omitempty" json:"bidding_closed_at,omitempty"` // auction closed and its bids picked
+	Progress        *TaskProgress       `bson:"-" json:"progress,omitempty"`                                    // computed from milestones
+	CreatedAt       time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time           `bson:"updated_at" json:"updated_at"`
 }
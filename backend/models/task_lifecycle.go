@@ -0,0 +1,123 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Task statuses.
+const (
+	TaskStatusOpen       = "open"
+	TaskStatusInProgress = "in_progress"
+	TaskStatusSubmitted  = "submitted"
+	TaskStatusCompleted  = "completed"
+	TaskStatusCancelled  = "cancelled"
+	TaskStatusExpired    = "expired"
+)
+
+// Task lifecycle events. Each event is a named transition in taskTransitions.
+const (
+	TaskEventCreate          = "create"
+	TaskEventAcceptBid       = "accept_bid"
+	TaskEventSubmitWork      = "submit_work"
+	TaskEventRequestRevision = "request_revision"
+	TaskEventApprove         = "approve"
+	TaskEventCancel          = "cancel"
+	TaskEventForceCancel     = "force_cancel"
+	TaskEventExpire          = "expire"
+)
+
+// Task history events that do not change the task's status, see Record.
+const (
+	TaskEventMarkOverdue      = "mark_overdue"
+	TaskEventDeadlineReminder = "deadline_reminder"
+	TaskEventRejectBids       = "reject_bids"
+	TaskEventCloseBidding     = "close_bidding"
+)
+
+// ErrIllegalTransition is returned when an event is not allowed from the
+// task's current status.
+var ErrIllegalTransition = errors.New("illegal task status transition")
+
+type taskTransition struct {
+	from []string
+	to   string
+}
+
+// taskTransitions is the task state machine. Who may fire each event is
+// decided by the policy package and the handlers; this table only decides
+// which statuses an event is valid from.
+var taskTransitions = map[string]taskTransition{
+	TaskEventAcceptBid:       {from: []string{TaskStatusOpen}, to: TaskStatusInProgress},
+	TaskEventSubmitWork:      {from: []string{TaskStatusInProgress}, to: TaskStatusSubmitted},
+	TaskEventRequestRevision: {from: []string{TaskStatusSubmitted}, to: TaskStatusInProgress},
+	TaskEventApprove:         {from: []string{TaskStatusSubmitted}, to: TaskStatusCompleted},
+	TaskEventCancel:          {from: []string{TaskStatusOpen, TaskStatusInProgress}, to: TaskStatusCancelled},
+	TaskEventForceCancel:     {from: []string{TaskStatusOpen, TaskStatusInProgress, TaskStatusSubmitted}, to: TaskStatusCancelled},
+	TaskEventExpire:          {from: []string{TaskStatusOpen}, to: TaskStatusExpired},
+}
+
+// TaskStatusChange is one entry of a task's status history.
+type TaskStatusChange struct {
+	From    string              `bson:"from,omitempty" json:"from,omitempty"`
+	To      string              `bson:"to" json:"to"`
+	Event   string              `bson:"event" json:"event"`
+	ActorID *primitive.ObjectID `bson:"actor_id,omitempty" json:"actor_id,omitempty"`
+	Note    string              `bson:"note,omitempty" json:"note,omitempty"`
+	At      time.Time           `bson:"at" json:"at"`
+}
+
+// CanTransition reports whether event is allowed from the task's current status.
+func (t *Task) CanTransition(event string) bool {
+	transition, ok := taskTransitions[event]
+	if !ok {
+		return false
+	}
+	for _, from := range transition.from {
+		if t.Status == from {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition applies event to the task, appending a timestamped entry to its
+// status history. actorID is nil for transitions made by the system.
+func (t *Task) Transition(event string, actorID *primitive.ObjectID, note string, at time.Time) error {
+	if !t.CanTransition(event) {
+		return ErrIllegalTransition
+	}
+
+	to := taskTransitions[event].to
+	t.StatusHistory = append(t.StatusHistory, TaskStatusChange{
+		From:    t.Status,
+		To:      to,
+		Event:   event,
+		ActorID: actorID,
+		Note:    note,
+		At:      at,
+	})
+	t.Status = to
+	t.UpdatedAt = at
+
+	if to == TaskStatusCompleted {
+		t.CompletedAt = &at
+	}
+	return nil
+}
+
+// Record appends a history entry for an event that leaves the task's status
+// unchanged, such as a deadline reminder.
+func (t *Task) Record(event string, actorID *primitive.ObjectID, note string, at time.Time) {
+	t.StatusHistory = append(t.StatusHistory, TaskStatusChange{
+		From:    t.Status,
+		To:      t.Status,
+		Event:   event,
+		ActorID: actorID,
+		Note:    note,
+		At:      at,
+	})
+	t.UpdatedAt = at
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var taskStatuses = []string{
+	TaskStatusOpen,
+	TaskStatusInProgress,
+	TaskStatusSubmitted,
+	TaskStatusCompleted,
+	TaskStatusCancelled,
+	TaskStatusExpired,
+}
+
+var taskEvents = []string{
+	TaskEventCreate,
+	TaskEventAcceptBid,
+	TaskEventSubmitWork,
+	TaskEventRequestRevision,
+	TaskEventApprove,
+	TaskEventCancel,
+	TaskEventForceCancel,
+	TaskEventExpire,
+}
+
+func TestTaskTransitions(t *testing.T) {
+	// allowed lists the status each event leads to from each status it is
+	// valid from. Every other status and event pair must be rejected.
+	allowed := map[string]map[string]string{
+		TaskStatusOpen: {
+			TaskEventAcceptBid:   TaskStatusInProgress,
+			TaskEventCancel:      TaskStatusCancelled,
+			TaskEventForceCancel: TaskStatusCancelled,
+			TaskEventExpire:      TaskStatusExpired,
+		},
+		TaskStatusInProgress: {
+			TaskEventSubmitWork:  TaskStatusSubmitted,
+			TaskEventCancel:      TaskStatusCancelled,
+			TaskEventForceCancel: TaskStatusCancelled,
+		},
+		TaskStatusSubmitted: {
+			TaskEventRequestRevision: TaskStatusInProgress,
+			TaskEventApprove:         TaskStatusCompleted,
+			TaskEventForceCancel:     TaskStatusCancelled,
+		},
+	}
+
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	actor := primitive.NewObjectID()
+	for _, from := range taskStatuses {
+		for _, event := range taskEvents {
+			to, ok := allowed[from][event]
+			t.Run(from+"/"+event, func(t *testing.T) {
+				task := Task{Status: from}
+				if got := task.CanTransition(event); got != ok {
+					t.Fatalf("CanTransition = %v, want %v", got, ok)
+				}
+
+				err := task.Transition(event, &actor, "note", at)
+				if !ok {
+					if !errors.Is(err, ErrIllegalTransition) {
+						t.Fatalf("Transition = %v, want ErrIllegalTransition", err)
+					}
+					if task.Status != from || len(task.StatusHistory) != 0 {
+						t.Errorf("rejected transition changed the task to %q with %d history entries", task.Status, len(task.StatusHistory))
+					}
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("Transition = %v", err)
+				}
+				if task.Status != to {
+					t.Errorf("status = %q, want %q", task.Status, to)
+				}
+				if len(task.StatusHistory) != 1 {
+					t.Fatalf("history has %d entries, want 1", len(task.StatusHistory))
+				}
+				entry := task.StatusHistory[0]
+				if entry.From != from || entry.To != to || entry.Event != event || entry.ActorID == nil || *entry.ActorID != actor || !entry.At.Equal(at) {
+					t.Errorf("history entry = %+v", entry)
+				}
+				if !task.UpdatedAt.Equal(at) {
+					t.Errorf("UpdatedAt = %v, want %v", task.UpdatedAt, at)
+				}
+				if completed := task.CompletedAt != nil; completed != (to == TaskStatusCompleted) {
+					t.Errorf("CompletedAt = %v after moving to %q", task.CompletedAt, to)
+				}
+			})
+		}
+	}
+}
+
+func TestTaskTransitionUnknownEvent(t *testing.T) {
+	task := Task{Status: TaskStatusOpen}
+	if task.CanTransition("publish") {
+		t.Error("CanTransition accepted an unknown event")
+	}
+	if err := task.Transition("publish", nil, "", time.Now()); !errors.Is(err, ErrIllegalTransition) {
+		t.Errorf("Transition = %v, want ErrIllegalTransition", err)
+	}
+}
+
+func TestTaskRecordKeepsStatus(t *testing.T) {
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, status := range taskStatuses {
+		t.Run(status, func(t *testing.T) {
+			task := Task{Status: status}
+			task.Record(TaskEventDeadlineReminder, nil, "24h", at)
+			if task.Status != status {
+				t.Errorf("status = %q, want %q", task.Status, status)
+			}
+			if len(task.StatusHistory) != 1 || task.StatusHistory[0].From != status || task.StatusHistory[0].To != status {
+				t.Errorf("history = %+v", task.StatusHistory)
+			}
+		})
+	}
+}
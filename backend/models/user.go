@@ -6,6 +6,13 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// User roles, stored in User.UserType.
+const (
+	RoleClient     = "client"
+	RoleFreelancer = "freelancer"
+	RoleAdmin      = "admin"
+)
+
 type User struct {
 	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	Email        string             `bson:"email" json:"email"`
@@ -18,22 +25,29 @@ type User struct {
 	Skills       []string           `bson:"skills,omitempty" json:"skills,omitempty"`
 	Rating       float64            `bson:"rating" json:"rating"`
 	IsVerified   bool               `bson:"is_verified" json:"is_verified"`
+	SuspendedAt  *time.Time         `bson:"suspended_at,omitempty" json:"suspended_at,omitempty"`
 	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at"`
 }
 
 type UserResponse struct {
-	ID           string    `json:"id"`
-	Email        string    `json:"email"`
-	FirstName    string    `json:"first_name"`
-	LastName     string    `json:"last_name"`
-	UserType     string    `json:"user_type"`
-	ProfileImage string    `json:"profile_image,omitempty"`
-	Bio          string    `json:"bio,omitempty"`
-	Skills       []string  `json:"skills,omitempty"`
-	Rating       float64   `json:"rating"`
-	IsVerified   bool      `json:"is_verified"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID           string     `json:"id"`
+	Email        string     `json:"email"`
+	FirstName    string     `json:"first_name"`
+	LastName     string     `json:"last_name"`
+	UserType     string     `json:"user_type"`
+	ProfileImage string     `json:"profile_image,omitempty"`
+	Bio          string     `json:"bio,omitempty"`
+	Skills       []string   `json:"skills,omitempty"`
+	Rating       float64    `json:"rating"`
+	IsVerified   bool       `json:"is_verified"`
+	SuspendedAt  *time.Time `json:"suspended_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// IsSuspended reports whether an admin has suspended the account.
+func (u *User) IsSuspended() bool {
+	return u.SuspendedAt != nil
 }
 
 func (u *User) ToResponse() UserResponse {
@@ -48,6 +62,7 @@ func (u *User) ToResponse() UserResponse {
 		Skills:       u.Skills,
 		Rating:       u.Rating,
 		IsVerified:   u.IsVerified,
+		SuspendedAt:  u.SuspendedAt,
 		CreatedAt:    u.CreatedAt,
 	}
 }
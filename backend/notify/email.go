@@ -0,0 +1,207 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/jobs"
+	"github.com/Vivekpdy/tasklanceweb/backend/mailer"
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Job types run by the notification service.
+const (
+	// JobNotificationEmail emails a single notification.
+	JobNotificationEmail = "notification_email"
+	// JobDigestScan looks for users whose daily digest is due.
+	JobDigestScan = "notification_digest_scan"
+	// JobDigestEmail sends one user's daily digest.
+	JobDigestEmail = "notification_digest_email"
+)
+
+// digestInterval is how often the digest scan runs.
+const digestInterval = 10 * time.Minute
+
+// EmailConfig controls notification emails.
+type EmailConfig struct {
+	FrontendURL string // base URL for links in emails
+	DigestHour  int    // UTC hour at which daily digests go out
+}
+
+// EmailConfigFromEnv reads FRONTEND_URL and DIGEST_HOUR, defaulting to the
+// local web app and 08:00 UTC.
+func EmailConfigFromEnv() EmailConfig {
+	config := EmailConfig{FrontendURL: "http://localhost:5173", DigestHour: 8}
+	if url := os.Getenv("FRONTEND_URL"); url != "" {
+		config.FrontendURL = strings.TrimRight(url, "/")
+	}
+	if value := os.Getenv("DIGEST_HOUR"); value != "" {
+		if hour, err := strconv.Atoi(value); err == nil && hour >= 0 && hour < 24 {
+			config.DigestHour = hour
+		}
+	}
+	return config
+}
+
+type emailJob struct {
+	NotificationID primitive.ObjectID `bson:"notification_id"`
+}
+
+type digestJob struct {
+	UserID primitive.ObjectID `bson:"user_id"`
+}
+
+func (s *Service) registerJobs() {
+	s.queue.Register(JobNotificationEmail, s.runEmailJob)
+	s.queue.Register(JobDigestScan, func(ctx context.Context, job *models.Job) error {
+		return s.SendDigests(ctx, time.Now())
+	})
+	s.queue.Register(JobDigestEmail, s.runDigestJob)
+	s.queue.Every("notification-digests", digestInterval, JobDigestScan, nil)
+}
+
+// emailMode returns the user's email preference, defaulting to instant.
+func (s *Service) emailMode(ctx context.Context, userID primitive.ObjectID) string {
+	preference, err := s.preferences.FindByUser(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.DefaultNotificationPreference(userID).Email
+	}
+	if err != nil {
+		log.Printf("Failed to load notification preference for user %s: %v", userID.Hex(), err)
+		return models.EmailOff
+	}
+	return preference.Email
+}
+
+func (s *Service) runEmailJob(ctx context.Context, job *models.Job) error {
+	var payload emailJob
+	if err := jobs.Decode(job, &payload); err != nil {
+		return jobs.Permanent(err)
+	}
+
+	notification, err := s.notifications.FindByID(ctx, payload.NotificationID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return jobs.Permanent(err)
+	}
+	if err != nil {
+		return err
+	}
+
+	user, err := s.recipient(ctx, notification.UserID)
+	if err != nil || user == nil {
+		return err
+	}
+
+	msg, err := mailer.NotificationEmail(user.Email, s.emailData(user, []models.Notification{*notification}))
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+	return s.mailer.Send(ctx, msg)
+}
+
+// SendDigests queues a digest email for every digest user who has not had
+// one since the most recent digest hour. The job's unique key stops
+// overlapping scans from queueing the same digest twice, and the user is
+// only marked as sent once the job is queued, so a failed enqueue is tried
+// again on the next scan.
+func (s *Service) SendDigests(ctx context.Context, now time.Time) error {
+	cutoff := s.digestCutoff(now)
+	preferences, err := s.preferences.FindDigestDue(ctx, cutoff)
+	if err != nil {
+		return fmt.Errorf("find due digests: %w", err)
+	}
+
+	for _, preference := range preferences {
+		key := fmt.Sprintf("digest:%s:%d", preference.UserID.Hex(), cutoff.Unix())
+		_, err := s.queue.Enqueue(ctx, JobDigestEmail, digestJob{UserID: preference.UserID}, jobs.UniqueKey(key))
+		if err != nil && !errors.Is(err, repository.ErrDuplicate) {
+			log.Printf("Failed to queue digest for user %s: %v", preference.UserID.Hex(), err)
+			continue
+		}
+		err = s.preferences.ClaimDigest(ctx, preference.UserID, cutoff, now)
+		if err != nil && !errors.Is(err, repository.ErrConflict) {
+			log.Printf("Failed to record digest for user %s: %v", preference.UserID.Hex(), err)
+		}
+	}
+	return nil
+}
+
+// runDigestJob emails the user everything waiting for a digest. The
+// notifications stay pending until the email is sent, so a failed attempt
+// is retried with the same content.
+func (s *Service) runDigestJob(ctx context.Context, job *models.Job) error {
+	var payload digestJob
+	if err := jobs.Decode(job, &payload); err != nil {
+		return jobs.Permanent(err)
+	}
+
+	pending, err := s.notifications.FindDigestPending(ctx, payload.UserID)
+	if err != nil || len(pending) == 0 {
+		return err
+	}
+
+	user, err := s.recipient(ctx, payload.UserID)
+	if err != nil {
+		return err
+	}
+	if user != nil {
+		msg, err := mailer.DigestEmail(user.Email, s.emailData(user, pending))
+		if err != nil {
+			return jobs.Permanent(err)
+		}
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return s.notifications.ClearDigestPending(ctx, payload.UserID, pending[len(pending)-1].CreatedAt)
+}
+
+// digestCutoff is the most recent digest hour at or before now.
+func (s *Service) digestCutoff(now time.Time) time.Time {
+	now = now.UTC()
+	cutoff := time.Date(now.Year(), now.Month(), now.Day(), s.config.DigestHour, 0, 0, 0, time.UTC)
+	if cutoff.After(now) {
+		cutoff = cutoff.AddDate(0, 0, -1)
+	}
+	return cutoff
+}
+
+// recipient loads the user to email. It returns nil without an error for
+// accounts that are gone, have not verified their address or have been
+// suspended, since those are never emailed.
+func (s *Service) recipient(ctx context.Context, userID primitive.ObjectID) (*models.User, error) {
+	user, err := s.users.FindByID(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsVerified || user.IsSuspended() {
+		return nil, nil
+	}
+	return user, nil
+}
+
+func (s *Service) emailData(user *models.User, notifications []models.Notification) mailer.NotificationData {
+	data := mailer.NotificationData{
+		FirstName:       user.FirstName,
+		PreferencesLink: s.config.FrontendURL + "/profile",
+	}
+	for _, notification := range notifications {
+		item := mailer.NotificationItem{Title: notification.Title, Body: notification.Body, At: notification.CreatedAt}
+		if notification.TaskID != nil {
+			item.Link = s.config.FrontendURL + "/tasks/" + notification.TaskID.Hex()
+		}
+		data.Items = append(data.Items, item)
+	}
+	return data
+}
@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/jobs"
+	"github.com/Vivekpdy/tasklanceweb/backend/mailer"
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// failingJobs is a job store whose first inserts fail.
+type failingJobs struct {
+	repository.JobRepository
+	failures int
+}
+
+func (j *failingJobs) Create(ctx context.Context, job *models.Job) error {
+	if j.failures > 0 {
+		j.failures--
+		return errors.New("job store unavailable")
+	}
+	return j.JobRepository.Create(ctx, job)
+}
+
+func TestSendDigestsRetriesFailedEnqueue(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewMemoryRepositories()
+	jobStore := &failingJobs{JobRepository: repos.Jobs, failures: 1}
+	queue := jobs.NewQueue(jobStore, jobs.Config{Visibility: time.Minute, MaxAttempts: 5})
+	service := NewService(repos.Notifications, repos.NotificationPreferences, repos.Users, mailer.NewMemoryMailer(), queue, EmailConfig{DigestHour: 8})
+
+	userID := primitive.NewObjectID()
+	chosen := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	if err := repos.NotificationPreferences.Save(ctx, &models.NotificationPreference{UserID: userID, Email: models.EmailDailyDigest, LastDigestAt: &chosen}); err != nil {
+		t.Fatal(err)
+	}
+	digestJobs := func() int {
+		page, err := repos.Jobs.Find(ctx, repository.JobFilter{Type: JobDigestEmail}, pagination.Params{Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(page.Items)
+	}
+
+	now := time.Date(2024, 6, 2, 8, 5, 0, 0, time.UTC)
+	if err := service.SendDigests(ctx, now); err != nil {
+		t.Fatal(err)
+	}
+	if count := digestJobs(); count != 0 {
+		t.Fatalf("%d digest jobs after the failed enqueue, want 0", count)
+	}
+	preference, err := repos.NotificationPreferences.FindByUser(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !preference.LastDigestAt.Equal(chosen) {
+		t.Fatalf("digest was recorded at %v although it was never queued", preference.LastDigestAt)
+	}
+
+	// The next scan queues it, and later scans the same day do not.
+	for _, at := range []time.Time{now.Add(10 * time.Minute), now.Add(20 * time.Minute)} {
+		if err := service.SendDigests(ctx, at); err != nil {
+			t.Fatal(err)
+		}
+		if count := digestJobs(); count != 1 {
+			t.Fatalf("%d digest jobs after the scan at %v, want 1", count, at)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package notify
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BidReceived tells a task owner that a freelancer bid on their task.
+func BidReceived(task *models.Task, bid *models.Bid) models.Notification {
+	return models.Notification{
+		UserID:     task.ClientID,
+		Type:       models.NotificationBidReceived,
+		Title:      "New bid on " + task.Title,
+		Body:       fmt.Sprintf("A freelancer bid $%.2f on your task %q.", bid.Amount, task.Title),
+		TaskID:     &task.ID,
+		ResourceID: &bid.ID,
+	}
+}
+
+// BidAccepted tells a freelancer that their bid won the task.
+func BidAccepted(task *models.Task, bid *models.Bid) models.Notification {
+	return models.Notification{
+		UserID:     bid.FreelancerID,
+		Type:       models.NotificationBidAccepted,
+		Title:      "Your bid was accepted",
+		Body:       fmt.Sprintf("Your $%.2f bid on %q was accepted. You can start work now.", bid.Amount, task.Title),
+		TaskID:     &task.ID,
+		ResourceID: &bid.ID,
+	}
+}
+
+// PaymentStatusChanged tells the party a payment's new status matters to:
+// the freelancer when funds are held or paid out, the client when a charge
+// fails or is refunded. ok is false for statuses nobody is told about.
+func PaymentStatusChanged(payment *models.Payment) (notification models.Notification, ok bool) {
+	notification = models.Notification{
+		TaskID:     &payment.TaskID,
+		ResourceID: &payment.ID,
+	}
+
+	switch payment.Status {
+	case models.PaymentStatusHeld:
+		notification.UserID = payment.FreelancerID
+		notification.Type = models.NotificationPaymentHeld
+		notification.Title = "Payment secured in escrow"
+		notification.Body = fmt.Sprintf("$%.2f for your work is now held in escrow and will be released when the client approves it.", payment.Amount)
+	case models.PaymentStatusCompleted:
+		notification.UserID = payment.FreelancerID
+		notification.Type = models.NotificationPaymentCompleted
+		notification.Title = "Payment released"
+		notification.Body = fmt.Sprintf("$%.2f has been paid out to you after a $%.2f platform fee.", payment.Amount-payment.PlatformFee, payment.PlatformFee)
+	case models.PaymentStatusFailed:
+		notification.UserID = payment.ClientID
+		notification.Type = models.NotificationPaymentFailed
+		notification.Title = "Payment failed"
+		notification.Body = fmt.Sprintf("Your $%.2f payment could not be completed: %s", payment.Amount, payment.FailureReason)
+	case models.PaymentStatusRefunded:
+		notification.UserID = payment.ClientID
+		notification.Type = models.NotificationPaymentRefunded
+		notification.Title = "Payment refunded"
+		notification.Body = fmt.Sprintf("$%.2f held in escrow has been refunded to you.", payment.Amount)
+	default:
+		return models.Notification{}, false
+	}
+	return notification, true
+}
+
+// ReviewReceived tells a user someone reviewed their work on a task.
+func ReviewReceived(task *models.Task, review *models.Review) models.Notification {
+	return models.Notification{
+		UserID:     review.ReviewedUserID,
+		Type:       models.NotificationReviewReceived,
+		Title:      "New review",
+		Body:       fmt.Sprintf("You received a %d-star review for %q.", review.Rating, task.Title),
+		TaskID:     &task.ID,
+		ResourceID: &review.ID,
+	}
+}
+
+// BidRejected tells a freelancer that their bid lost, with the reason.
+func BidRejected(task *models.Task, bid *models.Bid, reason string) models.Notification {
+	return models.Notification{
+		UserID:     bid.FreelancerID,
+		Type:       models.NotificationBidRejected,
+		Title:      "Your bid was not accepted",
+		Body:       fmt.Sprintf("Your $%.2f bid on %q was rejected: %s", bid.Amount, task.Title, reason),
+		TaskID:     &task.ID,
+		ResourceID: &bid.ID,
+	}
+}
+
+// TaskExpired tells a client that their task closed without an accepted bid.
+func TaskExpired(task *models.Task) models.Notification {
+	return models.Notification{
+		UserID: task.ClientID,
+		Type:   models.NotificationTaskExpired,
+		Title:  "Task expired",
+		Body:   fmt.Sprintf("The deadline of %q passed before you accepted a bid, so it is closed to new bids.", task.Title),
+		TaskID: &task.ID,
+	}
+}
+
+// TaskOverdue tells userID, the client or the assigned freelancer, that the
+// task's deadline passed before the work was submitted.
+func TaskOverdue(task *models.Task, userID primitive.ObjectID) models.Notification {
+	return models.Notification{
+		UserID: userID,
+		Type:   models.NotificationTaskOverdue,
+		Title:  "Task overdue",
+		Body:   fmt.Sprintf("The deadline of %q passed before the work was submitted.", task.Title),
+		TaskID: &task.ID,
+	}
+}
+
+// DeadlineSoon reminds userID that the task's deadline is remaining away:
+// the client while the task is still open, the freelancer once assigned.
+func DeadlineSoon(task *models.Task, userID primitive.ObjectID, remaining time.Duration) models.Notification {
+	body := fmt.Sprintf("%q is due in %s.", task.Title, humanDuration(remaining))
+	if task.Status == models.TaskStatusOpen {
+		body += " It expires then unless you accept a bid."
+	}
+	return models.Notification{
+		UserID: userID,
+		Type:   models.NotificationDeadlineSoon,
+		Title:  "Deadline approaching",
+		Body:   body,
+		TaskID: &task.ID,
+	}
+}
+
+// humanDuration rounds d to whole days, hours or minutes for display.
+func humanDuration(d time.Duration) string {
+	unit, name := time.Minute, "minute"
+	switch {
+	case d >= 48*time.Hour:
+		unit, name = 24*time.Hour, "day"
+	case d >= 2*time.Hour:
+		unit, name = time.Hour, "hour"
+	}
+
+	n := int64((d + unit/2) / unit)
+	if n <= 1 {
+		return "1 " + name
+	}
+	return fmt.Sprintf("%d %ss", n, name)
+}
+
+// BidWithdrawn tells a task owner that a freelancer withdrew their bid.
+func BidWithdrawn(task *models.Task, bid *models.Bid) models.Notification {
+	return models.Notification{
+		UserID:     task.ClientID,
+		Type:       models.NotificationBidWithdrawn,
+		Title:      "Bid withdrawn",
+		Body:       fmt.Sprintf("A freelancer withdrew their $%.2f bid on %q.", bid.Amount, task.Title),
+		TaskID:     &task.ID,
+		ResourceID: &bid.ID,
+	}
+}
+
+// BidCountered tells a freelancer that the task owner made a counter-offer
+// on their bid.
+func BidCountered(task *models.Task, bid *models.Bid, counter *models.BidRevision) models.Notification {
+	return models.Notification{
+		UserID:     bid.FreelancerID,
+		Type:       models.NotificationBidCountered,
+		Title:      "Counter-offer on " + task.Title,
+		Body:       fmt.Sprintf("The client offered $%.2f by %s instead of your $%.2f bid on %q. Accept or decline it to continue.", counter.Amount, counter.ProposedDeadline.Format("Jan 2, 2006"), bid.Amount, task.Title),
+		TaskID:     &task.ID,
+		ResourceID: &bid.ID,
+	}
+}
+
+// CounterAnswered tells a task owner whether the freelancer accepted their
+// counter-offer.
+func CounterAnswered(task *models.Task, bid *models.Bid, accepted bool) models.Notification {
+	notification := models.Notification{
+		UserID:     task.ClientID,
+		Type:       models.NotificationCounterDeclined,
+		Title:      "Counter-offer declined",
+		Body:       fmt.Sprintf("The freelancer declined your counter-offer on %q; their $%.2f bid stands.", task.Title, bid.Amount),
+		TaskID:     &task.ID,
+		ResourceID: &bid.ID,
+	}
+	if accepted {
+		notification.Type = models.NotificationCounterAccepted
+		notification.Title = "Counter-offer accepted"
+		notification.Body = fmt.Sprintf("The freelancer accepted your $%.2f counter-offer on %q. Accept the bid to assign the task.", bid.Amount, task.Title)
+	}
+	return notification
+}
+
+// BiddingClosed tells a task owner that bidding on their auction closed and
+// which bids were picked: the winner of a reverse auction, or the shortlist
+// of a sealed one.
+func BiddingClosed(task *models.Task, shortlisted []models.Bid, total int) models.Notification {
+	notification := models.Notification{
+		UserID: task.ClientID,
+		Type:   models.NotificationBiddingClosed,
+		Title:  "Bidding closed on " + task.Title,
+		TaskID: &task.ID,
+	}
+	switch {
+	case len(shortlisted) == 0:
+		notification.Body = fmt.Sprintf("Bidding on %q closed without any bids.", task.Title)
+	case task.BiddingMode == models.BiddingReverseAuction:
+		notification.Body = fmt.Sprintf("The auction for %q closed and the lowest of %d bid(s), $%.2f, won. Accept it to assign the task.", task.Title, total, shortlisted[0].Amount)
+		notification.ResourceID = &shortlisted[0].ID
+	default:
+		notification.Body = fmt.Sprintf("Sealed bidding on %q closed. The %d lowest of %d bid(s) were shortlisted for you to choose from.", task.Title, len(shortlisted), total)
+	}
+	return notification
+}
+
+// BidShortlisted tells a freelancer that their bid won a reverse auction or
+// made the shortlist of a sealed one.
+func BidShortlisted(task *models.Task, bid *models.Bid) models.Notification {
+	body := fmt.Sprintf("Your $%.2f bid on %q was shortlisted. The client will now choose between the shortlisted bids.", bid.Amount, task.Title)
+	if task.BiddingMode == models.BiddingReverseAuction {
+		body = fmt.Sprintf("Your $%.2f bid won the auction for %q. The client will now confirm it.", bid.Amount, task.Title)
+	}
+	return models.Notification{
+		UserID:     bid.FreelancerID,
+		Type:       models.NotificationBidShortlisted,
+		Title:      "Your bid was shortlisted",
+		Body:       body,
+		TaskID:     &task.ID,
+		ResourceID: &bid.ID,
+	}
+}
@@ -0,0 +1,116 @@
+// Package notify records in-app notifications, pushes them to the
+// recipient's open streams and emails them according to the recipient's
+// preference. Notifying never fails the action that triggered it: storage
+// and delivery errors are logged and the action carries on.
+package notify
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/jobs"
+	"github.com/Vivekpdy/tasklanceweb/backend/mailer"
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// streamBuffer is how many notifications may queue for a subscriber that is
+// not keeping up before further ones are dropped from its stream. Dropped
+// notifications are still stored and show up in the list.
+const streamBuffer = 16
+
+type Service struct {
+	notifications repository.NotificationRepository
+	preferences   repository.NotificationPreferenceRepository
+	users         repository.UserRepository
+	mailer        mailer.Mailer
+	queue         *jobs.Queue
+	config        EmailConfig
+
+	mu          sync.RWMutex
+	subscribers map[primitive.ObjectID]map[chan models.Notification]struct{}
+}
+
+// NewService builds the service and registers its email jobs with queue.
+func NewService(notifications repository.NotificationRepository, preferences repository.NotificationPreferenceRepository, users repository.UserRepository, mailer mailer.Mailer, queue *jobs.Queue, config EmailConfig) *Service {
+	s := &Service{
+		notifications: notifications,
+		preferences:   preferences,
+		users:         users,
+		mailer:        mailer,
+		queue:         queue,
+		config:        config,
+		subscribers:   make(map[primitive.ObjectID]map[chan models.Notification]struct{}),
+	}
+	s.registerJobs()
+	return s
+}
+
+// Notify stores notification for its UserID, publishes it to the user's
+// streams and, for emailed types, either queues an email job right away or
+// marks it for the user's next digest.
+func (s *Service) Notify(ctx context.Context, notification models.Notification) {
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+
+	mode := models.EmailOff
+	if models.IsEmailed(notification.Type) {
+		mode = s.emailMode(ctx, notification.UserID)
+	}
+	notification.DigestPending = mode == models.EmailDailyDigest
+
+	if err := s.notifications.Create(ctx, &notification); err != nil {
+		log.Printf("Failed to store %s notification for user %s: %v", notification.Type, notification.UserID.Hex(), err)
+		return
+	}
+	s.publish(notification)
+
+	if mode == models.EmailInstant {
+		payload := emailJob{NotificationID: notification.ID}
+		if _, err := s.queue.Enqueue(ctx, JobNotificationEmail, payload); err != nil {
+			log.Printf("Failed to queue email for notification %s: %v", notification.ID.Hex(), err)
+		}
+	}
+}
+
+// Subscribe returns a channel receiving the user's new notifications and a
+// function that ends the subscription and closes the channel.
+func (s *Service) Subscribe(userID primitive.ObjectID) (<-chan models.Notification, func()) {
+	ch := make(chan models.Notification, streamBuffer)
+
+	s.mu.Lock()
+	if s.subscribers[userID] == nil {
+		s.subscribers[userID] = make(map[chan models.Notification]struct{})
+	}
+	s.subscribers[userID][ch] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			delete(s.subscribers[userID], ch)
+			if len(s.subscribers[userID]) == 0 {
+				delete(s.subscribers, userID)
+			}
+			close(ch)
+		})
+	}
+}
+
+func (s *Service) publish(notification models.Notification) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for ch := range s.subscribers[notification.UserID] {
+		select {
+		case ch <- notification:
+		default:
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Package pagination implements keyset pagination over (created_at, _id).
+//
+// Cursors are opaque to clients: they encode the sort key of the last item on
+// a page, so the next page starts strictly after it even when new documents
+// are inserted in between.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the position of one item in a (created_at, _id) ordering.
+// Orderings led by another field also record that field's value: Number for
+// an amount or a relevance score, Time for a date such as a deadline.
+type Cursor struct {
+	CreatedAt time.Time          `json:"t"`
+	ID        primitive.ObjectID `json:"id"`
+	Number    *float64           `json:"n,omitempty"`
+	Time      *time.Time         `json:"at,omitempty"`
+}
+
+// Encode returns the opaque string handed to clients as next_cursor.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode parses a cursor produced by Encode.
+func Decode(value string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// Before reports whether c sorts before other in ascending order.
+func (c Cursor) Before(other Cursor) bool {
+	if !c.CreatedAt.Equal(other.CreatedAt) {
+		return c.CreatedAt.Before(other.CreatedAt)
+	}
+	return c.ID.Hex() < other.ID.Hex()
+}
+
+// Params is a page request: at most Limit items after the After cursor, or
+// from the start when After is nil.
+type Params struct {
+	Limit int
+	After *Cursor
+}
+
+// Parse builds Params from the raw limit and cursor query values. An empty
+// limit means DefaultLimit and anything above MaxLimit is clamped.
+func Parse(limit, cursor string) (Params, error) {
+	params := Params{Limit: DefaultLimit}
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return Params{}, errors.New("limit must be a positive integer")
+		}
+		params.Limit = n
+	}
+	if params.Limit > MaxLimit {
+		params.Limit = MaxLimit
+	}
+	if cursor != "" {
+		after, err := Decode(cursor)
+		if err != nil {
+			return Params{}, err
+		}
+		params.After = after
+	}
+	return params, nil
+}
+
+// Page is the response envelope shared by every list endpoint.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// NewPage builds a page from up to Limit+1 items read after params.After.
+// The extra item only signals that another page exists and is dropped.
+func NewPage[T any](items []T, params Params, cursorOf func(T) Cursor) *Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	page := &Page[T]{Items: items}
+	if len(items) > params.Limit {
+		page.Items = items[:params.Limit]
+		page.HasMore = true
+		page.NextCursor = cursorOf(page.Items[len(page.Items)-1]).Encode()
+	}
+	return page
+}
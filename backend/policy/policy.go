@@ -0,0 +1,78 @@
+// Package policy is the single place that decides which role may perform
+// which action. Routes declare the permission they need and the middleware
+// consults this table, so the rules can be audited in one file.
+package policy
+
+import (
+	"sort"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+)
+
+type Permission string
+
+const (
+	CreateTask   Permission = "tasks:create"
+	UpdateTask   Permission = "tasks:update"
+	DeleteTask   Permission = "tasks:delete"
+	CancelTask   Permission = "tasks:cancel"
+	SubmitWork   Permission = "tasks:submit"
+	ReviewWork   Permission = "tasks:review_work"
+	CreateBid    Permission = "bids:create"
+	UpdateBid    Permission = "bids:update"
+	AcceptBid    Permission = "bids:accept"
+	WithdrawBid  Permission = "bids:withdraw"
+	CounterBid   Permission = "bids:counter"
+	AnswerBid    Permission = "bids:answer_counter"
+	TriageBids   Permission = "bids:triage"
+	CreateReview Permission = "reviews:create"
+
+	RecommendTasks     Permission = "tasks:recommended"
+	SuggestFreelancers Permission = "tasks:suggested_freelancers"
+
+	ManageMilestones Permission = "milestones:manage"
+
+	CreatePayment Permission = "payments:create"
+	UpdatePayment Permission = "payments:update"
+
+	AdminAccess Permission = "admin:access"
+)
+
+// rolePermissions grants permissions to roles. Ownership of the individual
+// task or bid is still checked by the handlers.
+var rolePermissions = map[string][]Permission{
+	models.RoleClient: {
+		CreateTask, UpdateTask, DeleteTask, CancelTask, ReviewWork, SuggestFreelancers,
+		ManageMilestones,
+		AcceptBid, CounterBid, TriageBids, CreateReview,
+		CreatePayment,
+	},
+	models.RoleFreelancer: {
+		SubmitWork, RecommendTasks, CreateBid, UpdateBid, WithdrawBid, AnswerBid, CreateReview,
+	},
+	models.RoleAdmin: {
+		UpdatePayment,
+		AdminAccess,
+	},
+}
+
+// Allowed reports whether role holds permission.
+func Allowed(role string, permission Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Matrix returns a sorted copy of the role to permission table.
+func Matrix() map[string][]Permission {
+	matrix := make(map[string][]Permission, len(rolePermissions))
+	for role, permissions := range rolePermissions {
+		sorted := append([]Permission(nil), permissions...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		matrix[role] = sorted
+	}
+	return matrix
+}
@@ -0,0 +1,238 @@
+// Package recommend matches freelancers with tasks. Open tasks are ranked
+// for a freelancer, and freelancers for a task, by a weighted score over
+// skill overlap, budget fit, history and recency.
+package recommend
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// candidateLimit caps each candidate query; only the candidates found are
+// scored.
+const candidateLimit = 200
+
+// Weights weigh the signals of a match against each other. Only their
+// ratios matter.
+type Weights struct {
+	Skills  float64
+	Budget  float64
+	History float64
+	Recency float64
+}
+
+// Config controls how matches are scored.
+type Config struct {
+	Weights         Weights
+	RecencyHalfLife time.Duration // recency halves every half-life
+}
+
+// DefaultConfig is used for anything the environment leaves unset.
+func DefaultConfig() Config {
+	return Config{
+		Weights:         Weights{Skills: 0.4, Budget: 0.2, History: 0.25, Recency: 0.15},
+		RecencyHalfLife: 72 * time.Hour,
+	}
+}
+
+// ConfigFromEnv reads RECOMMEND_WEIGHT_SKILLS, RECOMMEND_WEIGHT_BUDGET,
+// RECOMMEND_WEIGHT_HISTORY, RECOMMEND_WEIGHT_RECENCY and
+// RECOMMEND_RECENCY_HALF_LIFE, falling back to DefaultConfig for values
+// that are unset or invalid.
+func ConfigFromEnv() Config {
+	config := DefaultConfig()
+	weights := map[string]*float64{
+		"RECOMMEND_WEIGHT_SKILLS":  &config.Weights.Skills,
+		"RECOMMEND_WEIGHT_BUDGET":  &config.Weights.Budget,
+		"RECOMMEND_WEIGHT_HISTORY": &config.Weights.History,
+		"RECOMMEND_WEIGHT_RECENCY": &config.Weights.Recency,
+	}
+	for name, weight := range weights {
+		if value, err := strconv.ParseFloat(os.Getenv(name), 64); err == nil && value >= 0 {
+			*weight = value
+		}
+	}
+	if value, err := time.ParseDuration(os.Getenv("RECOMMEND_RECENCY_HALF_LIFE")); err == nil && value > 0 {
+		config.RecencyHalfLife = value
+	}
+	return config
+}
+
+// TaskMatch is an open task recommended to a freelancer.
+type TaskMatch struct {
+	Task          models.Task `json:"task"`
+	Score         float64     `json:"score"`
+	Breakdown     Breakdown   `json:"breakdown"`
+	MatchedSkills []string    `json:"matched_skills"`
+}
+
+// FreelancerMatch is a freelancer suggested for a task.
+type FreelancerMatch struct {
+	Freelancer     models.UserResponse `json:"freelancer"`
+	Score          float64             `json:"score"`
+	Breakdown      Breakdown           `json:"breakdown"`
+	MatchedSkills  []string            `json:"matched_skills"`
+	CompletedTasks int                 `json:"completed_tasks"`
+	OnTimeRate     *float64            `json:"on_time_rate"` // null without completed tasks
+}
+
+type Service struct {
+	tasks  repository.TaskRepository
+	bids   repository.BidRepository
+	users  repository.UserRepository
+	config Config
+}
+
+// NewService builds the recommender. Weights that are all zero fall back to
+// the default weights.
+func NewService(tasks repository.TaskRepository, bids repository.BidRepository, users repository.UserRepository, config Config) *Service {
+	if config.Weights.Score(Breakdown{Skills: 1, Budget: 1, History: 1, Recency: 1}) == 0 {
+		config.Weights = DefaultConfig().Weights
+	}
+	if config.RecencyHalfLife <= 0 {
+		config.RecencyHalfLife = DefaultConfig().RecencyHalfLife
+	}
+	return &Service{tasks: tasks, bids: bids, users: users, config: config}
+}
+
+// RecommendTasks returns up to limit open tasks for freelancer, best match
+// first. Tasks no longer taking bids and tasks the freelancer already bid
+// on are left out.
+func (s *Service) RecommendTasks(ctx context.Context, freelancer *models.User, now time.Time, limit int) ([]TaskMatch, error) {
+	candidates, err := s.openTasks(ctx, freelancer.Skills)
+	if err != nil {
+		return nil, err
+	}
+	candidates = slices.DeleteFunc(candidates, func(task models.Task) bool { return !task.AcceptsBids(now) })
+	if len(candidates) == 0 {
+		return []TaskMatch{}, nil
+	}
+
+	taskIDs := make([]primitive.ObjectID, len(candidates))
+	for i := range candidates {
+		taskIDs[i] = candidates[i].ID
+	}
+	bidOn, err := s.bids.FindBidTaskIDs(ctx, freelancer.ID, taskIDs)
+	if err != nil {
+		return nil, fmt.Errorf("find bids: %w", err)
+	}
+	histories, err := s.histories(ctx, []primitive.ObjectID{freelancer.ID})
+	if err != nil {
+		return nil, err
+	}
+	history := histories[freelancer.ID]
+
+	matches := []TaskMatch{}
+	for i := range candidates {
+		if !slices.Contains(bidOn, candidates[i].ID) {
+			matches = append(matches, scoreTask(freelancer, history, &candidates[i], now, s.config))
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		if !matches[i].Task.CreatedAt.Equal(matches[j].Task.CreatedAt) {
+			return matches[i].Task.CreatedAt.After(matches[j].Task.CreatedAt)
+		}
+		return matches[i].Task.ID.Hex() > matches[j].Task.ID.Hex()
+	})
+	return matches[:min(limit, len(matches))], nil
+}
+
+// SuggestFreelancers returns up to limit freelancers for task, best match
+// first. Candidates are active freelancers with one of the task's required
+// skills, or any active freelancer when the task requires none.
+func (s *Service) SuggestFreelancers(ctx context.Context, task *models.Task, now time.Time, limit int) ([]FreelancerMatch, error) {
+	suspended := false
+	filter := repository.UserFilter{UserType: models.RoleFreelancer, Suspended: &suspended, Skills: task.RequiredSkills}
+	candidates, err := s.users.Search(ctx, filter, pagination.Params{Limit: candidateLimit})
+	if err != nil {
+		return nil, fmt.Errorf("find freelancers: %w", err)
+	}
+	if len(candidates.Items) == 0 {
+		return []FreelancerMatch{}, nil
+	}
+
+	freelancerIDs := make([]primitive.ObjectID, len(candidates.Items))
+	for i := range candidates.Items {
+		freelancerIDs[i] = candidates.Items[i].ID
+	}
+	histories, err := s.histories(ctx, freelancerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]FreelancerMatch, len(candidates.Items))
+	for i := range candidates.Items {
+		freelancer := &candidates.Items[i]
+		matches[i] = scoreFreelancer(freelancer, histories[freelancer.ID], task, now, s.config)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Freelancer.ID < matches[j].Freelancer.ID
+	})
+	return matches[:min(limit, len(matches))], nil
+}
+
+// openTasks returns the newest open tasks together with the newest open
+// tasks requiring one of skills, as typed or lowercased, without
+// duplicates.
+func (s *Service) openTasks(ctx context.Context, skills []string) ([]models.Task, error) {
+	searches := []repository.TaskSearch{{Status: models.TaskStatusOpen, Sort: repository.TaskSortNewest, Page: pagination.Params{Limit: candidateLimit}}}
+	var variants []string
+	for _, skill := range skills {
+		skill = strings.TrimSpace(skill)
+		for _, variant := range []string{skill, strings.ToLower(skill)} {
+			if variant != "" && !slices.Contains(variants, variant) {
+				variants = append(variants, variant)
+			}
+		}
+	}
+	if len(variants) > 0 {
+		searches = append(searches, repository.TaskSearch{Status: models.TaskStatusOpen, Skills: variants, Sort: repository.TaskSortNewest, Page: pagination.Params{Limit: candidateLimit}})
+	}
+
+	tasks := []models.Task{}
+	seen := make(map[primitive.ObjectID]bool)
+	for _, search := range searches {
+		result, err := s.tasks.Search(ctx, search)
+		if err != nil {
+			return nil, fmt.Errorf("find open tasks: %w", err)
+		}
+		for _, task := range result.Items {
+			if !seen[task.ID] {
+				seen[task.ID] = true
+				tasks = append(tasks, task)
+			}
+		}
+	}
+	return tasks, nil
+}
+
+// histories loads the completed-task history of each freelancer, keyed by
+// ID. Freelancers without completed tasks have no entry.
+func (s *Service) histories(ctx context.Context, freelancerIDs []primitive.ObjectID) (map[primitive.ObjectID]*repository.FreelancerHistory, error) {
+	list, err := s.tasks.FreelancerHistories(ctx, freelancerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("load freelancer histories: %w", err)
+	}
+	histories := make(map[primitive.ObjectID]*repository.FreelancerHistory, len(list))
+	for i := range list {
+		histories[list[i].FreelancerID] = &list[i]
+	}
+	return histories, nil
+}
@@ -0,0 +1,262 @@
+package recommend
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// now is the moment the fixtures in testdata/marketplace.json are scored at.
+var now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// Fixture IDs, see testdata/marketplace.json.
+const (
+	gopher    = "000000000000000000000f01" // Go and PostgreSQL, three completed backend tasks, one late
+	reacty    = "000000000000000000000f02" // React and CSS, one completed frontend task
+	newbie    = "000000000000000000000f03" // Go, no history
+	banned    = "000000000000000000000f04" // suspended
+	ordersAPI = "000000000000000000000a01" // go+postgresql, for a client gopher worked for
+	checkout  = "000000000000000000000a02" // React+CSS, the newest task
+	legacyGo  = "000000000000000000000a03" // Go, two months old and a low budget
+	pipeline  = "000000000000000000000a04" // gopher already bid on it
+	sealedGo  = "000000000000000000000a05" // auction whose bidding closed
+	assigned  = "000000000000000000000a06" // in progress
+)
+
+type fixtures struct {
+	Users []models.User `json:"users"`
+	Tasks []models.Task `json:"tasks"`
+	Bids  []models.Bid  `json:"bids"`
+}
+
+// loadFixtures fills in-memory repositories with testdata/marketplace.json.
+func loadFixtures(t *testing.T) *repository.Repositories {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/marketplace.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f fixtures
+	if err := json.Unmarshal(data, &f); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	repos := repository.NewMemoryRepositories()
+	for i := range f.Users {
+		if err := repos.Users.Create(ctx, &f.Users[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range f.Tasks {
+		if err := repos.Tasks.Create(ctx, &f.Tasks[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range f.Bids {
+		if err := repos.Bids.Create(ctx, &f.Bids[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return repos
+}
+
+func newTestService(t *testing.T, config Config) (*Service, *repository.Repositories) {
+	t.Helper()
+	repos := loadFixtures(t)
+	return NewService(repos.Tasks, repos.Bids, repos.Users, config), repos
+}
+
+func findUser(t *testing.T, repos *repository.Repositories, hex string) *models.User {
+	t.Helper()
+	user, err := repos.Users.FindByID(context.Background(), objectID(t, hex))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func findTask(t *testing.T, repos *repository.Repositories, hex string) *models.Task {
+	t.Helper()
+	task, err := repos.Tasks.FindByID(context.Background(), objectID(t, hex))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return task
+}
+
+func objectID(t *testing.T, hex string) primitive.ObjectID {
+	t.Helper()
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func taskIDs(matches []TaskMatch) []string {
+	ids := make([]string, len(matches))
+	for i, match := range matches {
+		ids[i] = match.Task.ID.Hex()
+	}
+	return ids
+}
+
+func freelancerIDs(matches []FreelancerMatch) []string {
+	ids := make([]string, len(matches))
+	for i, match := range matches {
+		ids[i] = match.Freelancer.ID
+	}
+	return ids
+}
+
+func assertIDs(t *testing.T, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func assertNear(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 0.001 {
+		t.Errorf("%s = %.4f, want %.4f", name, got, want)
+	}
+}
+
+func TestRecommendTasksRanksByDefaultWeights(t *testing.T) {
+	service, repos := newTestService(t, DefaultConfig())
+
+	matches, err := service.RecommendTasks(context.Background(), findUser(t, repos, gopher), now, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The pipeline task is already bid on, the sealed task no longer takes
+	// bids and the assigned task is not open.
+	assertIDs(t, taskIDs(matches), []string{ordersAPI, legacyGo, checkout})
+
+	best := matches[0]
+	assertNear(t, "skills", best.Breakdown.Skills, 1)
+	assertNear(t, "budget", best.Breakdown.Budget, 1)                // 1200 against an average of 1000
+	assertNear(t, "history", best.Breakdown.History, 1)              // repeat client
+	assertNear(t, "recency", best.Breakdown.Recency, math.Sqrt(0.5)) // 36h old, 72h half-life
+	assertNear(t, "score", best.Score, 0.4+0.2+0.25+0.15*math.Sqrt(0.5))
+	if len(best.MatchedSkills) != 2 || best.MatchedSkills[0] != "go" || best.MatchedSkills[1] != "postgresql" {
+		t.Errorf("matched skills = %v, want [go postgresql]", best.MatchedSkills)
+	}
+
+	legacy := matches[1]
+	assertNear(t, "legacy budget", legacy.Breakdown.Budget, 0.3)
+	assertNear(t, "legacy history", legacy.Breakdown.History, 1) // every completed task was backend
+}
+
+func TestRecommendTasksFollowsConfiguredWeights(t *testing.T) {
+	service, repos := newTestService(t, Config{Weights: Weights{Recency: 1}, RecencyHalfLife: 72 * time.Hour})
+
+	matches, err := service.RecommendTasks(context.Background(), findUser(t, repos, gopher), now, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertIDs(t, taskIDs(matches), []string{checkout, ordersAPI, legacyGo})
+}
+
+func TestRecommendTasksWithoutHistory(t *testing.T) {
+	service, repos := newTestService(t, DefaultConfig())
+
+	matches, err := service.RecommendTasks(context.Background(), findUser(t, repos, newbie), now, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only half of the skills of the orders API and the pipeline match, so
+	// the older task that needs just Go ranks first. The two tie and the
+	// newer ID wins.
+	assertIDs(t, taskIDs(matches), []string{legacyGo, pipeline, ordersAPI, checkout})
+	for _, match := range matches {
+		assertNear(t, match.Task.Title+" budget", match.Breakdown.Budget, neutral)
+		assertNear(t, match.Task.Title+" history", match.Breakdown.History, 0)
+	}
+}
+
+func TestRecommendTasksLimit(t *testing.T) {
+	service, repos := newTestService(t, DefaultConfig())
+
+	matches, err := service.RecommendTasks(context.Background(), findUser(t, repos, gopher), now, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertIDs(t, taskIDs(matches), []string{ordersAPI})
+}
+
+func TestSuggestFreelancers(t *testing.T) {
+	service, repos := newTestService(t, DefaultConfig())
+
+	matches, err := service.SuggestFreelancers(context.Background(), findTask(t, repos, ordersAPI), now, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The suspended freelancer and the one without Go or PostgreSQL are
+	// left out.
+	assertIDs(t, freelancerIDs(matches), []string{gopher, newbie})
+
+	best := matches[0]
+	if best.CompletedTasks != 3 || best.OnTimeRate == nil {
+		t.Fatalf("completed = %d, on-time rate = %v, want 3 and a rate", best.CompletedTasks, best.OnTimeRate)
+	}
+	assertNear(t, "on-time rate", *best.OnTimeRate, 2.0/3)
+	assertNear(t, "history", best.Breakdown.History, (4.8/5+2.0/3)/2)
+	assertNear(t, "recency", best.Breakdown.Recency, math.Pow(0.5, 300.0/72)) // last completed 12.5 days ago
+
+	newcomer := matches[1]
+	if newcomer.CompletedTasks != 0 || newcomer.OnTimeRate != nil {
+		t.Errorf("newcomer completed = %d, on-time rate = %v, want none", newcomer.CompletedTasks, newcomer.OnTimeRate)
+	}
+	assertNear(t, "newcomer skills", newcomer.Breakdown.Skills, 0.5)
+}
+
+func TestSuggestFreelancersMatchesSkillsIgnoringCase(t *testing.T) {
+	service, repos := newTestService(t, DefaultConfig())
+
+	matches, err := service.SuggestFreelancers(context.Background(), findTask(t, repos, checkout), now, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertIDs(t, freelancerIDs(matches), []string{reacty})
+	assertNear(t, "skills", matches[0].Breakdown.Skills, 1)
+}
+
+func TestSuggestFreelancersExcludesSuspended(t *testing.T) {
+	service, repos := newTestService(t, DefaultConfig())
+
+	matches, err := service.SuggestFreelancers(context.Background(), findTask(t, repos, legacyGo), now, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, match := range matches {
+		if match.Freelancer.ID == banned {
+			t.Fatal("suspended freelancer suggested")
+		}
+	}
+}
+
+func TestNewServiceDefaultsZeroWeights(t *testing.T) {
+	service := NewService(nil, nil, nil, Config{})
+	if service.config != DefaultConfig() {
+		t.Errorf("config = %+v, want the default", service.config)
+	}
+}
@@ -0,0 +1,144 @@
+package recommend
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+)
+
+// neutral is the score of a signal there is no data for, so it neither
+// helps nor hurts a match.
+const neutral = 0.5
+
+// Breakdown holds the individual signals of a match, each between 0 and 1.
+type Breakdown struct {
+	Skills  float64 `json:"skills"`
+	Budget  float64 `json:"budget"`
+	History float64 `json:"history"`
+	Recency float64 `json:"recency"`
+}
+
+// Score combines the signals of b into one score between 0 and 1, weighing
+// each by its share of the total weight.
+func (w Weights) Score(b Breakdown) float64 {
+	total := w.Skills + w.Budget + w.History + w.Recency
+	if total <= 0 {
+		return 0
+	}
+	return (w.Skills*b.Skills + w.Budget*b.Budget + w.History*b.History + w.Recency*b.Recency) / total
+}
+
+// skillOverlap returns the share of the required skills found in skills,
+// and which they are, normalized and sorted. A task without required
+// skills is a neutral match for everyone.
+func skillOverlap(skills, required []string) (float64, []string) {
+	required = models.NormalizeSkills(required)
+	matched := []string{}
+	for _, skill := range models.NormalizeSkills(skills) {
+		if slices.Contains(required, skill) {
+			matched = append(matched, skill)
+		}
+	}
+	slices.Sort(matched)
+	if len(required) == 0 {
+		return neutral, matched
+	}
+	return float64(len(matched)) / float64(len(required)), matched
+}
+
+// budgetFit compares a task's budget with the average budget of the
+// freelancer's completed tasks. A budget at least as high fits fully and a
+// lower one fits in proportion. Without history the fit is neutral.
+func budgetFit(budget float64, history *repository.FreelancerHistory) float64 {
+	if history == nil || history.AverageBudget <= 0 {
+		return neutral
+	}
+	return math.Min(budget/history.AverageBudget, 1)
+}
+
+// affinity scores a freelancer's history with the kind of task on offer:
+// the share of their completed tasks in its category, or 1 if they already
+// completed a task for the same client.
+func affinity(task *models.Task, history *repository.FreelancerHistory) float64 {
+	if history == nil || history.Completed == 0 {
+		return 0
+	}
+	if slices.Contains(history.ClientIDs, task.ClientID) {
+		return 1
+	}
+	for _, category := range history.Categories {
+		if category.Category == task.Category {
+			return float64(category.Count) / float64(history.Completed)
+		}
+	}
+	return 0
+}
+
+// trackRecord scores a freelancer's history as a hire: the mean of their
+// rating out of 5 and their on-time delivery rate. Either counts as 0
+// until the freelancer has one.
+func trackRecord(freelancer *models.User, history *repository.FreelancerHistory) float64 {
+	onTime := 0.0
+	if history != nil && history.Completed > 0 {
+		onTime = float64(history.OnTime) / float64(history.Completed)
+	}
+	return (math.Min(freelancer.Rating/5, 1) + onTime) / 2
+}
+
+// recency halves every halfLife since at, and is 1 for times not yet past.
+func recency(at, now time.Time, halfLife time.Duration) float64 {
+	age := now.Sub(at)
+	if age <= 0 || halfLife <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, float64(age)/float64(halfLife))
+}
+
+// scoreTask rates task as a recommendation for freelancer.
+func scoreTask(freelancer *models.User, history *repository.FreelancerHistory, task *models.Task, now time.Time, config Config) TaskMatch {
+	skills, matched := skillOverlap(freelancer.Skills, task.RequiredSkills)
+	breakdown := Breakdown{
+		Skills:  skills,
+		Budget:  budgetFit(task.Budget, history),
+		History: affinity(task, history),
+		Recency: recency(task.CreatedAt, now, config.RecencyHalfLife),
+	}
+	return TaskMatch{
+		Task:          *task,
+		Score:         config.Weights.Score(breakdown),
+		Breakdown:     breakdown,
+		MatchedSkills: matched,
+	}
+}
+
+// scoreFreelancer rates freelancer as a suggestion for task. Recency is
+// measured from the freelancer's last completed task, or from sign-up.
+func scoreFreelancer(freelancer *models.User, history *repository.FreelancerHistory, task *models.Task, now time.Time, config Config) FreelancerMatch {
+	skills, matched := skillOverlap(freelancer.Skills, task.RequiredSkills)
+	lastActive := freelancer.CreatedAt
+	if history != nil && history.LastCompletedAt != nil {
+		lastActive = *history.LastCompletedAt
+	}
+	breakdown := Breakdown{
+		Skills:  skills,
+		Budget:  budgetFit(task.Budget, history),
+		History: trackRecord(freelancer, history),
+		Recency: recency(lastActive, now, config.RecencyHalfLife),
+	}
+
+	match := FreelancerMatch{
+		Freelancer:    freelancer.ToResponse(),
+		Score:         config.Weights.Score(breakdown),
+		Breakdown:     breakdown,
+		MatchedSkills: matched,
+	}
+	if history != nil && history.Completed > 0 {
+		rate := float64(history.OnTime) / float64(history.Completed)
+		match.CompletedTasks = history.Completed
+		match.OnTimeRate = &rate
+	}
+	return match
+}
@@ -0,0 +1,157 @@
+package recommend
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSkillOverlap(t *testing.T) {
+	tests := []struct {
+		name     string
+		skills   []string
+		required []string
+		want     float64
+		matched  int
+	}{
+		{"all", []string{"Go", "SQL"}, []string{"go", "sql"}, 1, 2},
+		{"half", []string{"go"}, []string{"go", "react"}, 0.5, 1},
+		{"none", []string{"php"}, []string{"go"}, 0, 0},
+		{"case and space", []string{" GO "}, []string{"go", "Go"}, 1, 1},
+		{"nothing required", []string{"go"}, nil, neutral, 0},
+		{"no skills", nil, []string{"go"}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, matched := skillOverlap(tt.skills, tt.required)
+			if got != tt.want || len(matched) != tt.matched {
+				t.Errorf("skillOverlap = %v %v, want %v with %d matched", got, matched, tt.want, tt.matched)
+			}
+		})
+	}
+}
+
+func TestBudgetFit(t *testing.T) {
+	history := &repository.FreelancerHistory{Completed: 2, AverageBudget: 1000}
+	tests := []struct {
+		name    string
+		budget  float64
+		history *repository.FreelancerHistory
+		want    float64
+	}{
+		{"above average", 1500, history, 1},
+		{"at average", 1000, history, 1},
+		{"below average", 250, history, 0.25},
+		{"no history", 250, nil, neutral},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := budgetFit(tt.budget, tt.history); got != tt.want {
+				t.Errorf("budgetFit = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAffinity(t *testing.T) {
+	client := primitive.NewObjectID()
+	history := &repository.FreelancerHistory{
+		Completed:  4,
+		Categories: []repository.CategoryCount{{Category: "backend", Count: 3}, {Category: "design", Count: 1}},
+		ClientIDs:  []primitive.ObjectID{client},
+	}
+	tests := []struct {
+		name    string
+		task    models.Task
+		history *repository.FreelancerHistory
+		want    float64
+	}{
+		{"repeat client", models.Task{ClientID: client, Category: "design"}, history, 1},
+		{"category share", models.Task{ClientID: primitive.NewObjectID(), Category: "backend"}, history, 0.75},
+		{"new category", models.Task{ClientID: primitive.NewObjectID(), Category: "mobile"}, history, 0},
+		{"no history", models.Task{ClientID: client, Category: "backend"}, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := affinity(&tt.task, tt.history); got != tt.want {
+				t.Errorf("affinity = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrackRecord(t *testing.T) {
+	freelancer := &models.User{Rating: 4}
+	if got := trackRecord(freelancer, nil); got != 0.4 {
+		t.Errorf("without history = %v, want 0.4", got)
+	}
+	history := &repository.FreelancerHistory{Completed: 4, OnTime: 3}
+	if got := trackRecord(freelancer, history); got != (0.8+0.75)/2 {
+		t.Errorf("with history = %v, want %v", got, (0.8+0.75)/2)
+	}
+}
+
+func TestRecency(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	halfLife := 24 * time.Hour
+	tests := []struct {
+		name string
+		at   time.Time
+		want float64
+	}{
+		{"now", now, 1},
+		{"future", now.Add(time.Hour), 1},
+		{"one half-life", now.Add(-24 * time.Hour), 0.5},
+		{"two half-lives", now.Add(-48 * time.Hour), 0.25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := recency(tt.at, now, halfLife); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("recency = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWeightsScore(t *testing.T) {
+	breakdown := Breakdown{Skills: 1, Budget: 0.5, History: 0, Recency: 0.25}
+	tests := []struct {
+		name    string
+		weights Weights
+		want    float64
+	}{
+		{"skills only", Weights{Skills: 2}, 1},
+		{"even", Weights{Skills: 1, Budget: 1, History: 1, Recency: 1}, 0.4375},
+		{"ratios matter", Weights{Skills: 10, Budget: 10, History: 10, Recency: 10}, 0.4375},
+		{"zero", Weights{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.weights.Score(breakdown); got != tt.want {
+				t.Errorf("Score = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("RECOMMEND_WEIGHT_SKILLS", "1")
+	t.Setenv("RECOMMEND_WEIGHT_BUDGET", "0")
+	t.Setenv("RECOMMEND_WEIGHT_HISTORY", "-1") // invalid, keeps the default
+	t.Setenv("RECOMMEND_WEIGHT_RECENCY", "abc")
+	t.Setenv("RECOMMEND_RECENCY_HALF_LIFE", "24h")
+
+	config := ConfigFromEnv()
+	defaults := DefaultConfig()
+	want := Config{
+		Weights:         Weights{Skills: 1, Budget: 0, History: defaults.Weights.History, Recency: defaults.Weights.Recency},
+		RecencyHalfLife: 24 * time.Hour,
+	}
+	if config != want {
+		t.Errorf("ConfigFromEnv = %+v, want %+v", config, want)
+	}
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AdminActionFilter narrows the moderation audit log. Empty fields are ignored.
+type AdminActionFilter struct {
+	TargetType string
+	TargetID   primitive.ObjectID
+}
+
+// AdminActionRepository stores the append-only moderation audit log.
+type AdminActionRepository interface {
+	Create(ctx context.Context, action *models.AdminAction) error
+	Find(ctx context.Context, filter AdminActionFilter, page pagination.Params) (*pagination.Page[models.AdminAction], error)
+}
+
+type mongoAdminActionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoAdminActionRepository(db *mongo.Database) AdminActionRepository {
+	return &mongoAdminActionRepository{collection: db.Collection("admin_actions")}
+}
+
+func (r *mongoAdminActionRepository) Create(ctx context.Context, action *models.AdminAction) error {
+	if action.ID.IsZero() {
+		action.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, action)
+	return mongoError(err)
+}
+
+func (r *mongoAdminActionRepository) Find(ctx context.Context, filter AdminActionFilter, page pagination.Params) (*pagination.Page[models.AdminAction], error) {
+	query := bson.M{}
+	if filter.TargetType != "" {
+		query["target_type"] = filter.TargetType
+	}
+	if !filter.TargetID.IsZero() {
+		query["target_id"] = filter.TargetID
+	}
+
+	return findPage(ctx, r.collection, query, page, true, adminActionCursor)
+}
@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BidStats summarizes a task's open bids.
+type BidStats struct {
+	Count        int     `bson:"count"`
+	LowestAmount float64 `bson:"lowest_amount"` // zero without open bids
+}
+
+// Task owner bid listing views, see BidFilter.
+const (
+	BidViewActive      = "active"      // every bid that is not archived
+	BidViewShortlisted = "shortlisted" // shortlisted bids
+	BidViewArchived    = "archived"    // archived bids
+)
+
+// BidFilter narrows a task's bids to one of the owner's triage views. The
+// zero value matches every bid.
+type BidFilter struct {
+	View string
+}
+
+// ComparedFreelancer is the part of a bidder's profile shown when comparing
+// bids.
+type ComparedFreelancer struct {
+	ID           primitive.ObjectID `bson:"_id" json:"id"`
+	FirstName    string             `bson:"first_name" json:"first_name"`
+	LastName     string             `bson:"last_name" json:"last_name"`
+	ProfileImage string             `bson:"profile_image,omitempty" json:"profile_image,omitempty"`
+	Skills       []string           `bson:"skills" json:"skills,omitempty"`
+	Rating       float64            `bson:"rating" json:"rating"`
+}
+
+// BidComparison is a bid joined with its freelancer's profile and delivery
+// record, see BidRepository.Compare.
+type BidComparison struct {
+	Bid            models.Bid         `bson:"bid"`
+	Freelancer     ComparedFreelancer `bson:"freelancer"`
+	CompletedTasks int                `bson:"completed_tasks"`
+	OnTimeTasks    int                `bson:"on_time_tasks"`  // completed without being flagged overdue
+	MatchedSkills  []string           `bson:"matched_skills"` // normalized, sorted
+}
+
+type BidRepository interface {
+	Create(ctx context.Context, bid *models.Bid) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Bid, error)
+	FindByTask(ctx context.Context, taskID primitive.ObjectID, filter BidFilter, page pagination.Params) (*pagination.Page[models.Bid], error)
+	FindByTaskAndFreelancer(ctx context.Context, taskID, freelancerID primitive.ObjectID) (*models.Bid, error)
+	// FindByTaskAndStatus returns every bid on the task with one of the
+	// given statuses, oldest first.
+	FindByTaskAndStatus(ctx context.Context, taskID primitive.ObjectID, statuses ...string) ([]models.Bid, error)
+	// FindOpenBidTaskIDs returns the tasks that have bids still awaiting a
+	// decision, see models.OpenBidStatuses.
+	FindOpenBidTaskIDs(ctx context.Context) ([]primitive.ObjectID, error)
+	// Compare returns the task's bids with the given IDs, oldest first, each
+	// joined with its freelancer's profile, completed-task count, on-time
+	// count and the skills it shares with requiredSkills. The join runs as a
+	// single query however many bids are compared.
+	Compare(ctx context.Context, taskID primitive.ObjectID, bidIDs []primitive.ObjectID, requiredSkills []string) ([]BidComparison, error)
+	// FindBidTaskIDs returns those of taskIDs the freelancer has bid on, in
+	// any status.
+	FindBidTaskIDs(ctx context.Context, freelancerID primitive.ObjectID, taskIDs []primitive.ObjectID) ([]primitive.ObjectID, error)
+	// OpenBidStats counts the task's open bids and finds the lowest amount.
+	OpenBidStats(ctx context.Context, taskID primitive.ObjectID) (BidStats, error)
+	Update(ctx context.Context, bid *models.Bid) error
+	// UpdateIfStatus saves bid only if its stored status is still
+	// expectedStatus, returning ErrConflict otherwise.
+	UpdateIfStatus(ctx context.Context, bid *models.Bid, expectedStatus string) error
+	// UpdateIfUnchanged saves bid only if nobody saved it since it was
+	// loaded, that is its stored UpdatedAt is still lastUpdatedAt, returning
+	// ErrConflict otherwise. Negotiation steps use it so two racing steps
+	// cannot drop each other's entry from the thread.
+	UpdateIfUnchanged(ctx context.Context, bid *models.Bid, lastUpdatedAt time.Time) error
+}
+
+type mongoBidRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoBidRepository(db *mongo.Database) BidRepository {
+	return &mongoBidRepository{collection: db.Collection("bids")}
+}
+
+func (r *mongoBidRepository) Create(ctx context.Context, bid *models.Bid) error {
+	if bid.ID.IsZero() {
+		bid.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, bid)
+	return mongoError(err)
+}
+
+func (r *mongoBidRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Bid, error) {
+	var bid models.Bid
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&bid); err != nil {
+		return nil, mongoError(err)
+	}
+	return &bid, nil
+}
+
+func (r *mongoBidRepository) FindByTask(ctx context.Context, taskID primitive.ObjectID, filter BidFilter, page pagination.Params) (*pagination.Page[models.Bid], error) {
+	query := bson.M{"task_id": taskID}
+	switch filter.View {
+	case BidViewActive:
+		query["archived"] = bson.M{"$ne": true}
+	case BidViewShortlisted:
+		query["shortlisted"] = true
+	case BidViewArchived:
+		query["archived"] = true
+	}
+	return findPage(ctx, r.collection, query, page, false, bidCursor)
+}
+
+func (r *mongoBidRepository) FindByTaskAndFreelancer(ctx context.Context, taskID, freelancerID primitive.ObjectID) (*models.Bid, error) {
+	var bid models.Bid
+	filter := bson.M{"task_id": taskID, "freelancer_id": freelancerID}
+	if err := r.collection.FindOne(ctx, filter).Decode(&bid); err != nil {
+		return nil, mongoError(err)
+	}
+	return &bid, nil
+}
+
+func (r *mongoBidRepository) FindByTaskAndStatus(ctx context.Context, taskID primitive.ObjectID, statuses ...string) ([]models.Bid, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"task_id": taskID, "status": bson.M{"$in": statuses}}, opts)
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	bids := []models.Bid{}
+	if err := cursor.All(ctx, &bids); err != nil {
+		return nil, err
+	}
+	return bids, nil
+}
+
+func (r *mongoBidRepository) FindOpenBidTaskIDs(ctx context.Context) ([]primitive.ObjectID, error) {
+	values, err := r.collection.Distinct(ctx, "task_id", bson.M{"status": bson.M{"$in": models.OpenBidStatuses}})
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	taskIDs := make([]primitive.ObjectID, 0, len(values))
+	for _, value := range values {
+		if taskID, ok := value.(primitive.ObjectID); ok {
+			taskIDs = append(taskIDs, taskID)
+		}
+	}
+	return taskIDs, nil
+}
+
+func (r *mongoBidRepository) FindBidTaskIDs(ctx context.Context, freelancerID primitive.ObjectID, taskIDs []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	values, err := r.collection.Distinct(ctx, "task_id", bson.M{"freelancer_id": freelancerID, "task_id": bson.M{"$in": taskIDs}})
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	bidTaskIDs := make([]primitive.ObjectID, 0, len(values))
+	for _, value := range values {
+		if taskID, ok := value.(primitive.ObjectID); ok {
+			bidTaskIDs = append(bidTaskIDs, taskID)
+		}
+	}
+	return bidTaskIDs, nil
+}
+
+func (r *mongoBidRepository) Update(ctx context.Context, bid *models.Bid) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": bid.ID}, bid)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoBidRepository) UpdateIfStatus(ctx context.Context, bid *models.Bid, expectedStatus string) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": bid.ID, "status": expectedStatus}, bid)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoBidRepository) UpdateIfUnchanged(ctx context.Context, bid *models.Bid, lastUpdatedAt time.Time) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": bid.ID, "updated_at": lastUpdatedAt}, bid)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoBidRepository) OpenBidStats(ctx context.Context, taskID primitive.ObjectID) (BidStats, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"task_id": taskID, "status": bson.M{"$in": models.OpenBidStatuses}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":           nil,
+			"count":         bson.M{"$sum": 1},
+			"lowest_amount": bson.M{"$min": "$amount"},
+		}}},
+	}
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return BidStats{}, mongoError(err)
+	}
+
+	results := []BidStats{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return BidStats{}, err
+	}
+	if len(results) == 0 {
+		return BidStats{}, nil
+	}
+	return results[0], nil
+}
+
+func (r *mongoBidRepository) Compare(ctx context.Context, taskID primitive.ObjectID, bidIDs []primitive.ObjectID, requiredSkills []string) ([]BidComparison, error) {
+	lowerSkills := bson.M{"$map": bson.M{
+		"input": bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$freelancer.skills", 0}}, bson.A{}}},
+		"in":    bson.M{"$toLower": bson.M{"$trim": bson.M{"input": "$$this"}}},
+	}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"task_id": taskID, "_id": bson.M{"$in": bidIDs}}}},
+		{{Key: "$project", Value: bson.M{"_id": 0, "bid": "$$ROOT"}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": "users",
+			"let":  bson.M{"freelancer_id": "$bid.freelancer_id"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"$_id", "$$freelancer_id"}}}},
+				bson.M{"$project": bson.M{"first_name": 1, "last_name": 1, "profile_image": 1, "skills": 1, "rating": 1}},
+			},
+			"as": "freelancer",
+		}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": "tasks",
+			"let":  bson.M{"freelancer_id": "$bid.freelancer_id"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{
+					"status": models.TaskStatusCompleted,
+					"$expr":  bson.M{"$eq": bson.A{"$freelancer_id", "$$freelancer_id"}},
+				}},
+				bson.M{"$group": bson.M{
+					"_id":       nil,
+					"completed": bson.M{"$sum": 1},
+					"on_time":   bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$overdue_at", nil}}, 0, 1}}},
+				}},
+			},
+			"as": "deliveries",
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"bid":             1,
+			"freelancer":      bson.M{"$arrayElemAt": bson.A{"$freelancer", 0}},
+			"completed_tasks": bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$deliveries.completed", 0}}, 0}},
+			"on_time_tasks":   bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$deliveries.on_time", 0}}, 0}},
+			"matched_skills":  bson.M{"$setIntersection": bson.A{lowerSkills, models.NormalizeSkills(requiredSkills)}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "bid.created_at", Value: 1}, {Key: "bid._id", Value: 1}}}},
+	}
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	comparisons := []BidComparison{}
+	if err := cursor.All(ctx, &comparisons); err != nil {
+		return nil, err
+	}
+	for i := range comparisons {
+		slices.Sort(comparisons[i].MatchedSkills)
+	}
+	return comparisons, nil
+}
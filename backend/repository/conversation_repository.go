@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ConversationRepository interface {
+	// Create returns ErrDuplicate if the task already has a conversation with
+	// the freelancer.
+	Create(ctx context.Context, conversation *models.Conversation) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Conversation, error)
+	FindByTaskAndFreelancer(ctx context.Context, taskID, freelancerID primitive.ObjectID) (*models.Conversation, error)
+	// FindByTask pages through a task's conversations, most recently active
+	// first.
+	FindByTask(ctx context.Context, taskID primitive.ObjectID, page pagination.Params) (*pagination.Page[models.Conversation], error)
+	// Touch records that a message was posted at the given time.
+	Touch(ctx context.Context, id primitive.ObjectID, at time.Time) error
+}
+
+type mongoConversationRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoConversationRepository(db *mongo.Database) ConversationRepository {
+	return &mongoConversationRepository{collection: db.Collection("conversations")}
+}
+
+func (r *mongoConversationRepository) Create(ctx context.Context, conversation *models.Conversation) error {
+	if conversation.ID.IsZero() {
+		conversation.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, conversation)
+	return mongoError(err)
+}
+
+func (r *mongoConversationRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Conversation, error) {
+	var conversation models.Conversation
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&conversation); err != nil {
+		return nil, mongoError(err)
+	}
+	return &conversation, nil
+}
+
+func (r *mongoConversationRepository) FindByTaskAndFreelancer(ctx context.Context, taskID, freelancerID primitive.ObjectID) (*models.Conversation, error) {
+	var conversation models.Conversation
+	filter := bson.M{"task_id": taskID, "freelancer_id": freelancerID}
+	if err := r.collection.FindOne(ctx, filter).Decode(&conversation); err != nil {
+		return nil, mongoError(err)
+	}
+	return &conversation, nil
+}
+
+// FindByTask orders by (updated_at, _id) rather than creation, so its
+// cursors carry updated_at in Time.
+func (r *mongoConversationRepository) FindByTask(ctx context.Context, taskID primitive.ObjectID, page pagination.Params) (*pagination.Page[models.Conversation], error) {
+	query := bson.M{"task_id": taskID}
+	if page.After != nil {
+		if page.After.Time == nil {
+			return nil, pagination.ErrInvalidCursor
+		}
+		query["$or"] = bson.A{
+			bson.M{"updated_at": bson.M{"$lt": *page.After.Time}},
+			bson.M{"updated_at": *page.After.Time, "_id": bson.M{"$lt": page.After.ID}},
+		}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "updated_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(page.Limit + 1))
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	conversations := []models.Conversation{}
+	if err := cursor.All(ctx, &conversations); err != nil {
+		return nil, err
+	}
+	return pagination.NewPage(conversations, page, conversationCursor), nil
+}
+
+func (r *mongoConversationRepository) Touch(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	update := bson.M{"$set": bson.M{"last_message_at": at, "updated_at": at}}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
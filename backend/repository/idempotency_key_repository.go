@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// IdempotencyKeyRepository stores Idempotency-Key records until they expire.
+type IdempotencyKeyRepository interface {
+	// Create claims the user's key, replacing an expired record. It returns
+	// ErrDuplicate while an unexpired record for the key exists.
+	Create(ctx context.Context, record *models.IdempotencyKey) error
+	// Find returns the user's unexpired record for key.
+	Find(ctx context.Context, userID primitive.ObjectID, key string) (*models.IdempotencyKey, error)
+	Update(ctx context.Context, record *models.IdempotencyKey) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+type mongoIdempotencyKeyRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoIdempotencyKeyRepository(db *mongo.Database) IdempotencyKeyRepository {
+	return &mongoIdempotencyKeyRepository{collection: db.Collection("idempotency_keys")}
+}
+
+// Create removes an expired record for the key first, since the TTL monitor
+// only runs periodically. The unique (user_id, key) index then lets exactly
+// one concurrent request claim the key.
+func (r *mongoIdempotencyKeyRepository) Create(ctx context.Context, record *models.IdempotencyKey) error {
+	expired := bson.M{"user_id": record.UserID, "key": record.Key, "expires_at": bson.M{"$lte": time.Now()}}
+	if _, err := r.collection.DeleteOne(ctx, expired); err != nil {
+		return mongoError(err)
+	}
+
+	if record.ID.IsZero() {
+		record.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, record)
+	return mongoError(err)
+}
+
+func (r *mongoIdempotencyKeyRepository) Find(ctx context.Context, userID primitive.ObjectID, key string) (*models.IdempotencyKey, error) {
+	filter := bson.M{"user_id": userID, "key": key, "expires_at": bson.M{"$gt": time.Now()}}
+
+	var record models.IdempotencyKey
+	if err := r.collection.FindOne(ctx, filter).Decode(&record); err != nil {
+		return nil, mongoError(err)
+	}
+	return &record, nil
+}
+
+func (r *mongoIdempotencyKeyRepository) Update(ctx context.Context, record *models.IdempotencyKey) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": record.ID}, record)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoIdempotencyKeyRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return mongoError(err)
+}
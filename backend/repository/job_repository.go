@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// JobFilter narrows the job list. Empty fields are ignored.
+type JobFilter struct {
+	Status string
+	Type   string
+}
+
+// JobRepository stores the background job queue. Every state change of a
+// leased job is conditional on the worker still holding the lease, so a
+// worker whose lease expired cannot overwrite the outcome of the worker
+// that took the job over.
+type JobRepository interface {
+	// Create returns ErrDuplicate when a job with the same UniqueKey exists.
+	Create(ctx context.Context, job *models.Job) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Job, error)
+	Find(ctx context.Context, filter JobFilter, page pagination.Params) (*pagination.Page[models.Job], error)
+	// Lease hands owner the earliest due job of one of the given types until
+	// the lease expires at until: a queued job whose RunAt has passed, or a
+	// running job whose previous lease expired. It counts the attempt and
+	// returns ErrNotFound when nothing is due.
+	Lease(ctx context.Context, types []string, owner string, now, until time.Time) (*models.Job, error)
+	// Complete marks owner's job succeeded; expiresAt is when it may be purged.
+	Complete(ctx context.Context, id primitive.ObjectID, owner string, at, expiresAt time.Time) error
+	// Release puts owner's failed job back in the queue to run again at runAt.
+	Release(ctx context.Context, id primitive.ObjectID, owner, lastError string, runAt, at time.Time) error
+	// Bury moves owner's failed job to the dead-letter state.
+	Bury(ctx context.Context, id primitive.ObjectID, owner, lastError string, at time.Time) error
+	// Requeue gives a dead job a fresh set of attempts starting at at. It
+	// returns ErrConflict for jobs that are not dead.
+	Requeue(ctx context.Context, id primitive.ObjectID, at time.Time) (*models.Job, error)
+}
+
+type mongoJobRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoJobRepository(db *mongo.Database) JobRepository {
+	return &mongoJobRepository{collection: db.Collection("jobs")}
+}
+
+func (r *mongoJobRepository) Create(ctx context.Context, job *models.Job) error {
+	if job.ID.IsZero() {
+		job.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, job)
+	return mongoError(err)
+}
+
+func (r *mongoJobRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Job, error) {
+	var job models.Job
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&job); err != nil {
+		return nil, mongoError(err)
+	}
+	return &job, nil
+}
+
+func (r *mongoJobRepository) Find(ctx context.Context, filter JobFilter, page pagination.Params) (*pagination.Page[models.Job], error) {
+	query := bson.M{}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.Type != "" {
+		query["type"] = filter.Type
+	}
+
+	return findPage(ctx, r.collection, query, page, true, jobCursor)
+}
+
+func (r *mongoJobRepository) Lease(ctx context.Context, types []string, owner string, now, until time.Time) (*models.Job, error) {
+	query := bson.M{
+		"type": bson.M{"$in": types},
+		"$or": bson.A{
+			bson.M{"status": models.JobQueued, "run_at": bson.M{"$lte": now}},
+			bson.M{"status": models.JobRunning, "leased_until": bson.M{"$lt": now}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{"status": models.JobRunning, "lease_owner": owner, "leased_until": until, "updated_at": now},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "run_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var job models.Job
+	if err := r.collection.FindOneAndUpdate(ctx, query, update, opts).Decode(&job); err != nil {
+		return nil, mongoError(err)
+	}
+	return &job, nil
+}
+
+func (r *mongoJobRepository) Complete(ctx context.Context, id primitive.ObjectID, owner string, at, expiresAt time.Time) error {
+	return r.finishLease(ctx, id, owner, bson.M{
+		"status":      models.JobSucceeded,
+		"finished_at": at,
+		"expires_at":  expiresAt,
+		"updated_at":  at,
+	})
+}
+
+func (r *mongoJobRepository) Release(ctx context.Context, id primitive.ObjectID, owner, lastError string, runAt, at time.Time) error {
+	return r.finishLease(ctx, id, owner, bson.M{
+		"status":     models.JobQueued,
+		"run_at":     runAt,
+		"last_error": lastError,
+		"updated_at": at,
+	})
+}
+
+func (r *mongoJobRepository) Bury(ctx context.Context, id primitive.ObjectID, owner, lastError string, at time.Time) error {
+	return r.finishLease(ctx, id, owner, bson.M{
+		"status":      models.JobDead,
+		"last_error":  lastError,
+		"finished_at": at,
+		"updated_at":  at,
+	})
+}
+
+// finishLease applies set to owner's running job and drops the lease.
+func (r *mongoJobRepository) finishLease(ctx context.Context, id primitive.ObjectID, owner string, set bson.M) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": models.JobRunning, "lease_owner": owner},
+		bson.M{"$set": set, "$unset": bson.M{"lease_owner": "", "leased_until": ""}},
+	)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoJobRepository) Requeue(ctx context.Context, id primitive.ObjectID, at time.Time) (*models.Job, error) {
+	update := bson.M{
+		"$set":   bson.M{"status": models.JobQueued, "attempts": 0, "run_at": at, "updated_at": at},
+		"$unset": bson.M{"finished_at": ""},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var job models.Job
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id, "status": models.JobDead}, update, opts).Decode(&job)
+	if err == mongo.ErrNoDocuments {
+		if _, findErr := r.FindByID(ctx, id); findErr != nil {
+			return nil, findErr
+		}
+		return nil, ErrConflict
+	}
+	if err != nil {
+		return nil, mongoError(err)
+	}
+	return &job, nil
+}
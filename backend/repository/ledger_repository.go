@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// LedgerFilter selects the postings summed by Balance. Account is required;
+// the ID fields are ignored when zero.
+type LedgerFilter struct {
+	Account      string
+	OwnerID      primitive.ObjectID
+	TaskID       primitive.ObjectID
+	ClientID     primitive.ObjectID
+	FreelancerID primitive.ObjectID
+}
+
+// LedgerRepository stores the append-only double-entry ledger. Entries are
+// never updated or deleted; corrections are new entries.
+type LedgerRepository interface {
+	// Post appends a balanced entry. Posting the same entry type twice for a
+	// payment returns ErrDuplicate, which makes retries safe.
+	Post(ctx context.Context, entry *models.LedgerEntry) error
+	FindByTask(ctx context.Context, taskID primitive.ObjectID) ([]models.LedgerEntry, error)
+	// Balance sums the matching postings, in cents.
+	Balance(ctx context.Context, filter LedgerFilter) (int64, error)
+}
+
+type mongoLedgerRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoLedgerRepository(db *mongo.Database) LedgerRepository {
+	return &mongoLedgerRepository{collection: db.Collection("ledger_entries")}
+}
+
+func (r *mongoLedgerRepository) Post(ctx context.Context, entry *models.LedgerEntry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+	if entry.ID.IsZero() {
+		entry.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, entry)
+	return mongoError(err)
+}
+
+func (r *mongoLedgerRepository) FindByTask(ctx context.Context, taskID primitive.ObjectID) ([]models.LedgerEntry, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"task_id": taskID})
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	entries := []models.LedgerEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *mongoLedgerRepository) Balance(ctx context.Context, filter LedgerFilter) (int64, error) {
+	entryMatch := bson.M{"postings.account": filter.Account}
+	if !filter.TaskID.IsZero() {
+		entryMatch["task_id"] = filter.TaskID
+	}
+	if !filter.ClientID.IsZero() {
+		entryMatch["client_id"] = filter.ClientID
+	}
+	if !filter.FreelancerID.IsZero() {
+		entryMatch["freelancer_id"] = filter.FreelancerID
+	}
+	postingMatch := bson.M{"postings.account": filter.Account}
+	if !filter.OwnerID.IsZero() {
+		postingMatch["postings.owner_id"] = filter.OwnerID
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: entryMatch}},
+		{{Key: "$unwind", Value: "$postings"}},
+		{{Key: "$match", Value: postingMatch}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "balance": bson.M{"$sum": "$postings.amount"}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, mongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var result struct {
+		Balance int64 `bson:"balance"`
+	}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&result); err != nil {
+			return 0, err
+		}
+	}
+	return result.Balance, cursor.Err()
+}
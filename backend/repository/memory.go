@@ -0,0 +1,36 @@
+package repository
+
+// NewMemoryRepositories returns in-memory implementations of every repository.
+// They hold no external state and are intended for tests and local tooling.
+func NewMemoryRepositories() *Repositories {
+	users := NewMemoryUserRepository()
+	tasks := NewMemoryTaskRepository()
+	return &Repositories{
+		Users:                   users,
+		Tasks:                   tasks,
+		Bids:                    NewMemoryBidRepository(users, tasks),
+		Reviews:                 NewMemoryReviewRepository(),
+		Payments:                NewMemoryPaymentRepository(),
+		Milestones:              NewMemoryMilestoneRepository(),
+		Sessions:                NewMemorySessionRepository(),
+		PasswordResets:          NewMemoryPasswordResetRepository(),
+		AdminActions:            NewMemoryAdminActionRepository(),
+		Ledger:                  NewMemoryLedgerRepository(),
+		PaymentEvents:           NewMemoryPaymentEventRepository(),
+		IdempotencyKeys:         NewMemoryIdempotencyKeyRepository(),
+		Conversations:           NewMemoryConversationRepository(),
+		Messages:                NewMemoryMessageRepository(),
+		Notifications:           NewMemoryNotificationRepository(),
+		NotificationPreferences: NewMemoryNotificationPreferenceRepository(),
+		Jobs:                    NewMemoryJobRepository(),
+		Transactor:              NewMemoryTransactor(),
+	}
+}
+
+// cloneStrings copies a slice so stored documents never alias caller memory.
+func cloneStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append([]string(nil), values...)
+}
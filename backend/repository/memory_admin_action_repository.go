@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryAdminActionRepository struct {
+	mu      sync.RWMutex
+	actions []models.AdminAction
+}
+
+func NewMemoryAdminActionRepository() AdminActionRepository {
+	return &memoryAdminActionRepository{}
+}
+
+func (r *memoryAdminActionRepository) Create(ctx context.Context, action *models.AdminAction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if action.ID.IsZero() {
+		action.ID = primitive.NewObjectID()
+	}
+	r.actions = append(r.actions, *action)
+	return nil
+}
+
+func (r *memoryAdminActionRepository) Find(ctx context.Context, filter AdminActionFilter, page pagination.Params) (*pagination.Page[models.AdminAction], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	actions := []models.AdminAction{}
+	for _, action := range r.actions {
+		if filter.TargetType != "" && action.TargetType != filter.TargetType {
+			continue
+		}
+		if !filter.TargetID.IsZero() && action.TargetID != filter.TargetID {
+			continue
+		}
+		actions = append(actions, action)
+	}
+
+	sort.Slice(actions, func(i, j int) bool {
+		return lessByCreated(adminActionCursor(actions[i]), adminActionCursor(actions[j]), true)
+	})
+	return memoryPage(actions, page, true, adminActionCursor), nil
+}
@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryBidRepository struct {
+	mu    sync.RWMutex
+	bids  map[primitive.ObjectID]models.Bid
+	users UserRepository // joined by Compare
+	tasks TaskRepository // joined by Compare
+}
+
+// NewMemoryBidRepository returns a BidRepository whose Compare reads users
+// and tasks in place of the lookups the Mongo pipeline runs.
+func NewMemoryBidRepository(users UserRepository, tasks TaskRepository) BidRepository {
+	return &memoryBidRepository{bids: make(map[primitive.ObjectID]models.Bid), users: users, tasks: tasks}
+}
+
+func (r *memoryBidRepository) Create(ctx context.Context, bid *models.Bid) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if bid.ID.IsZero() {
+		bid.ID = primitive.NewObjectID()
+	}
+	if _, exists := r.bids[bid.ID]; exists {
+		return ErrDuplicate
+	}
+	r.bids[bid.ID] = copyBid(*bid)
+	return nil
+}
+
+func (r *memoryBidRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Bid, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bid, ok := r.bids[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	bid = copyBid(bid)
+	return &bid, nil
+}
+
+func (r *memoryBidRepository) FindByTask(ctx context.Context, taskID primitive.ObjectID, filter BidFilter, page pagination.Params) (*pagination.Page[models.Bid], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bids := []models.Bid{}
+	for _, bid := range r.bids {
+		if bid.TaskID != taskID {
+			continue
+		}
+		switch filter.View {
+		case BidViewActive:
+			if bid.Archived {
+				continue
+			}
+		case BidViewShortlisted:
+			if !bid.Shortlisted {
+				continue
+			}
+		case BidViewArchived:
+			if !bid.Archived {
+				continue
+			}
+		}
+		bids = append(bids, copyBid(bid))
+	}
+
+	sort.Slice(bids, func(i, j int) bool {
+		return lessByCreated(bidCursor(bids[i]), bidCursor(bids[j]), false)
+	})
+	return memoryPage(bids, page, false, bidCursor), nil
+}
+
+func (r *memoryBidRepository) FindByTaskAndFreelancer(ctx context.Context, taskID, freelancerID primitive.ObjectID) (*models.Bid, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, bid := range r.bids {
+		if bid.TaskID == taskID && bid.FreelancerID == freelancerID {
+			bid = copyBid(bid)
+			return &bid, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryBidRepository) FindByTaskAndStatus(ctx context.Context, taskID primitive.ObjectID, statuses ...string) ([]models.Bid, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bids := []models.Bid{}
+	for _, bid := range r.bids {
+		if bid.TaskID == taskID && slices.Contains(statuses, bid.Status) {
+			bids = append(bids, copyBid(bid))
+		}
+	}
+
+	sort.Slice(bids, func(i, j int) bool {
+		return lessByCreated(bidCursor(bids[i]), bidCursor(bids[j]), false)
+	})
+	return bids, nil
+}
+
+func (r *memoryBidRepository) FindOpenBidTaskIDs(ctx context.Context) ([]primitive.ObjectID, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[primitive.ObjectID]bool)
+	taskIDs := []primitive.ObjectID{}
+	for _, bid := range r.bids {
+		if slices.Contains(models.OpenBidStatuses, bid.Status) && !seen[bid.TaskID] {
+			seen[bid.TaskID] = true
+			taskIDs = append(taskIDs, bid.TaskID)
+		}
+	}
+	return taskIDs, nil
+}
+
+func (r *memoryBidRepository) FindBidTaskIDs(ctx context.Context, freelancerID primitive.ObjectID, taskIDs []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bidTaskIDs := []primitive.ObjectID{}
+	for _, bid := range r.bids {
+		if bid.FreelancerID == freelancerID && slices.Contains(taskIDs, bid.TaskID) && !slices.Contains(bidTaskIDs, bid.TaskID) {
+			bidTaskIDs = append(bidTaskIDs, bid.TaskID)
+		}
+	}
+	return bidTaskIDs, nil
+}
+
+func (r *memoryBidRepository) Update(ctx context.Context, bid *models.Bid) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.bids[bid.ID]; !ok {
+		return ErrNotFound
+	}
+	r.bids[bid.ID] = copyBid(*bid)
+	return nil
+}
+
+func (r *memoryBidRepository) UpdateIfStatus(ctx context.Context, bid *models.Bid, expectedStatus string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.bids[bid.ID]
+	if !ok || stored.Status != expectedStatus {
+		return ErrConflict
+	}
+	r.bids[bid.ID] = copyBid(*bid)
+	return nil
+}
+
+func (r *memoryBidRepository) UpdateIfUnchanged(ctx context.Context, bid *models.Bid, lastUpdatedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.bids[bid.ID]
+	if !ok || !stored.UpdatedAt.Equal(lastUpdatedAt) {
+		return ErrConflict
+	}
+	r.bids[bid.ID] = copyBid(*bid)
+	return nil
+}
+
+func copyBid(bid models.Bid) models.Bid {
+	bid.Milestones = append([]models.ProposedMilestone(nil), bid.Milestones...)
+	bid.Negotiation = append([]models.BidRevision(nil), bid.Negotiation...)
+	return bid
+}
+
+func (r *memoryBidRepository) OpenBidStats(ctx context.Context, taskID primitive.ObjectID) (BidStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := BidStats{}
+	for _, bid := range r.bids {
+		if bid.TaskID != taskID || !slices.Contains(models.OpenBidStatuses, bid.Status) {
+			continue
+		}
+		if stats.Count == 0 || bid.Amount < stats.LowestAmount {
+			stats.LowestAmount = bid.Amount
+		}
+		stats.Count++
+	}
+	return stats, nil
+}
+
+func (r *memoryBidRepository) Compare(ctx context.Context, taskID primitive.ObjectID, bidIDs []primitive.ObjectID, requiredSkills []string) ([]BidComparison, error) {
+	r.mu.RLock()
+	comparisons := []BidComparison{}
+	for _, id := range bidIDs {
+		if bid, ok := r.bids[id]; ok && bid.TaskID == taskID {
+			comparisons = append(comparisons, BidComparison{Bid: copyBid(bid)})
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(comparisons, func(i, j int) bool {
+		return lessByCreated(bidCursor(comparisons[i].Bid), bidCursor(comparisons[j].Bid), false)
+	})
+
+	required := models.NormalizeSkills(requiredSkills)
+	for i := range comparisons {
+		comparison := &comparisons[i]
+		comparison.MatchedSkills = []string{}
+		user, err := r.users.FindByID(ctx, comparison.Bid.FreelancerID)
+		if err == nil {
+			comparison.Freelancer = ComparedFreelancer{
+				ID:           user.ID,
+				FirstName:    user.FirstName,
+				LastName:     user.LastName,
+				ProfileImage: user.ProfileImage,
+				Skills:       cloneStrings(user.Skills),
+				Rating:       user.Rating,
+			}
+			for _, skill := range models.NormalizeSkills(user.Skills) {
+				if slices.Contains(required, skill) {
+					comparison.MatchedSkills = append(comparison.MatchedSkills, skill)
+				}
+			}
+			slices.Sort(comparison.MatchedSkills)
+		} else if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+
+		if comparison.CompletedTasks, comparison.OnTimeTasks, err = r.deliveryRecord(ctx, comparison.Bid.FreelancerID); err != nil {
+			return nil, err
+		}
+	}
+	return comparisons, nil
+}
+
+// deliveryRecord counts the freelancer's completed tasks and those of them
+// never flagged overdue, like the tasks lookup in the Mongo pipeline.
+func (r *memoryBidRepository) deliveryRecord(ctx context.Context, freelancerID primitive.ObjectID) (completed, onTime int, err error) {
+	filter := TaskFilter{Status: models.TaskStatusCompleted, FreelancerID: &freelancerID}
+	page := pagination.Params{Limit: pagination.MaxLimit}
+	for {
+		tasks, err := r.tasks.Find(ctx, filter, page)
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, task := range tasks.Items {
+			completed++
+			if task.OverdueAt == nil {
+				onTime++
+			}
+		}
+		if !tasks.HasMore {
+			return completed, onTime, nil
+		}
+		if page.After, err = pagination.Decode(tasks.NextCursor); err != nil {
+			return 0, 0, err
+		}
+	}
+}
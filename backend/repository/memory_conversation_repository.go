@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryConversationRepository struct {
+	mu            sync.RWMutex
+	conversations map[primitive.ObjectID]models.Conversation
+}
+
+func NewMemoryConversationRepository() ConversationRepository {
+	return &memoryConversationRepository{conversations: make(map[primitive.ObjectID]models.Conversation)}
+}
+
+func (r *memoryConversationRepository) Create(ctx context.Context, conversation *models.Conversation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Mirrors the unique (task_id, freelancer_id) index
+	for _, existing := range r.conversations {
+		if existing.TaskID == conversation.TaskID && existing.FreelancerID == conversation.FreelancerID {
+			return ErrDuplicate
+		}
+	}
+	if conversation.ID.IsZero() {
+		conversation.ID = primitive.NewObjectID()
+	}
+	r.conversations[conversation.ID] = copyConversation(*conversation)
+	return nil
+}
+
+func (r *memoryConversationRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Conversation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	conversation, ok := r.conversations[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &conversation, nil
+}
+
+func (r *memoryConversationRepository) FindByTaskAndFreelancer(ctx context.Context, taskID, freelancerID primitive.ObjectID) (*models.Conversation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, conversation := range r.conversations {
+		if conversation.TaskID == taskID && conversation.FreelancerID == freelancerID {
+			return &conversation, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryConversationRepository) FindByTask(ctx context.Context, taskID primitive.ObjectID, page pagination.Params) (*pagination.Page[models.Conversation], error) {
+	if page.After != nil && page.After.Time == nil {
+		return nil, pagination.ErrInvalidCursor
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	conversations := []models.Conversation{}
+	for _, conversation := range r.conversations {
+		if conversation.TaskID == taskID {
+			conversations = append(conversations, conversation)
+		}
+	}
+
+	// Positions by (updated_at, _id), compared like created_at cursors
+	byActivity := func(conversation models.Conversation) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: conversation.UpdatedAt, ID: conversation.ID}
+	}
+	sort.Slice(conversations, func(i, j int) bool {
+		return lessByCreated(byActivity(conversations[i]), byActivity(conversations[j]), true)
+	})
+
+	start := 0
+	if page.After != nil {
+		after := pagination.Cursor{CreatedAt: *page.After.Time, ID: page.After.ID}
+		for start < len(conversations) && !byActivity(conversations[start]).Before(after) {
+			start++
+		}
+	}
+	end := min(start+page.Limit+1, len(conversations))
+	return pagination.NewPage(conversations[start:end], page, conversationCursor), nil
+}
+
+func (r *memoryConversationRepository) Touch(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	conversation, ok := r.conversations[id]
+	if !ok {
+		return ErrNotFound
+	}
+	conversation.LastMessageAt = &at
+	conversation.UpdatedAt = at
+	r.conversations[id] = conversation
+	return nil
+}
+
+func copyConversation(conversation models.Conversation) models.Conversation {
+	conversation.Unread = 0
+	return conversation
+}
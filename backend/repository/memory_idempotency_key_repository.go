@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryIdempotencyKeyRepository struct {
+	mu      sync.RWMutex
+	records map[primitive.ObjectID]models.IdempotencyKey
+}
+
+func NewMemoryIdempotencyKeyRepository() IdempotencyKeyRepository {
+	return &memoryIdempotencyKeyRepository{records: make(map[primitive.ObjectID]models.IdempotencyKey)}
+}
+
+func (r *memoryIdempotencyKeyRepository) Create(ctx context.Context, record *models.IdempotencyKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, existing := range r.records {
+		if existing.UserID != record.UserID || existing.Key != record.Key {
+			continue
+		}
+		if existing.ExpiresAt.After(time.Now()) {
+			return ErrDuplicate
+		}
+		delete(r.records, id)
+	}
+	if record.ID.IsZero() {
+		record.ID = primitive.NewObjectID()
+	}
+	r.records[record.ID] = copyIdempotencyKey(*record)
+	return nil
+}
+
+func (r *memoryIdempotencyKeyRepository) Find(ctx context.Context, userID primitive.ObjectID, key string) (*models.IdempotencyKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, record := range r.records {
+		if record.UserID == userID && record.Key == key && record.ExpiresAt.After(time.Now()) {
+			copied := copyIdempotencyKey(record)
+			return &copied, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryIdempotencyKeyRepository) Update(ctx context.Context, record *models.IdempotencyKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.records[record.ID]; !ok {
+		return ErrNotFound
+	}
+	r.records[record.ID] = copyIdempotencyKey(*record)
+	return nil
+}
+
+func (r *memoryIdempotencyKeyRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.records, id)
+	return nil
+}
+
+func copyIdempotencyKey(record models.IdempotencyKey) models.IdempotencyKey {
+	if record.ResponseBody != nil {
+		record.ResponseBody = append([]byte(nil), record.ResponseBody...)
+	}
+	return record
+}
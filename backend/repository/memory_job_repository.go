@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryJobRepository struct {
+	mu   sync.RWMutex
+	jobs map[primitive.ObjectID]models.Job
+}
+
+func NewMemoryJobRepository() JobRepository {
+	return &memoryJobRepository{jobs: make(map[primitive.ObjectID]models.Job)}
+}
+
+func (r *memoryJobRepository) Create(ctx context.Context, job *models.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Mirrors the unique sparse index on unique_key.
+	if job.UniqueKey != "" {
+		for _, existing := range r.jobs {
+			if existing.UniqueKey == job.UniqueKey {
+				return ErrDuplicate
+			}
+		}
+	}
+
+	if job.ID.IsZero() {
+		job.ID = primitive.NewObjectID()
+	}
+	r.jobs[job.ID] = copyJob(*job)
+	return nil
+}
+
+func (r *memoryJobRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Job, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	job = copyJob(job)
+	return &job, nil
+}
+
+func (r *memoryJobRepository) Find(ctx context.Context, filter JobFilter, page pagination.Params) (*pagination.Page[models.Job], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	jobs := []models.Job{}
+	for _, job := range r.jobs {
+		if filter.Status != "" && job.Status != filter.Status {
+			continue
+		}
+		if filter.Type != "" && job.Type != filter.Type {
+			continue
+		}
+		jobs = append(jobs, copyJob(job))
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return lessByCreated(jobCursor(jobs[i]), jobCursor(jobs[j]), true)
+	})
+	return memoryPage(jobs, page, true, jobCursor), nil
+}
+
+func (r *memoryJobRepository) Lease(ctx context.Context, types []string, owner string, now, until time.Time) (*models.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wanted := make(map[string]bool, len(types))
+	for _, jobType := range types {
+		wanted[jobType] = true
+	}
+
+	var next *models.Job
+	for _, job := range r.jobs {
+		due := job.Status == models.JobQueued && !job.RunAt.After(now) ||
+			job.Status == models.JobRunning && job.LeasedUntil != nil && job.LeasedUntil.Before(now)
+		if !wanted[job.Type] || !due {
+			continue
+		}
+		if next == nil || job.RunAt.Before(next.RunAt) {
+			job := job
+			next = &job
+		}
+	}
+	if next == nil {
+		return nil, ErrNotFound
+	}
+
+	next.Status = models.JobRunning
+	next.LeaseOwner = owner
+	next.LeasedUntil = &until
+	next.Attempts++
+	next.UpdatedAt = now
+	r.jobs[next.ID] = *next
+
+	job := copyJob(*next)
+	return &job, nil
+}
+
+func (r *memoryJobRepository) Complete(ctx context.Context, id primitive.ObjectID, owner string, at, expiresAt time.Time) error {
+	return r.finishLease(id, owner, func(job *models.Job) {
+		job.Status = models.JobSucceeded
+		job.FinishedAt = &at
+		job.ExpiresAt = &expiresAt
+		job.UpdatedAt = at
+	})
+}
+
+func (r *memoryJobRepository) Release(ctx context.Context, id primitive.ObjectID, owner, lastError string, runAt, at time.Time) error {
+	return r.finishLease(id, owner, func(job *models.Job) {
+		job.Status = models.JobQueued
+		job.RunAt = runAt
+		job.LastError = lastError
+		job.UpdatedAt = at
+	})
+}
+
+func (r *memoryJobRepository) Bury(ctx context.Context, id primitive.ObjectID, owner, lastError string, at time.Time) error {
+	return r.finishLease(id, owner, func(job *models.Job) {
+		job.Status = models.JobDead
+		job.LastError = lastError
+		job.FinishedAt = &at
+		job.UpdatedAt = at
+	})
+}
+
+func (r *memoryJobRepository) finishLease(id primitive.ObjectID, owner string, apply func(*models.Job)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok || job.Status != models.JobRunning || job.LeaseOwner != owner {
+		return ErrConflict
+	}
+	apply(&job)
+	job.LeaseOwner = ""
+	job.LeasedUntil = nil
+	r.jobs[id] = job
+	return nil
+}
+
+func (r *memoryJobRepository) Requeue(ctx context.Context, id primitive.ObjectID, at time.Time) (*models.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if job.Status != models.JobDead {
+		return nil, ErrConflict
+	}
+
+	job.Status = models.JobQueued
+	job.Attempts = 0
+	job.RunAt = at
+	job.FinishedAt = nil
+	job.UpdatedAt = at
+	r.jobs[id] = job
+
+	job = copyJob(job)
+	return &job, nil
+}
+
+func copyJob(job models.Job) models.Job {
+	if job.Payload != nil {
+		payload := make(bson.M, len(job.Payload))
+		for key, value := range job.Payload {
+			payload[key] = value
+		}
+		job.Payload = payload
+	}
+	return job
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+)
+
+func TestMemoryJobLease(t *testing.T) {
+	ctx := context.Background()
+	jobs := NewMemoryJobRepository()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	later := &models.Job{Type: "email", Status: models.JobQueued, MaxAttempts: 3, RunAt: now.Add(-time.Minute)}
+	earlier := &models.Job{Type: "email", Status: models.JobQueued, MaxAttempts: 3, RunAt: now.Add(-time.Hour)}
+	future := &models.Job{Type: "email", Status: models.JobQueued, MaxAttempts: 3, RunAt: now.Add(time.Hour)}
+	other := &models.Job{Type: "scan", Status: models.JobQueued, MaxAttempts: 3, RunAt: now.Add(-2 * time.Hour)}
+	for _, job := range []*models.Job{later, earlier, future, other} {
+		if err := jobs.Create(ctx, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The earliest due job of a wanted type goes first.
+	leased, err := jobs.Lease(ctx, []string{"email"}, "a", now, now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leased.ID != earlier.ID || leased.Status != models.JobRunning || leased.Attempts != 1 || leased.LeaseOwner != "a" {
+		t.Fatalf("leased %+v, want the earliest due email job", leased)
+	}
+	if next, err := jobs.Lease(ctx, []string{"email"}, "b", now, now.Add(time.Minute)); err != nil || next.ID != later.ID {
+		t.Fatalf("second lease = %v %v, want the later job", next, err)
+	}
+	if _, err := jobs.Lease(ctx, []string{"email"}, "b", now, now.Add(time.Minute)); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("third lease = %v, want ErrNotFound while the rest is leased or not due", err)
+	}
+
+	// Once a's lease runs out, b takes the job over and a loses it.
+	expired := now.Add(2 * time.Minute)
+	reclaimed, err := jobs.Lease(ctx, []string{"email"}, "b", expired, expired.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reclaimed.ID != earlier.ID || reclaimed.Attempts != 2 || reclaimed.LeaseOwner != "b" {
+		t.Fatalf("reclaimed %+v, want the earlier job on its second attempt", reclaimed)
+	}
+	if err := jobs.Complete(ctx, earlier.ID, "a", expired, expired); !errors.Is(err, ErrConflict) {
+		t.Errorf("Complete by the expired owner = %v, want ErrConflict", err)
+	}
+	if err := jobs.Bury(ctx, earlier.ID, "b", "boom", expired); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only dead jobs can be requeued.
+	if _, err := jobs.Requeue(ctx, later.ID, expired); !errors.Is(err, ErrConflict) {
+		t.Errorf("Requeue of a running job = %v, want ErrConflict", err)
+	}
+	requeued, err := jobs.Requeue(ctx, earlier.ID, expired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requeued.Status != models.JobQueued || requeued.Attempts != 0 || requeued.LastError != "boom" {
+		t.Errorf("requeued %+v, want queued with no attempts and the last error kept", requeued)
+	}
+}
+
+func TestMemoryJobUniqueKey(t *testing.T) {
+	ctx := context.Background()
+	jobs := NewMemoryJobRepository()
+
+	if err := jobs.Create(ctx, &models.Job{Type: "email", UniqueKey: "k"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := jobs.Create(ctx, &models.Job{Type: "email", UniqueKey: "k"}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Create with a taken key = %v, want ErrDuplicate", err)
+	}
+	if err := jobs.Create(ctx, &models.Job{Type: "email"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := jobs.Create(ctx, &models.Job{Type: "email"}); err != nil {
+		t.Errorf("second Create without a key = %v", err)
+	}
+}
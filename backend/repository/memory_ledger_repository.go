@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryLedgerRepository struct {
+	mu      sync.RWMutex
+	entries []models.LedgerEntry
+}
+
+func NewMemoryLedgerRepository() LedgerRepository {
+	return &memoryLedgerRepository{}
+}
+
+func (r *memoryLedgerRepository) Post(ctx context.Context, entry *models.LedgerEntry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Mirrors the unique (payment_id, type) index
+	for _, existing := range r.entries {
+		if existing.PaymentID == entry.PaymentID && existing.Type == entry.Type {
+			return ErrDuplicate
+		}
+	}
+	if entry.ID.IsZero() {
+		entry.ID = primitive.NewObjectID()
+	}
+	r.entries = append(r.entries, copyLedgerEntry(*entry))
+	return nil
+}
+
+func (r *memoryLedgerRepository) FindByTask(ctx context.Context, taskID primitive.ObjectID) ([]models.LedgerEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := []models.LedgerEntry{}
+	for _, entry := range r.entries {
+		if entry.TaskID == taskID {
+			entries = append(entries, copyLedgerEntry(entry))
+		}
+	}
+	return entries, nil
+}
+
+func (r *memoryLedgerRepository) Balance(ctx context.Context, filter LedgerFilter) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var balance int64
+	for _, entry := range r.entries {
+		if !filter.TaskID.IsZero() && entry.TaskID != filter.TaskID {
+			continue
+		}
+		if !filter.ClientID.IsZero() && entry.ClientID != filter.ClientID {
+			continue
+		}
+		if !filter.FreelancerID.IsZero() && entry.FreelancerID != filter.FreelancerID {
+			continue
+		}
+		for _, posting := range entry.Postings {
+			if posting.Account != filter.Account {
+				continue
+			}
+			if !filter.OwnerID.IsZero() && (posting.OwnerID == nil || *posting.OwnerID != filter.OwnerID) {
+				continue
+			}
+			balance += posting.Amount
+		}
+	}
+	return balance, nil
+}
+
+func copyLedgerEntry(entry models.LedgerEntry) models.LedgerEntry {
+	entry.Postings = append([]models.LedgerPosting(nil), entry.Postings...)
+	return entry
+}
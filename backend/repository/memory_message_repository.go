@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryMessageRepository struct {
+	mu       sync.RWMutex
+	messages map[primitive.ObjectID]models.Message
+}
+
+func NewMemoryMessageRepository() MessageRepository {
+	return &memoryMessageRepository{messages: make(map[primitive.ObjectID]models.Message)}
+}
+
+func (r *memoryMessageRepository) Create(ctx context.Context, message *models.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if message.ID.IsZero() {
+		message.ID = primitive.NewObjectID()
+	}
+	if _, exists := r.messages[message.ID]; exists {
+		return ErrDuplicate
+	}
+	r.messages[message.ID] = *message
+	return nil
+}
+
+func (r *memoryMessageRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	message, ok := r.messages[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &message, nil
+}
+
+func (r *memoryMessageRepository) FindByConversation(ctx context.Context, conversationID primitive.ObjectID, page pagination.Params) (*pagination.Page[models.Message], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	messages := []models.Message{}
+	for _, message := range r.messages {
+		if message.ConversationID == conversationID {
+			messages = append(messages, message)
+		}
+	}
+
+	sort.Slice(messages, func(i, j int) bool {
+		return lessByCreated(messageCursor(messages[i]), messageCursor(messages[j]), true)
+	})
+	return memoryPage(messages, page, true, messageCursor), nil
+}
+
+func (r *memoryMessageRepository) MarkRead(ctx context.Context, conversationID, readerID primitive.ObjectID, through, readAt time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var marked int64
+	for id, message := range r.messages {
+		if message.ConversationID != conversationID || message.SenderID == readerID || message.ReadAt != nil || message.CreatedAt.After(through) {
+			continue
+		}
+		at := readAt
+		message.ReadAt = &at
+		r.messages[id] = message
+		marked++
+	}
+	return marked, nil
+}
+
+func (r *memoryMessageRepository) CountUnread(ctx context.Context, conversationID, readerID primitive.ObjectID) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, message := range r.messages {
+		if message.ConversationID == conversationID && message.SenderID != readerID && message.ReadAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryMilestoneRepository struct {
+	mu         sync.RWMutex
+	milestones map[primitive.ObjectID]models.Milestone
+}
+
+func NewMemoryMilestoneRepository() MilestoneRepository {
+	return &memoryMilestoneRepository{milestones: make(map[primitive.ObjectID]models.Milestone)}
+}
+
+func (r *memoryMilestoneRepository) Create(ctx context.Context, milestone *models.Milestone) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if milestone.ID.IsZero() {
+		milestone.ID = primitive.NewObjectID()
+	}
+	if _, exists := r.milestones[milestone.ID]; exists {
+		return ErrDuplicate
+	}
+	r.milestones[milestone.ID] = copyMilestone(*milestone)
+	return nil
+}
+
+func (r *memoryMilestoneRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Milestone, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	milestone, ok := r.milestones[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := copyMilestone(milestone)
+	return &copied, nil
+}
+
+func (r *memoryMilestoneRepository) FindByTask(ctx context.Context, taskID primitive.ObjectID) ([]models.Milestone, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	milestones := []models.Milestone{}
+	for _, milestone := range r.milestones {
+		if milestone.TaskID == taskID {
+			milestones = append(milestones, copyMilestone(milestone))
+		}
+	}
+
+	sort.Slice(milestones, func(i, j int) bool {
+		if !milestones[i].DueDate.Equal(milestones[j].DueDate) {
+			return milestones[i].DueDate.Before(milestones[j].DueDate)
+		}
+		return milestones[i].ID.Hex() < milestones[j].ID.Hex()
+	})
+	return milestones, nil
+}
+
+func (r *memoryMilestoneRepository) Update(ctx context.Context, milestone *models.Milestone) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.milestones[milestone.ID]; !ok {
+		return ErrNotFound
+	}
+	r.milestones[milestone.ID] = copyMilestone(*milestone)
+	return nil
+}
+
+func (r *memoryMilestoneRepository) UpdateIfStatus(ctx context.Context, milestone *models.Milestone, expectedStatus string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.milestones[milestone.ID]
+	if !ok || stored.Status != expectedStatus {
+		return ErrConflict
+	}
+	r.milestones[milestone.ID] = copyMilestone(*milestone)
+	return nil
+}
+
+func (r *memoryMilestoneRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.milestones[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.milestones, id)
+	return nil
+}
+
+func (r *memoryMilestoneRepository) DeleteByTask(ctx context.Context, taskID primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, milestone := range r.milestones {
+		if milestone.TaskID == taskID {
+			delete(r.milestones, id)
+		}
+	}
+	return nil
+}
+
+func copyMilestone(milestone models.Milestone) models.Milestone {
+	milestone.StatusHistory = append([]models.TaskStatusChange(nil), milestone.StatusHistory...)
+	return milestone
+}
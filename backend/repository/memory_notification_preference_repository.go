@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryNotificationPreferenceRepository struct {
+	mu          sync.RWMutex
+	preferences map[primitive.ObjectID]models.NotificationPreference // keyed by user
+}
+
+func NewMemoryNotificationPreferenceRepository() NotificationPreferenceRepository {
+	return &memoryNotificationPreferenceRepository{preferences: make(map[primitive.ObjectID]models.NotificationPreference)}
+}
+
+func (r *memoryNotificationPreferenceRepository) FindByUser(ctx context.Context, userID primitive.ObjectID) (*models.NotificationPreference, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	preference, ok := r.preferences[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &preference, nil
+}
+
+func (r *memoryNotificationPreferenceRepository) Save(ctx context.Context, preference *models.NotificationPreference) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if preference.ID.IsZero() {
+		preference.ID = primitive.NewObjectID()
+	}
+	r.preferences[preference.UserID] = *preference
+	return nil
+}
+
+func (r *memoryNotificationPreferenceRepository) FindDigestDue(ctx context.Context, cutoff time.Time) ([]models.NotificationPreference, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	preferences := []models.NotificationPreference{}
+	for _, preference := range r.preferences {
+		if digestDue(preference, cutoff) {
+			preferences = append(preferences, preference)
+		}
+	}
+	return preferences, nil
+}
+
+func (r *memoryNotificationPreferenceRepository) ClaimDigest(ctx context.Context, userID primitive.ObjectID, cutoff, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	preference, ok := r.preferences[userID]
+	if !ok || !digestDue(preference, cutoff) {
+		return ErrConflict
+	}
+	preference.LastDigestAt = &at
+	r.preferences[userID] = preference
+	return nil
+}
+
+func digestDue(preference models.NotificationPreference, cutoff time.Time) bool {
+	return preference.Email == models.EmailDailyDigest && (preference.LastDigestAt == nil || preference.LastDigestAt.Before(cutoff))
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryNotificationRepository struct {
+	mu            sync.RWMutex
+	notifications map[primitive.ObjectID]models.Notification
+}
+
+func NewMemoryNotificationRepository() NotificationRepository {
+	return &memoryNotificationRepository{notifications: make(map[primitive.ObjectID]models.Notification)}
+}
+
+func (r *memoryNotificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if notification.ID.IsZero() {
+		notification.ID = primitive.NewObjectID()
+	}
+	if _, exists := r.notifications[notification.ID]; exists {
+		return ErrDuplicate
+	}
+	r.notifications[notification.ID] = *notification
+	return nil
+}
+
+func (r *memoryNotificationRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Notification, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	notification, ok := r.notifications[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &notification, nil
+}
+
+func (r *memoryNotificationRepository) Find(ctx context.Context, filter NotificationFilter, page pagination.Params) (*pagination.Page[models.Notification], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	notifications := []models.Notification{}
+	for _, notification := range r.notifications {
+		if notification.UserID != filter.UserID || filter.UnreadOnly && notification.IsRead() {
+			continue
+		}
+		notifications = append(notifications, notification)
+	}
+
+	sort.Slice(notifications, func(i, j int) bool {
+		return lessByCreated(notificationCursor(notifications[i]), notificationCursor(notifications[j]), true)
+	})
+	return memoryPage(notifications, page, true, notificationCursor), nil
+}
+
+func (r *memoryNotificationRepository) MarkRead(ctx context.Context, userID, id primitive.ObjectID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	notification, ok := r.notifications[id]
+	if !ok || notification.UserID != userID {
+		return ErrNotFound
+	}
+	if !notification.IsRead() {
+		notification.ReadAt = &at
+		r.notifications[id] = notification
+	}
+	return nil
+}
+
+func (r *memoryNotificationRepository) MarkAllRead(ctx context.Context, userID primitive.ObjectID, at time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var marked int64
+	for id, notification := range r.notifications {
+		if notification.UserID != userID || notification.IsRead() {
+			continue
+		}
+		readAt := at
+		notification.ReadAt = &readAt
+		r.notifications[id] = notification
+		marked++
+	}
+	return marked, nil
+}
+
+func (r *memoryNotificationRepository) CountUnread(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, notification := range r.notifications {
+		if notification.UserID == userID && !notification.IsRead() {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryNotificationRepository) FindDigestPending(ctx context.Context, userID primitive.ObjectID) ([]models.Notification, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	notifications := []models.Notification{}
+	for _, notification := range r.notifications {
+		if notification.UserID == userID && notification.DigestPending {
+			notifications = append(notifications, notification)
+		}
+	}
+
+	sort.Slice(notifications, func(i, j int) bool {
+		return lessByCreated(notificationCursor(notifications[i]), notificationCursor(notifications[j]), false)
+	})
+	return notifications, nil
+}
+
+func (r *memoryNotificationRepository) ClearDigestPending(ctx context.Context, userID primitive.ObjectID, through time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, notification := range r.notifications {
+		if notification.UserID == userID && notification.DigestPending && !notification.CreatedAt.After(through) {
+			notification.DigestPending = false
+			r.notifications[id] = notification
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryPasswordResetRepository struct {
+	mu     sync.Mutex
+	resets map[primitive.ObjectID]models.PasswordReset
+}
+
+func NewMemoryPasswordResetRepository() PasswordResetRepository {
+	return &memoryPasswordResetRepository{resets: make(map[primitive.ObjectID]models.PasswordReset)}
+}
+
+func (r *memoryPasswordResetRepository) Create(ctx context.Context, reset *models.PasswordReset) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if reset.ID.IsZero() {
+		reset.ID = primitive.NewObjectID()
+	}
+	for _, existing := range r.resets {
+		if existing.ID == reset.ID || existing.TokenHash == reset.TokenHash {
+			return ErrDuplicate
+		}
+	}
+	r.resets[reset.ID] = *reset
+	return nil
+}
+
+func (r *memoryPasswordResetRepository) Consume(ctx context.Context, tokenHash string, now time.Time) (*models.PasswordReset, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, reset := range r.resets {
+		if reset.TokenHash != tokenHash || reset.UsedAt != nil || !now.Before(reset.ExpiresAt) {
+			continue
+		}
+		usedAt := now
+		reset.UsedAt = &usedAt
+		r.resets[id] = reset
+		return &reset, nil
+	}
+	return nil, ErrNotFound
+}
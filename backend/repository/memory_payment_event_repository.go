@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryPaymentEventRepository struct {
+	mu     sync.RWMutex
+	events map[primitive.ObjectID]models.PaymentEvent
+}
+
+func NewMemoryPaymentEventRepository() PaymentEventRepository {
+	return &memoryPaymentEventRepository{events: make(map[primitive.ObjectID]models.PaymentEvent)}
+}
+
+func (r *memoryPaymentEventRepository) Create(ctx context.Context, event *models.PaymentEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Mirrors the unique (provider, event_id) index
+	for _, existing := range r.events {
+		if existing.Provider == event.Provider && existing.EventID == event.EventID {
+			return ErrDuplicate
+		}
+	}
+	if event.ID.IsZero() {
+		event.ID = primitive.NewObjectID()
+	}
+	r.events[event.ID] = copyPaymentEvent(*event)
+	return nil
+}
+
+func (r *memoryPaymentEventRepository) FindByEventID(ctx context.Context, provider, eventID string) (*models.PaymentEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, event := range r.events {
+		if event.Provider == provider && event.EventID == eventID {
+			copied := copyPaymentEvent(event)
+			return &copied, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryPaymentEventRepository) Update(ctx context.Context, event *models.PaymentEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.events[event.ID]; !ok {
+		return ErrNotFound
+	}
+	r.events[event.ID] = copyPaymentEvent(*event)
+	return nil
+}
+
+func copyPaymentEvent(event models.PaymentEvent) models.PaymentEvent {
+	if event.PaymentID != nil {
+		id := *event.PaymentID
+		event.PaymentID = &id
+	}
+	if event.ProcessedAt != nil {
+		at := *event.ProcessedAt
+		event.ProcessedAt = &at
+	}
+	return event
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryPaymentRepository struct {
+	mu       sync.RWMutex
+	payments map[primitive.ObjectID]models.Payment
+}
+
+func NewMemoryPaymentRepository() PaymentRepository {
+	return &memoryPaymentRepository{payments: make(map[primitive.ObjectID]models.Payment)}
+}
+
+func (r *memoryPaymentRepository) Create(ctx context.Context, payment *models.Payment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if payment.ID.IsZero() {
+		payment.ID = primitive.NewObjectID()
+	}
+	if _, exists := r.payments[payment.ID]; exists {
+		return ErrDuplicate
+	}
+	r.payments[payment.ID] = *payment
+	return nil
+}
+
+func (r *memoryPaymentRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Payment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	payment, ok := r.payments[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &payment, nil
+}
+
+func (r *memoryPaymentRepository) FindByTransactionID(ctx context.Context, transactionID string) (*models.Payment, error) {
+	return r.findOne(func(payment models.Payment) bool { return payment.TransactionID == transactionID })
+}
+
+func (r *memoryPaymentRepository) FindByPayoutID(ctx context.Context, payoutID string) (*models.Payment, error) {
+	return r.findOne(func(payment models.Payment) bool { return payment.PayoutID == payoutID })
+}
+
+func (r *memoryPaymentRepository) findOne(match func(models.Payment) bool) (*models.Payment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, payment := range r.payments {
+		if match(payment) {
+			return &payment, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryPaymentRepository) FindByTask(ctx context.Context, taskID primitive.ObjectID, page pagination.Params) (*pagination.Page[models.Payment], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	payments := []models.Payment{}
+	for _, payment := range r.payments {
+		if payment.TaskID == taskID {
+			payments = append(payments, payment)
+		}
+	}
+
+	sort.Slice(payments, func(i, j int) bool {
+		return lessByCreated(paymentCursor(payments[i]), paymentCursor(payments[j]), true)
+	})
+	return memoryPage(payments, page, true, paymentCursor), nil
+}
+
+func (r *memoryPaymentRepository) FindByTaskAndStatus(ctx context.Context, taskID primitive.ObjectID, status string) ([]models.Payment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	payments := []models.Payment{}
+	for _, payment := range r.payments {
+		if payment.TaskID == taskID && payment.Status == status {
+			payments = append(payments, payment)
+		}
+	}
+
+	sort.Slice(payments, func(i, j int) bool {
+		return lessByCreated(paymentCursor(payments[i]), paymentCursor(payments[j]), false)
+	})
+	return payments, nil
+}
+
+func (r *memoryPaymentRepository) Update(ctx context.Context, payment *models.Payment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.payments[payment.ID]; !ok {
+		return ErrNotFound
+	}
+	r.payments[payment.ID] = *payment
+	return nil
+}
+
+func (r *memoryPaymentRepository) UpdateIfStatus(ctx context.Context, payment *models.Payment, expectedStatus string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.payments[payment.ID]
+	if !ok || stored.Status != expectedStatus {
+		return ErrConflict
+	}
+	r.payments[payment.ID] = *payment
+	return nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryReviewRepository struct {
+	mu      sync.RWMutex
+	reviews map[primitive.ObjectID]models.Review
+}
+
+func NewMemoryReviewRepository() ReviewRepository {
+	return &memoryReviewRepository{reviews: make(map[primitive.ObjectID]models.Review)}
+}
+
+func (r *memoryReviewRepository) Create(ctx context.Context, review *models.Review) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if review.ID.IsZero() {
+		review.ID = primitive.NewObjectID()
+	}
+	if _, exists := r.reviews[review.ID]; exists {
+		return ErrDuplicate
+	}
+	r.reviews[review.ID] = *review
+	return nil
+}
+
+func (r *memoryReviewRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Review, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	review, ok := r.reviews[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &review, nil
+}
+
+func (r *memoryReviewRepository) FindByReviewedUser(ctx context.Context, userID primitive.ObjectID, page pagination.Params) (*pagination.Page[models.Review], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reviews := []models.Review{}
+	for _, review := range r.reviews {
+		if review.ReviewedUserID == userID && !review.IsHidden {
+			reviews = append(reviews, review)
+		}
+	}
+
+	sort.Slice(reviews, func(i, j int) bool {
+		return lessByCreated(reviewCursor(reviews[i]), reviewCursor(reviews[j]), true)
+	})
+	return memoryPage(reviews, page, true, reviewCursor), nil
+}
+
+func (r *memoryReviewRepository) AverageRating(ctx context.Context, userID primitive.ObjectID) (float64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	total, count := 0, 0
+	for _, review := range r.reviews {
+		if review.ReviewedUserID == userID && !review.IsHidden {
+			total += review.Rating
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	return float64(total) / float64(count), nil
+}
+
+func (r *memoryReviewRepository) Update(ctx context.Context, review *models.Review) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.reviews[review.ID]; !ok {
+		return ErrNotFound
+	}
+	r.reviews[review.ID] = *review
+	return nil
+}
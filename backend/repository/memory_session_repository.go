@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memorySessionRepository struct {
+	mu       sync.RWMutex
+	sessions map[primitive.ObjectID]models.Session
+}
+
+func NewMemorySessionRepository() SessionRepository {
+	return &memorySessionRepository{sessions: make(map[primitive.ObjectID]models.Session)}
+}
+
+func (r *memorySessionRepository) Create(ctx context.Context, session *models.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+	if _, exists := r.sessions[session.ID]; exists {
+		return ErrDuplicate
+	}
+	r.sessions[session.ID] = copySession(*session)
+	return nil
+}
+
+func (r *memorySessionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	session = copySession(session)
+	return &session, nil
+}
+
+func (r *memorySessionRepository) FindByTokenHash(ctx context.Context, hash string) (*models.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, session := range r.sessions {
+		if session.RefreshTokenHash == hash {
+			session = copySession(session)
+			return &session, nil
+		}
+		for _, previous := range session.PreviousTokenHashes {
+			if previous == hash {
+				session = copySession(session)
+				return &session, nil
+			}
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memorySessionRepository) Rotate(ctx context.Context, id primitive.ObjectID, oldHash, newHash string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok || session.RefreshTokenHash != oldHash || session.RevokedAt != nil {
+		return ErrNotFound
+	}
+	session.PreviousTokenHashes = append(cloneStrings(session.PreviousTokenHashes), oldHash)
+	session.RefreshTokenHash = newHash
+	session.ExpiresAt = expiresAt
+	session.UpdatedAt = time.Now()
+	r.sessions[id] = session
+	return nil
+}
+
+func (r *memorySessionRepository) Revoke(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok || session.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	session.UpdatedAt = now
+	r.sessions[id] = session
+	return nil
+}
+
+func (r *memorySessionRepository) RevokeAllForUser(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return r.revokeWhere(func(session models.Session) bool {
+		return session.UserID == userID
+	}), nil
+}
+
+func (r *memorySessionRepository) RevokeOthers(ctx context.Context, userID, keep primitive.ObjectID) (int64, error) {
+	return r.revokeWhere(func(session models.Session) bool {
+		return session.UserID == userID && session.ID != keep
+	}), nil
+}
+
+// revokeWhere revokes every active session matching the predicate.
+func (r *memorySessionRepository) revokeWhere(match func(models.Session) bool) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var revoked int64
+	for id, session := range r.sessions {
+		if session.RevokedAt != nil || !match(session) {
+			continue
+		}
+		revokedAt := now
+		session.RevokedAt = &revokedAt
+		session.UpdatedAt = now
+		r.sessions[id] = session
+		revoked++
+	}
+	return revoked
+}
+
+func copySession(session models.Session) models.Session {
+	session.PreviousTokenHashes = cloneStrings(session.PreviousTokenHashes)
+	if session.RevokedAt != nil {
+		revokedAt := *session.RevokedAt
+		session.RevokedAt = &revokedAt
+	}
+	return session
+}
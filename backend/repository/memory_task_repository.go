@@ -0,0 +1,413 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryTaskRepository struct {
+	mu    sync.RWMutex
+	tasks map[primitive.ObjectID]models.Task
+}
+
+func NewMemoryTaskRepository() TaskRepository {
+	return &memoryTaskRepository{tasks: make(map[primitive.ObjectID]models.Task)}
+}
+
+func (r *memoryTaskRepository) Create(ctx context.Context, task *models.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if task.ID.IsZero() {
+		task.ID = primitive.NewObjectID()
+	}
+	if _, exists := r.tasks[task.ID]; exists {
+		return ErrDuplicate
+	}
+	r.tasks[task.ID] = copyTask(*task)
+	return nil
+}
+
+func (r *memoryTaskRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, ok := r.tasks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	task = copyTask(task)
+	return &task, nil
+}
+
+func (r *memoryTaskRepository) Find(ctx context.Context, filter TaskFilter, page pagination.Params) (*pagination.Page[models.Task], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := []models.Task{}
+	for _, task := range r.tasks {
+		if filter.Status != "" && task.Status != filter.Status {
+			continue
+		}
+		if filter.Category != "" && task.Category != filter.Category {
+			continue
+		}
+		if filter.FreelancerID != nil && (task.FreelancerID == nil || *task.FreelancerID != *filter.FreelancerID) {
+			continue
+		}
+		tasks = append(tasks, copyTask(task))
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return lessByCreated(taskCursor(tasks[i]), taskCursor(tasks[j]), true)
+	})
+	return memoryPage(tasks, page, true, taskCursor), nil
+}
+
+// Search approximates the Mongo text index with case-insensitive word
+// matching (no stemming): a task matches when any query word appears in its
+// title or description, and title hits weigh more towards relevance.
+func (r *memoryTaskRepository) Search(ctx context.Context, search TaskSearch) (*TaskSearchResult, error) {
+	keys := taskSearchKeys(search)
+	if search.Page.After != nil {
+		if err := checkTaskSearchCursor(keys, *search.Page.After); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	terms := textWords(search.Query)
+	scores := make(map[primitive.ObjectID]float64)
+	categories := make(map[string]int64)
+	skills := make(map[string]int64)
+
+	tasks := []models.Task{}
+	for _, task := range r.tasks {
+		if len(terms) > 0 {
+			score := textScore(task, terms)
+			if score == 0 {
+				continue
+			}
+			scores[task.ID] = score
+		}
+		if search.Status != "" && task.Status != search.Status {
+			continue
+		}
+		if search.Category != "" && task.Category != search.Category {
+			continue
+		}
+		if len(search.Skills) > 0 && !matchSkills(task.RequiredSkills, search.Skills, search.MatchAllSkills) {
+			continue
+		}
+		if search.MinBudget != nil && task.Budget < *search.MinBudget {
+			continue
+		}
+		if search.MaxBudget != nil && task.Budget > *search.MaxBudget {
+			continue
+		}
+		if search.DeadlineAfter != nil && task.Deadline.Before(*search.DeadlineAfter) {
+			continue
+		}
+		if search.DeadlineBefore != nil && task.Deadline.After(*search.DeadlineBefore) {
+			continue
+		}
+
+		if task.Category != "" {
+			categories[task.Category]++
+		}
+		for _, skill := range task.RequiredSkills {
+			skills[skill]++
+		}
+		tasks = append(tasks, copyTask(task))
+	}
+
+	hits := make([]scoredTask, len(tasks))
+	for i, task := range tasks {
+		hits[i] = scoredTask{Task: task, Score: scores[task.ID]}
+	}
+	position := func(hit scoredTask) pagination.Cursor {
+		return taskSearchCursor(search, hit.Task, hit.Score)
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		return compareTaskSearch(keys, position(hits[i]), position(hits[j])) < 0
+	})
+
+	start := 0
+	if search.Page.After != nil {
+		for start < len(hits) && compareTaskSearch(keys, position(hits[start]), *search.Page.After) <= 0 {
+			start++
+		}
+	}
+	end := min(start+search.Page.Limit+1, len(hits))
+
+	result := &TaskSearchResult{
+		Page:  taskSearchPage(hits[start:end], search),
+		Total: int64(len(tasks)),
+		Facets: TaskFacets{
+			Categories: facetCounts(categories),
+			Skills:     facetCounts(skills),
+		},
+	}
+	return result, nil
+}
+
+func (r *memoryTaskRepository) Update(ctx context.Context, task *models.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tasks[task.ID]; !ok {
+		return ErrNotFound
+	}
+	r.tasks[task.ID] = copyTask(*task)
+	return nil
+}
+
+func (r *memoryTaskRepository) UpdateIfStatus(ctx context.Context, task *models.Task, expectedStatus string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.tasks[task.ID]
+	if !ok || stored.Status != expectedStatus {
+		return ErrConflict
+	}
+	r.tasks[task.ID] = copyTask(*task)
+	return nil
+}
+
+func (r *memoryTaskRepository) UpdateIfUnchanged(ctx context.Context, task *models.Task, lastUpdatedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.tasks[task.ID]
+	if !ok || !stored.UpdatedAt.Equal(lastUpdatedAt) {
+		return ErrConflict
+	}
+	r.tasks[task.ID] = copyTask(*task)
+	return nil
+}
+
+func (r *memoryTaskRepository) FindByDeadline(ctx context.Context, filter TaskDeadlineFilter) ([]models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := []models.Task{}
+	for _, task := range r.tasks {
+		if !slices.Contains(filter.Statuses, task.Status) || !task.Deadline.Before(filter.Before) {
+			continue
+		}
+		if !filter.After.IsZero() && !task.Deadline.After(filter.After) {
+			continue
+		}
+		if filter.NotOverdue && task.OverdueAt != nil {
+			continue
+		}
+		if filter.NotReminded != 0 && slices.Contains(task.Reminders, filter.NotReminded) {
+			continue
+		}
+		tasks = append(tasks, copyTask(task))
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Deadline.Before(tasks[j].Deadline) })
+	if len(tasks) > filter.Limit {
+		tasks = tasks[:filter.Limit]
+	}
+	return tasks, nil
+}
+
+func (r *memoryTaskRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tasks[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.tasks, id)
+	return nil
+}
+
+func copyTask(task models.Task) models.Task {
+	task.RequiredSkills = cloneStrings(task.RequiredSkills)
+	task.Attachments = cloneStrings(task.Attachments)
+	if task.FreelancerID != nil {
+		freelancerID := *task.FreelancerID
+		task.FreelancerID = &freelancerID
+	}
+	if task.CompletedAt != nil {
+		completedAt := *task.CompletedAt
+		task.CompletedAt = &completedAt
+	}
+	if task.OverdueAt != nil {
+		overdueAt := *task.OverdueAt
+		task.OverdueAt = &overdueAt
+	}
+	if task.BiddingClosesAt != nil {
+		closesAt := *task.BiddingClosesAt
+		task.BiddingClosesAt = &closesAt
+	}
+	if task.BiddingClosedAt != nil {
+		closedAt := *task.BiddingClosedAt
+		task.BiddingClosedAt = &closedAt
+	}
+	task.Reminders = append([]time.Duration(nil), task.Reminders...)
+	task.StatusHistory = append([]models.TaskStatusChange(nil), task.StatusHistory...)
+	task.Progress = nil
+	return task
+}
+
+// compareTaskSearch orders two search cursors the way keys sort tasks.
+func compareTaskSearch(keys []taskSearchKey, a, b pagination.Cursor) int {
+	for _, key := range keys {
+		var order int
+		switch x := key.cursorValue(a).(type) {
+		case float64:
+			y := key.cursorValue(b).(float64)
+			order = cmp.Compare(x, y)
+		case time.Time:
+			order = x.Compare(key.cursorValue(b).(time.Time))
+		case primitive.ObjectID:
+			order = strings.Compare(x.Hex(), key.cursorValue(b).(primitive.ObjectID).Hex())
+		}
+		if key.descending {
+			order = -order
+		}
+		if order != 0 {
+			return order
+		}
+	}
+	return 0
+}
+
+func textScore(task models.Task, terms []string) float64 {
+	var score float64
+	for _, word := range textWords(task.Title) {
+		for _, term := range terms {
+			if word == term {
+				score += 3
+			}
+		}
+	}
+	for _, word := range textWords(task.Description) {
+		for _, term := range terms {
+			if word == term {
+				score++
+			}
+		}
+	}
+	return score
+}
+
+func textWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func matchSkills(have, want []string, all bool) bool {
+	set := make(map[string]bool, len(have))
+	for _, skill := range have {
+		set[skill] = true
+	}
+	for _, skill := range want {
+		if set[skill] && !all {
+			return true
+		}
+		if !set[skill] && all {
+			return false
+		}
+	}
+	return all
+}
+
+// facetCounts orders counts the way the Mongo facet stage does: most common
+// first, ties broken by value.
+func facetCounts(counts map[string]int64) []FacetCount {
+	facets := make([]FacetCount, 0, len(counts))
+	for value, count := range counts {
+		facets = append(facets, FacetCount{Value: value, Count: count})
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].Value < facets[j].Value
+	})
+	return facets
+}
+
+func (r *memoryTaskRepository) FindBiddingDue(ctx context.Context, at time.Time, limit int) ([]models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := []models.Task{}
+	for _, task := range r.tasks {
+		if task.Status != models.TaskStatusOpen || task.BiddingClosedAt != nil {
+			continue
+		}
+		if task.BiddingClosesAt == nil || task.BiddingClosesAt.After(at) {
+			continue
+		}
+		tasks = append(tasks, copyTask(task))
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].BiddingClosesAt.Before(*tasks[j].BiddingClosesAt) })
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+	return tasks, nil
+}
+
+func (r *memoryTaskRepository) FreelancerHistories(ctx context.Context, freelancerIDs []primitive.ObjectID) ([]FreelancerHistory, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byFreelancer := make(map[primitive.ObjectID]*FreelancerHistory)
+	budgets := make(map[primitive.ObjectID]float64)
+	for _, task := range r.tasks {
+		if task.Status != models.TaskStatusCompleted || task.FreelancerID == nil || !slices.Contains(freelancerIDs, *task.FreelancerID) {
+			continue
+		}
+		id := *task.FreelancerID
+		history, ok := byFreelancer[id]
+		if !ok {
+			history = &FreelancerHistory{FreelancerID: id}
+			byFreelancer[id] = history
+		}
+
+		history.Completed++
+		if task.OverdueAt == nil {
+			history.OnTime++
+		}
+		budgets[id] += task.Budget
+		if task.CompletedAt != nil && (history.LastCompletedAt == nil || task.CompletedAt.After(*history.LastCompletedAt)) {
+			completedAt := *task.CompletedAt
+			history.LastCompletedAt = &completedAt
+		}
+		if i := slices.IndexFunc(history.Categories, func(c CategoryCount) bool { return c.Category == task.Category }); i >= 0 {
+			history.Categories[i].Count++
+		} else {
+			history.Categories = append(history.Categories, CategoryCount{Category: task.Category, Count: 1})
+		}
+		if !slices.Contains(history.ClientIDs, task.ClientID) {
+			history.ClientIDs = append(history.ClientIDs, task.ClientID)
+		}
+	}
+
+	histories := []FreelancerHistory{}
+	for id, history := range byFreelancer {
+		history.AverageBudget = budgets[id] / float64(history.Completed)
+		histories = append(histories, *history)
+	}
+	return histories, nil
+}
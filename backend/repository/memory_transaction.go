@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"sync"
+)
+
+// memoryTransactor serializes transactions but cannot roll them back, so
+// callers should make their first write the one that can conflict.
+type memoryTransactor struct {
+	mu sync.Mutex
+}
+
+func NewMemoryTransactor() Transactor {
+	return &memoryTransactor{}
+}
+
+func (t *memoryTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return fn(ctx)
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryUserRepository struct {
+	mu    sync.RWMutex
+	users map[primitive.ObjectID]models.User
+}
+
+// NewMemoryUserRepository returns a UserRepository that enforces the same
+// unique email constraint as the users collection index.
+func NewMemoryUserRepository() UserRepository {
+	return &memoryUserRepository{users: make(map[primitive.ObjectID]models.User)}
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	if _, exists := r.users[user.ID]; exists {
+		return ErrDuplicate
+	}
+	if r.emailTaken(user.Email, user.ID) {
+		return ErrDuplicate
+	}
+	r.users[user.ID] = copyUser(*user)
+	return nil
+}
+
+func (r *memoryUserRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	user = copyUser(user)
+	return &user, nil
+}
+
+func (r *memoryUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Email == email {
+			user = copyUser(user)
+			return &user, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryUserRepository) Update(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.ID]; !ok {
+		return ErrNotFound
+	}
+	if r.emailTaken(user.Email, user.ID) {
+		return ErrDuplicate
+	}
+	r.users[user.ID] = copyUser(*user)
+	return nil
+}
+
+func (r *memoryUserRepository) CountByType(ctx context.Context, userType string) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, user := range r.users {
+		if user.UserType == userType {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryUserRepository) Search(ctx context.Context, filter UserFilter, page pagination.Params) (*pagination.Page[models.User], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	query := strings.ToLower(filter.Query)
+	skills := models.NormalizeSkills(filter.Skills)
+	users := []models.User{}
+	for _, user := range r.users {
+		if query != "" &&
+			!strings.Contains(strings.ToLower(user.Email), query) &&
+			!strings.Contains(strings.ToLower(user.FirstName), query) &&
+			!strings.Contains(strings.ToLower(user.LastName), query) {
+			continue
+		}
+		if filter.UserType != "" && user.UserType != filter.UserType {
+			continue
+		}
+		if filter.Suspended != nil && user.IsSuspended() != *filter.Suspended {
+			continue
+		}
+		if len(skills) > 0 && !hasAnySkill(user.Skills, skills) {
+			continue
+		}
+		users = append(users, copyUser(user))
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return lessByCreated(userCursor(users[i]), userCursor(users[j]), true)
+	})
+	return memoryPage(users, page, true, userCursor), nil
+}
+
+// hasAnySkill reports whether any of skills, already normalized, is among
+// userSkills.
+func hasAnySkill(userSkills, skills []string) bool {
+	for _, skill := range models.NormalizeSkills(userSkills) {
+		if slices.Contains(skills, skill) {
+			return true
+		}
+	}
+	return false
+}
+
+// emailTaken reports whether another user already owns email. Callers must hold the lock.
+func (r *memoryUserRepository) emailTaken(email string, except primitive.ObjectID) bool {
+	for id, existing := range r.users {
+		if id != except && existing.Email == email {
+			return true
+		}
+	}
+	return false
+}
+
+func copyUser(user models.User) models.User {
+	user.Skills = cloneStrings(user.Skills)
+	if user.SuspendedAt != nil {
+		suspendedAt := *user.SuspendedAt
+		user.SuspendedAt = &suspendedAt
+	}
+	return user
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MessageRepository interface {
+	Create(ctx context.Context, message *models.Message) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Message, error)
+	// FindByConversation pages through a conversation newest first, so the
+	// cursor walks back through older history.
+	FindByConversation(ctx context.Context, conversationID primitive.ObjectID, page pagination.Params) (*pagination.Page[models.Message], error)
+	// MarkRead marks every unread message readerID received in the
+	// conversation up to and including through as read at readAt, and
+	// returns how many were marked.
+	MarkRead(ctx context.Context, conversationID, readerID primitive.ObjectID, through, readAt time.Time) (int64, error)
+	// CountUnread counts the messages readerID has received in the
+	// conversation but not read.
+	CountUnread(ctx context.Context, conversationID, readerID primitive.ObjectID) (int64, error)
+}
+
+type mongoMessageRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoMessageRepository(db *mongo.Database) MessageRepository {
+	return &mongoMessageRepository{collection: db.Collection("messages")}
+}
+
+func (r *mongoMessageRepository) Create(ctx context.Context, message *models.Message) error {
+	if message.ID.IsZero() {
+		message.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, message)
+	return mongoError(err)
+}
+
+func (r *mongoMessageRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Message, error) {
+	var message models.Message
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&message); err != nil {
+		return nil, mongoError(err)
+	}
+	return &message, nil
+}
+
+func (r *mongoMessageRepository) FindByConversation(ctx context.Context, conversationID primitive.ObjectID, page pagination.Params) (*pagination.Page[models.Message], error) {
+	return findPage(ctx, r.collection, bson.M{"conversation_id": conversationID}, page, true, messageCursor)
+}
+
+func (r *mongoMessageRepository) MarkRead(ctx context.Context, conversationID, readerID primitive.ObjectID, through, readAt time.Time) (int64, error) {
+	filter := bson.M{
+		"conversation_id": conversationID,
+		"sender_id":       bson.M{"$ne": readerID},
+		"read_at":         nil,
+		"created_at":      bson.M{"$lte": through},
+	}
+	result, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"read_at": readAt}})
+	if err != nil {
+		return 0, mongoError(err)
+	}
+	return result.ModifiedCount, nil
+}
+
+func (r *mongoMessageRepository) CountUnread(ctx context.Context, conversationID, readerID primitive.ObjectID) (int64, error) {
+	filter := bson.M{
+		"conversation_id": conversationID,
+		"sender_id":       bson.M{"$ne": readerID},
+		"read_at":         nil,
+	}
+	count, err := r.collection.CountDocuments(ctx, filter)
+	return count, mongoError(err)
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MilestoneRepository interface {
+	Create(ctx context.Context, milestone *models.Milestone) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Milestone, error)
+	// FindByTask returns every milestone of a task ordered by due date.
+	FindByTask(ctx context.Context, taskID primitive.ObjectID) ([]models.Milestone, error)
+	Update(ctx context.Context, milestone *models.Milestone) error
+	// UpdateIfStatus saves milestone only if its stored status is still
+	// expectedStatus, returning ErrConflict otherwise.
+	UpdateIfStatus(ctx context.Context, milestone *models.Milestone, expectedStatus string) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	// DeleteByTask removes every milestone of a task.
+	DeleteByTask(ctx context.Context, taskID primitive.ObjectID) error
+}
+
+type mongoMilestoneRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoMilestoneRepository(db *mongo.Database) MilestoneRepository {
+	return &mongoMilestoneRepository{collection: db.Collection("milestones")}
+}
+
+func (r *mongoMilestoneRepository) Create(ctx context.Context, milestone *models.Milestone) error {
+	if milestone.ID.IsZero() {
+		milestone.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, milestone)
+	return mongoError(err)
+}
+
+func (r *mongoMilestoneRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Milestone, error) {
+	var milestone models.Milestone
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&milestone); err != nil {
+		return nil, mongoError(err)
+	}
+	return &milestone, nil
+}
+
+func (r *mongoMilestoneRepository) FindByTask(ctx context.Context, taskID primitive.ObjectID) ([]models.Milestone, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "due_date", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"task_id": taskID}, opts)
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	milestones := []models.Milestone{}
+	if err := cursor.All(ctx, &milestones); err != nil {
+		return nil, err
+	}
+	return milestones, nil
+}
+
+func (r *mongoMilestoneRepository) Update(ctx context.Context, milestone *models.Milestone) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": milestone.ID}, milestone)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoMilestoneRepository) UpdateIfStatus(ctx context.Context, milestone *models.Milestone, expectedStatus string) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": milestone.ID, "status": expectedStatus}, milestone)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoMilestoneRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoMilestoneRepository) DeleteByTask(ctx context.Context, taskID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"task_id": taskID})
+	return mongoError(err)
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type NotificationPreferenceRepository interface {
+	// FindByUser returns ErrNotFound for users who never saved a preference.
+	FindByUser(ctx context.Context, userID primitive.ObjectID) (*models.NotificationPreference, error)
+	// Save creates or replaces the user's preference.
+	Save(ctx context.Context, preference *models.NotificationPreference) error
+	// FindDigestDue returns the daily digest preferences whose last digest
+	// was sent before cutoff.
+	FindDigestDue(ctx context.Context, cutoff time.Time) ([]models.NotificationPreference, error)
+	// ClaimDigest records a digest for userID sent at the given time if the
+	// user's last digest is still before cutoff, so that concurrent senders
+	// do not both send it. It returns ErrConflict when another sender won.
+	ClaimDigest(ctx context.Context, userID primitive.ObjectID, cutoff, at time.Time) error
+}
+
+type mongoNotificationPreferenceRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoNotificationPreferenceRepository(db *mongo.Database) NotificationPreferenceRepository {
+	return &mongoNotificationPreferenceRepository{collection: db.Collection("notification_preferences")}
+}
+
+func (r *mongoNotificationPreferenceRepository) FindByUser(ctx context.Context, userID primitive.ObjectID) (*models.NotificationPreference, error) {
+	var preference models.NotificationPreference
+	if err := r.collection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&preference); err != nil {
+		return nil, mongoError(err)
+	}
+	return &preference, nil
+}
+
+func (r *mongoNotificationPreferenceRepository) Save(ctx context.Context, preference *models.NotificationPreference) error {
+	if preference.ID.IsZero() {
+		preference.ID = primitive.NewObjectID()
+	}
+	opts := options.Replace().SetUpsert(true)
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"user_id": preference.UserID}, preference, opts)
+	return mongoError(err)
+}
+
+func (r *mongoNotificationPreferenceRepository) FindDigestDue(ctx context.Context, cutoff time.Time) ([]models.NotificationPreference, error) {
+	cursor, err := r.collection.Find(ctx, digestDueQuery(bson.M{}, cutoff))
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	preferences := []models.NotificationPreference{}
+	if err := cursor.All(ctx, &preferences); err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+func (r *mongoNotificationPreferenceRepository) ClaimDigest(ctx context.Context, userID primitive.ObjectID, cutoff, at time.Time) error {
+	result, err := r.collection.UpdateOne(ctx,
+		digestDueQuery(bson.M{"user_id": userID}, cutoff),
+		bson.M{"$set": bson.M{"last_digest_at": at}},
+	)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// digestDueQuery narrows query to digest preferences not sent since cutoff.
+func digestDueQuery(query bson.M, cutoff time.Time) bson.M {
+	query["email"] = models.EmailDailyDigest
+	query["$or"] = bson.A{
+		bson.M{"last_digest_at": bson.M{"$lt": cutoff}},
+		bson.M{"last_digest_at": nil},
+	}
+	return query
+}
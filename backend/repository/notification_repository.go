@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NotificationFilter selects one user's notifications.
+type NotificationFilter struct {
+	UserID primitive.ObjectID
+	// UnreadOnly leaves out notifications that were marked as read.
+	UnreadOnly bool
+}
+
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *models.Notification) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Notification, error)
+	// Find pages through a user's notifications, newest first.
+	Find(ctx context.Context, filter NotificationFilter, page pagination.Params) (*pagination.Page[models.Notification], error)
+	// MarkRead marks one of userID's notifications as read. It returns
+	// ErrNotFound if the notification does not belong to the user, and
+	// leaves one that was already read unchanged.
+	MarkRead(ctx context.Context, userID, id primitive.ObjectID, at time.Time) error
+	// MarkAllRead marks every unread notification of userID as read and
+	// returns how many were marked.
+	MarkAllRead(ctx context.Context, userID primitive.ObjectID, at time.Time) (int64, error)
+	CountUnread(ctx context.Context, userID primitive.ObjectID) (int64, error)
+	// FindDigestPending returns userID's notifications waiting for a digest,
+	// oldest first.
+	FindDigestPending(ctx context.Context, userID primitive.ObjectID) ([]models.Notification, error)
+	// ClearDigestPending takes userID's notifications created up to through
+	// off the digest queue.
+	ClearDigestPending(ctx context.Context, userID primitive.ObjectID, through time.Time) error
+}
+
+type mongoNotificationRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoNotificationRepository(db *mongo.Database) NotificationRepository {
+	return &mongoNotificationRepository{collection: db.Collection("notifications")}
+}
+
+func (r *mongoNotificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	if notification.ID.IsZero() {
+		notification.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, notification)
+	return mongoError(err)
+}
+
+func (r *mongoNotificationRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Notification, error) {
+	var notification models.Notification
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&notification); err != nil {
+		return nil, mongoError(err)
+	}
+	return &notification, nil
+}
+
+func (r *mongoNotificationRepository) Find(ctx context.Context, filter NotificationFilter, page pagination.Params) (*pagination.Page[models.Notification], error) {
+	query := bson.M{"user_id": filter.UserID}
+	if filter.UnreadOnly {
+		query["read_at"] = nil
+	}
+	return findPage(ctx, r.collection, query, page, true, notificationCursor)
+}
+
+func (r *mongoNotificationRepository) MarkRead(ctx context.Context, userID, id primitive.ObjectID, at time.Time) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "user_id": userID},
+		bson.A{bson.M{"$set": bson.M{"read_at": bson.M{"$ifNull": bson.A{"$read_at", at}}}}},
+	)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoNotificationRepository) MarkAllRead(ctx context.Context, userID primitive.ObjectID, at time.Time) (int64, error) {
+	result, err := r.collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "read_at": nil},
+		bson.M{"$set": bson.M{"read_at": at}},
+	)
+	if err != nil {
+		return 0, mongoError(err)
+	}
+	return result.ModifiedCount, nil
+}
+
+func (r *mongoNotificationRepository) CountUnread(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"user_id": userID, "read_at": nil})
+	return count, mongoError(err)
+}
+
+func (r *mongoNotificationRepository) FindDigestPending(ctx context.Context, userID primitive.ObjectID) ([]models.Notification, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID, "digest_pending": true}, opts)
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	notifications := []models.Notification{}
+	if err := cursor.All(ctx, &notifications); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (r *mongoNotificationRepository) ClearDigestPending(ctx context.Context, userID primitive.ObjectID, through time.Time) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "digest_pending": true, "created_at": bson.M{"$lte": through}},
+		bson.M{"$unset": bson.M{"digest_pending": ""}},
+	)
+	return mongoError(err)
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// findPage reads one keyset page of a collection ordered by (created_at, _id).
+// It fetches one extra document to learn whether another page follows.
+func findPage[T any](ctx context.Context, collection *mongo.Collection, query bson.M, params pagination.Params, descending bool, cursorOf func(T) pagination.Cursor) (*pagination.Page[T], error) {
+	direction, compare := 1, "$gt"
+	if descending {
+		direction, compare = -1, "$lt"
+	}
+
+	if params.After != nil {
+		after := bson.M{"$or": bson.A{
+			bson.M{"created_at": bson.M{compare: params.After.CreatedAt}},
+			bson.M{"created_at": params.After.CreatedAt, "_id": bson.M{compare: params.After.ID}},
+		}}
+		if len(query) > 0 {
+			query = bson.M{"$and": bson.A{query, after}}
+		} else {
+			query = after
+		}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: direction}, {Key: "_id", Value: direction}}).
+		SetLimit(int64(params.Limit + 1))
+	cursor, err := collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	items := []T{}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return pagination.NewPage(items, params, cursorOf), nil
+}
+
+// memoryPage is findPage for the in-memory stores. items must already be
+// ordered by (created_at, _id) in the given direction.
+func memoryPage[T any](items []T, params pagination.Params, descending bool, cursorOf func(T) pagination.Cursor) *pagination.Page[T] {
+	start := 0
+	if params.After != nil {
+		for start < len(items) {
+			position := cursorOf(items[start])
+			if descending && position.Before(*params.After) || !descending && params.After.Before(position) {
+				break
+			}
+			start++
+		}
+	}
+
+	end := start + params.Limit + 1
+	if end > len(items) {
+		end = len(items)
+	}
+	return pagination.NewPage(items[start:end], params, cursorOf)
+}
+
+// lessByCreated orders cursors by (created_at, _id) the way findPage does.
+func lessByCreated(a, b pagination.Cursor, descending bool) bool {
+	if descending {
+		return b.Before(a)
+	}
+	return a.Before(b)
+}
+
+func taskCursor(task models.Task) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: task.CreatedAt, ID: task.ID}
+}
+
+func bidCursor(bid models.Bid) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: bid.CreatedAt, ID: bid.ID}
+}
+
+func reviewCursor(review models.Review) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: review.CreatedAt, ID: review.ID}
+}
+
+func paymentCursor(payment models.Payment) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: payment.CreatedAt, ID: payment.ID}
+}
+
+func userCursor(user models.User) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: user.CreatedAt, ID: user.ID}
+}
+
+func adminActionCursor(action models.AdminAction) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: action.CreatedAt, ID: action.ID}
+}
+
+// conversationCursor is a conversation's position in the most recently
+// active first order of ConversationRepository.FindByTask.
+func conversationCursor(conversation models.Conversation) pagination.Cursor {
+	updatedAt := conversation.UpdatedAt
+	return pagination.Cursor{CreatedAt: conversation.CreatedAt, ID: conversation.ID, Time: &updatedAt}
+}
+
+func messageCursor(message models.Message) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: message.CreatedAt, ID: message.ID}
+}
+
+func notificationCursor(notification models.Notification) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: notification.CreatedAt, ID: notification.ID}
+}
+
+func jobCursor(job models.Job) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: job.CreatedAt, ID: job.ID}
+}
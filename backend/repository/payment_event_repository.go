@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// PaymentEventRepository stores verified payment webhooks for deduplication
+// and audit.
+type PaymentEventRepository interface {
+	// Create returns ErrDuplicate if the provider's event ID was already stored.
+	Create(ctx context.Context, event *models.PaymentEvent) error
+	FindByEventID(ctx context.Context, provider, eventID string) (*models.PaymentEvent, error)
+	Update(ctx context.Context, event *models.PaymentEvent) error
+}
+
+type mongoPaymentEventRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoPaymentEventRepository(db *mongo.Database) PaymentEventRepository {
+	return &mongoPaymentEventRepository{collection: db.Collection("payment_events")}
+}
+
+func (r *mongoPaymentEventRepository) Create(ctx context.Context, event *models.PaymentEvent) error {
+	if event.ID.IsZero() {
+		event.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, event)
+	return mongoError(err)
+}
+
+func (r *mongoPaymentEventRepository) FindByEventID(ctx context.Context, provider, eventID string) (*models.PaymentEvent, error) {
+	var event models.PaymentEvent
+	if err := r.collection.FindOne(ctx, bson.M{"provider": provider, "event_id": eventID}).Decode(&event); err != nil {
+		return nil, mongoError(err)
+	}
+	return &event, nil
+}
+
+func (r *mongoPaymentEventRepository) Update(ctx context.Context, event *models.PaymentEvent) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": event.ID}, event)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
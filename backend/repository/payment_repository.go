@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PaymentRepository interface {
+	Create(ctx context.Context, payment *models.Payment) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Payment, error)
+	// FindByTransactionID looks a payment up by the provider's charge ID.
+	FindByTransactionID(ctx context.Context, transactionID string) (*models.Payment, error)
+	// FindByPayoutID looks a payment up by the provider's payout ID.
+	FindByPayoutID(ctx context.Context, payoutID string) (*models.Payment, error)
+	FindByTask(ctx context.Context, taskID primitive.ObjectID, page pagination.Params) (*pagination.Page[models.Payment], error)
+	// FindByTaskAndStatus returns every payment of a task in one status, oldest first.
+	FindByTaskAndStatus(ctx context.Context, taskID primitive.ObjectID, status string) ([]models.Payment, error)
+	Update(ctx context.Context, payment *models.Payment) error
+	// UpdateIfStatus saves payment only if its stored status is still
+	// expectedStatus, returning ErrConflict otherwise.
+	UpdateIfStatus(ctx context.Context, payment *models.Payment, expectedStatus string) error
+}
+
+type mongoPaymentRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoPaymentRepository(db *mongo.Database) PaymentRepository {
+	return &mongoPaymentRepository{collection: db.Collection("payments")}
+}
+
+func (r *mongoPaymentRepository) Create(ctx context.Context, payment *models.Payment) error {
+	if payment.ID.IsZero() {
+		payment.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, payment)
+	return mongoError(err)
+}
+
+func (r *mongoPaymentRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Payment, error) {
+	var payment models.Payment
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&payment); err != nil {
+		return nil, mongoError(err)
+	}
+	return &payment, nil
+}
+
+func (r *mongoPaymentRepository) FindByTransactionID(ctx context.Context, transactionID string) (*models.Payment, error) {
+	return r.findOne(ctx, bson.M{"transaction_id": transactionID})
+}
+
+func (r *mongoPaymentRepository) FindByPayoutID(ctx context.Context, payoutID string) (*models.Payment, error) {
+	return r.findOne(ctx, bson.M{"payout_id": payoutID})
+}
+
+func (r *mongoPaymentRepository) findOne(ctx context.Context, query bson.M) (*models.Payment, error) {
+	var payment models.Payment
+	if err := r.collection.FindOne(ctx, query).Decode(&payment); err != nil {
+		return nil, mongoError(err)
+	}
+	return &payment, nil
+}
+
+func (r *mongoPaymentRepository) FindByTask(ctx context.Context, taskID primitive.ObjectID, page pagination.Params) (*pagination.Page[models.Payment], error) {
+	return findPage(ctx, r.collection, bson.M{"task_id": taskID}, page, true, paymentCursor)
+}
+
+func (r *mongoPaymentRepository) FindByTaskAndStatus(ctx context.Context, taskID primitive.ObjectID, status string) ([]models.Payment, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"task_id": taskID, "status": status}, opts)
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	payments := []models.Payment{}
+	if err := cursor.All(ctx, &payments); err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+func (r *mongoPaymentRepository) Update(ctx context.Context, payment *models.Payment) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": payment.ID}, payment)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoPaymentRepository) UpdateIfStatus(ctx context.Context, payment *models.Payment, expectedStatus string) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": payment.ID, "status": expectedStatus}, payment)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	// ErrNotFound is returned when a lookup matches no document.
+	ErrNotFound = errors.New("document not found")
+	// ErrDuplicate is returned when an insert violates a unique index.
+	ErrDuplicate = errors.New("duplicate key")
+	// ErrConflict is returned when a conditional update finds the document
+	// was changed concurrently.
+	ErrConflict = errors.New("document was modified concurrently")
+)
+
+// Repositories bundles every store the HTTP layer depends on.
+type Repositories struct {
+	Users                   UserRepository
+	Tasks                   TaskRepository
+	Bids                    BidRepository
+	Reviews                 ReviewRepository
+	Payments                PaymentRepository
+	Milestones              MilestoneRepository
+	Sessions                SessionRepository
+	PasswordResets          PasswordResetRepository
+	AdminActions            AdminActionRepository
+	Ledger                  LedgerRepository
+	PaymentEvents           PaymentEventRepository
+	IdempotencyKeys         IdempotencyKeyRepository
+	Conversations           ConversationRepository
+	Messages                MessageRepository
+	Notifications           NotificationRepository
+	NotificationPreferences NotificationPreferenceRepository
+	Jobs                    JobRepository
+	Transactor              Transactor
+}
+
+// NewMongoRepositories returns MongoDB-backed implementations of every repository.
+func NewMongoRepositories(db *mongo.Database) *Repositories {
+	return &Repositories{
+		Users:                   NewMongoUserRepository(db),
+		Tasks:                   NewMongoTaskRepository(db),
+		Bids:                    NewMongoBidRepository(db),
+		Reviews:                 NewMongoReviewRepository(db),
+		Payments:                NewMongoPaymentRepository(db),
+		Milestones:              NewMongoMilestoneRepository(db),
+		Sessions:                NewMongoSessionRepository(db),
+		PasswordResets:          NewMongoPasswordResetRepository(db),
+		AdminActions:            NewMongoAdminActionRepository(db),
+		Ledger:                  NewMongoLedgerRepository(db),
+		PaymentEvents:           NewMongoPaymentEventRepository(db),
+		IdempotencyKeys:         NewMongoIdempotencyKeyRepository(db),
+		Conversations:           NewMongoConversationRepository(db),
+		Messages:                NewMongoMessageRepository(db),
+		Notifications:           NewMongoNotificationRepository(db),
+		NotificationPreferences: NewMongoNotificationPreferenceRepository(db),
+		Jobs:                    NewMongoJobRepository(db),
+		Transactor:              NewMongoTransactor(db),
+	}
+}
+
+// mongoError maps driver errors onto the package's sentinel errors.
+func mongoError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return ErrNotFound
+	case mongo.IsDuplicateKeyError(err):
+		return ErrDuplicate
+	default:
+		return err
+	}
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ReviewRepository interface {
+	Create(ctx context.Context, review *models.Review) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Review, error)
+	// FindByReviewedUser returns the visible reviews a user has received.
+	FindByReviewedUser(ctx context.Context, userID primitive.ObjectID, page pagination.Params) (*pagination.Page[models.Review], error)
+	// AverageRating averages the visible reviews a user has received.
+	AverageRating(ctx context.Context, userID primitive.ObjectID) (float64, error)
+	Update(ctx context.Context, review *models.Review) error
+}
+
+// notHidden matches reviews a moderator has not hidden, including documents
+// written before the is_hidden field existed.
+var notHidden = bson.M{"$ne": true}
+
+type mongoReviewRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoReviewRepository(db *mongo.Database) ReviewRepository {
+	return &mongoReviewRepository{collection: db.Collection("reviews")}
+}
+
+func (r *mongoReviewRepository) Create(ctx context.Context, review *models.Review) error {
+	if review.ID.IsZero() {
+		review.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, review)
+	return mongoError(err)
+}
+
+func (r *mongoReviewRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Review, error) {
+	var review models.Review
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&review); err != nil {
+		return nil, mongoError(err)
+	}
+	return &review, nil
+}
+
+func (r *mongoReviewRepository) FindByReviewedUser(ctx context.Context, userID primitive.ObjectID, page pagination.Params) (*pagination.Page[models.Review], error) {
+	filter := bson.M{"reviewed_user_id": userID, "is_hidden": notHidden}
+	return findPage(ctx, r.collection, filter, page, true, reviewCursor)
+}
+
+// AverageRating returns the mean visible rating a user has received, or 0 if they have none.
+func (r *mongoReviewRepository) AverageRating(ctx context.Context, userID primitive.ObjectID) (float64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"reviewed_user_id": userID, "is_hidden": notHidden}}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "avg": bson.M{"$avg": "$rating"}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var result struct {
+		Avg float64 `bson:"avg"`
+	}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&result); err != nil {
+			return 0, err
+		}
+	}
+	return result.Avg, cursor.Err()
+}
+
+func (r *mongoReviewRepository) Update(ctx context.Context, review *models.Review) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": review.ID}, review)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
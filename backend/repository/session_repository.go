@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Session, error)
+	// FindByTokenHash matches either the current or a previously rotated refresh token.
+	FindByTokenHash(ctx context.Context, hash string) (*models.Session, error)
+	// Rotate replaces the current refresh token hash, failing with ErrNotFound
+	// if oldHash is no longer current (for example after a concurrent refresh).
+	Rotate(ctx context.Context, id primitive.ObjectID, oldHash, newHash string, expiresAt time.Time) error
+	Revoke(ctx context.Context, id primitive.ObjectID) error
+	RevokeAllForUser(ctx context.Context, userID primitive.ObjectID) (int64, error)
+	// RevokeOthers revokes every session of userID except keep.
+	RevokeOthers(ctx context.Context, userID, keep primitive.ObjectID) (int64, error)
+}
+
+type mongoSessionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoSessionRepository(db *mongo.Database) SessionRepository {
+	return &mongoSessionRepository{collection: db.Collection("sessions")}
+}
+
+func (r *mongoSessionRepository) Create(ctx context.Context, session *models.Session) error {
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, session)
+	return mongoError(err)
+}
+
+func (r *mongoSessionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Session, error) {
+	var session models.Session
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&session); err != nil {
+		return nil, mongoError(err)
+	}
+	return &session, nil
+}
+
+func (r *mongoSessionRepository) FindByTokenHash(ctx context.Context, hash string) (*models.Session, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"refresh_token_hash": hash},
+		bson.M{"previous_token_hashes": hash},
+	}}
+
+	var session models.Session
+	if err := r.collection.FindOne(ctx, filter).Decode(&session); err != nil {
+		return nil, mongoError(err)
+	}
+	return &session, nil
+}
+
+func (r *mongoSessionRepository) Rotate(ctx context.Context, id primitive.ObjectID, oldHash, newHash string, expiresAt time.Time) error {
+	filter := bson.M{"_id": id, "refresh_token_hash": oldHash, "revoked_at": nil}
+	update := bson.M{
+		"$set": bson.M{
+			"refresh_token_hash": newHash,
+			"expires_at":         expiresAt,
+			"updated_at":         time.Now(),
+		},
+		"$push": bson.M{"previous_token_hashes": oldHash},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoSessionRepository) Revoke(ctx context.Context, id primitive.ObjectID) error {
+	now := time.Now()
+	filter := bson.M{"_id": id, "revoked_at": nil}
+	update := bson.M{"$set": bson.M{"revoked_at": now, "updated_at": now}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return mongoError(err)
+}
+
+func (r *mongoSessionRepository) RevokeAllForUser(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	now := time.Now()
+	filter := bson.M{"user_id": userID, "revoked_at": nil}
+	update := bson.M{"$set": bson.M{"revoked_at": now, "updated_at": now}}
+
+	result, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, mongoError(err)
+	}
+	return result.ModifiedCount, nil
+}
+
+func (r *mongoSessionRepository) RevokeOthers(ctx context.Context, userID, keep primitive.ObjectID) (int64, error) {
+	now := time.Now()
+	filter := bson.M{"user_id": userID, "_id": bson.M{"$ne": keep}, "revoked_at": nil}
+	update := bson.M{"$set": bson.M{"revoked_at": now, "updated_at": now}}
+
+	result, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, mongoError(err)
+	}
+	return result.ModifiedCount, nil
+}
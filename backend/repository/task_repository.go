@@ -0,0 +1,540 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TaskFilter narrows a task listing. Empty fields are ignored.
+type TaskFilter struct {
+	Status       string
+	Category     string
+	FreelancerID *primitive.ObjectID // tasks assigned to this freelancer
+}
+
+// Task search sort orders.
+const (
+	TaskSortRelevance  = "relevance"
+	TaskSortNewest     = "newest"
+	TaskSortDeadline   = "deadline"
+	TaskSortBudgetHigh = "budget_high"
+	TaskSortBudgetLow  = "budget_low"
+)
+
+// TaskSearch describes a full-text and faceted task search. Zero values are
+// ignored, except Page.Limit which must be positive. Page cursors are only
+// valid for the Sort they were returned with.
+type TaskSearch struct {
+	Query          string
+	Status         string
+	Category       string
+	Skills         []string
+	MatchAllSkills bool
+	MinBudget      *float64
+	MaxBudget      *float64
+	DeadlineAfter  *time.Time
+	DeadlineBefore *time.Time
+	Sort           string
+	Page           pagination.Params
+}
+
+// FacetCount is the number of matching tasks sharing one value.
+type FacetCount struct {
+	Value string `bson:"_id" json:"value"`
+	Count int64  `bson:"count" json:"count"`
+}
+
+// TaskFacets are counted over every task matching the search, not just the
+// returned page.
+type TaskFacets struct {
+	Categories []FacetCount `bson:"categories" json:"categories"`
+	Skills     []FacetCount `bson:"skills" json:"skills"`
+}
+
+// TaskSearchResult is one page of matching tasks, with the total and facets
+// counted over every match.
+type TaskSearchResult struct {
+	pagination.Page[models.Task]
+	Total  int64      `json:"total"`
+	Facets TaskFacets `json:"facets"`
+}
+
+// TaskDeadlineFilter selects tasks for the deadline scheduler. Zero values
+// are ignored, except Before and Limit which must be set.
+type TaskDeadlineFilter struct {
+	Statuses    []string
+	After       time.Time     // deadline strictly after
+	Before      time.Time     // deadline strictly before
+	NotOverdue  bool          // leave out tasks already flagged overdue
+	NotReminded time.Duration // leave out tasks already reminded at this offset
+	Limit       int
+}
+
+// CategoryCount is how many of a freelancer's completed tasks are in one
+// category.
+type CategoryCount struct {
+	Category string `bson:"category"`
+	Count    int    `bson:"count"`
+}
+
+// FreelancerHistory summarizes a freelancer's completed tasks, see
+// TaskRepository.FreelancerHistories.
+type FreelancerHistory struct {
+	FreelancerID    primitive.ObjectID   `bson:"_id"`
+	Completed       int                  `bson:"completed"`
+	OnTime          int                  `bson:"on_time"` // never flagged overdue
+	AverageBudget   float64              `bson:"average_budget"`
+	LastCompletedAt *time.Time           `bson:"last_completed_at"`
+	Categories      []CategoryCount      `bson:"categories"`
+	ClientIDs       []primitive.ObjectID `bson:"client_ids"` // clients worked for
+}
+
+type TaskRepository interface {
+	Create(ctx context.Context, task *models.Task) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Task, error)
+	Find(ctx context.Context, filter TaskFilter, page pagination.Params) (*pagination.Page[models.Task], error)
+	Search(ctx context.Context, search TaskSearch) (*TaskSearchResult, error)
+	Update(ctx context.Context, task *models.Task) error
+	// UpdateIfStatus saves task only if its stored status is still
+	// expectedStatus, returning ErrConflict otherwise.
+	UpdateIfStatus(ctx context.Context, task *models.Task, expectedStatus string) error
+	// UpdateIfUnchanged saves task only if nobody saved it since it was
+	// loaded, that is its stored UpdatedAt is still lastUpdatedAt, returning
+	// ErrConflict otherwise.
+	UpdateIfUnchanged(ctx context.Context, task *models.Task, lastUpdatedAt time.Time) error
+	// FindByDeadline returns matching tasks, earliest deadline first.
+	FindByDeadline(ctx context.Context, filter TaskDeadlineFilter) ([]models.Task, error)
+	// FindBiddingDue returns up to limit open auction tasks whose bidding
+	// closes at or before at and has not been closed yet, earliest first.
+	FindBiddingDue(ctx context.Context, at time.Time, limit int) ([]models.Task, error)
+	// FreelancerHistories summarizes the completed tasks of each of the
+	// freelancers in one query. Freelancers without completed tasks are
+	// left out.
+	FreelancerHistories(ctx context.Context, freelancerIDs []primitive.ObjectID) ([]FreelancerHistory, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+type mongoTaskRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoTaskRepository(db *mongo.Database) TaskRepository {
+	return &mongoTaskRepository{collection: db.Collection("tasks")}
+}
+
+func (r *mongoTaskRepository) Create(ctx context.Context, task *models.Task) error {
+	if task.ID.IsZero() {
+		task.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, task)
+	return mongoError(err)
+}
+
+func (r *mongoTaskRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Task, error) {
+	var task models.Task
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&task); err != nil {
+		return nil, mongoError(err)
+	}
+	return &task, nil
+}
+
+func (r *mongoTaskRepository) Find(ctx context.Context, filter TaskFilter, page pagination.Params) (*pagination.Page[models.Task], error) {
+	query := bson.M{}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.Category != "" {
+		query["category"] = filter.Category
+	}
+	if filter.FreelancerID != nil {
+		query["freelancer_id"] = *filter.FreelancerID
+	}
+
+	return findPage(ctx, r.collection, query, page, true, taskCursor)
+}
+
+// Search runs the filters, page and facet counts as a single aggregation.
+// Relevance comes from the text index on title and description, so it only
+// applies when Query is set; otherwise results are ordered newest first.
+func (r *mongoTaskRepository) Search(ctx context.Context, search TaskSearch) (*TaskSearchResult, error) {
+	keys := taskSearchKeys(search)
+	page := bson.A{}
+	if search.Page.After != nil {
+		after, err := taskSearchAfter(keys, *search.Page.After)
+		if err != nil {
+			return nil, err
+		}
+		page = append(page, bson.M{"$match": after})
+	}
+	page = append(page,
+		bson.M{"$sort": taskSearchSort(keys)},
+		bson.M{"$limit": search.Page.Limit + 1},
+	)
+
+	match := bson.M{}
+	if search.Query != "" {
+		match["$text"] = bson.M{"$search": search.Query}
+	}
+	if search.Status != "" {
+		match["status"] = search.Status
+	}
+	if search.Category != "" {
+		match["category"] = search.Category
+	}
+	if len(search.Skills) > 0 {
+		operator := "$in"
+		if search.MatchAllSkills {
+			operator = "$all"
+		}
+		match["required_skills"] = bson.M{operator: search.Skills}
+	}
+	if budget := rangeQuery(search.MinBudget, search.MaxBudget); budget != nil {
+		match["budget"] = budget
+	}
+	if deadline := rangeQuery(search.DeadlineAfter, search.DeadlineBefore); deadline != nil {
+		match["deadline"] = deadline
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: match}}}
+	if search.Query != "" {
+		pipeline = append(pipeline, bson.D{{Key: "$addFields", Value: bson.M{"score": bson.M{"$meta": "textScore"}}}})
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$facet", Value: bson.M{
+		"tasks": page,
+		"total": bson.A{bson.M{"$count": "count"}},
+		"categories": bson.A{
+			bson.M{"$match": bson.M{"category": bson.M{"$nin": bson.A{nil, ""}}}},
+			bson.M{"$group": bson.M{"_id": "$category", "count": bson.M{"$sum": 1}}},
+			bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		},
+		"skills": bson.A{
+			bson.M{"$unwind": "$required_skills"},
+			bson.M{"$group": bson.M{"_id": "$required_skills", "count": bson.M{"$sum": 1}}},
+			bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		},
+	}}})
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, mongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		Tasks []scoredTask `bson:"tasks"`
+		Total []struct {
+			Count int64 `bson:"count"`
+		} `bson:"total"`
+		Categories []FacetCount `bson:"categories"`
+		Skills     []FacetCount `bson:"skills"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	result := &TaskSearchResult{
+		Page:   pagination.Page[models.Task]{Items: []models.Task{}},
+		Facets: TaskFacets{Categories: []FacetCount{}, Skills: []FacetCount{}},
+	}
+	if len(rows) == 0 {
+		return result, nil
+	}
+	row := rows[0]
+	result.Page = taskSearchPage(row.Tasks, search)
+	if len(row.Total) > 0 {
+		result.Total = row.Total[0].Count
+	}
+	if row.Categories != nil {
+		result.Facets.Categories = row.Categories
+	}
+	if row.Skills != nil {
+		result.Facets.Skills = row.Skills
+	}
+	return result, nil
+}
+
+// taskSearchKey is one field of a search ordering.
+type taskSearchKey struct {
+	field      string // "score" is the text relevance
+	descending bool
+}
+
+// taskSearchKeys is the ordering of a search. It always ends with _id, so
+// every task has a distinct position a page cursor can resume from.
+func taskSearchKeys(search TaskSearch) []taskSearchKey {
+	switch search.Sort {
+	case TaskSortRelevance:
+		if search.Query != "" {
+			return []taskSearchKey{{"score", true}, {"created_at", true}, {"_id", true}}
+		}
+	case TaskSortDeadline:
+		return []taskSearchKey{{"deadline", false}, {"_id", false}}
+	case TaskSortBudgetHigh:
+		return []taskSearchKey{{"budget", true}, {"_id", true}}
+	case TaskSortBudgetLow:
+		return []taskSearchKey{{"budget", false}, {"_id", false}}
+	}
+	return []taskSearchKey{{"created_at", true}, {"_id", true}}
+}
+
+// cursorValue is the key's value recorded in cursor, or nil when the cursor
+// came from an ordering without this key.
+func (key taskSearchKey) cursorValue(cursor pagination.Cursor) any {
+	switch key.field {
+	case "score", "budget":
+		if cursor.Number != nil {
+			return *cursor.Number
+		}
+	case "deadline":
+		if cursor.Time != nil {
+			return *cursor.Time
+		}
+	case "created_at":
+		return cursor.CreatedAt
+	case "_id":
+		return cursor.ID
+	}
+	return nil
+}
+
+// taskSearchCursor is the position of a task in the search ordering. score
+// is its text relevance, which only relevance searches use.
+func taskSearchCursor(search TaskSearch, task models.Task, score float64) pagination.Cursor {
+	cursor := taskCursor(task)
+	switch taskSearchKeys(search)[0].field {
+	case "score":
+		cursor.Number = &score
+	case "budget":
+		budget := task.Budget
+		cursor.Number = &budget
+	case "deadline":
+		deadline := task.Deadline
+		cursor.Time = &deadline
+	}
+	return cursor
+}
+
+// scoredTask is a search hit with its text relevance.
+type scoredTask struct {
+	models.Task `bson:",inline"`
+	Score       float64 `bson:"score"`
+}
+
+// taskSearchPage builds a page from up to Page.Limit+1 hits.
+func taskSearchPage(hits []scoredTask, search TaskSearch) pagination.Page[models.Task] {
+	page := pagination.NewPage(hits, search.Page, func(hit scoredTask) pagination.Cursor {
+		return taskSearchCursor(search, hit.Task, hit.Score)
+	})
+	tasks := make([]models.Task, len(page.Items))
+	for i := range page.Items {
+		tasks[i] = page.Items[i].Task
+	}
+	return pagination.Page[models.Task]{Items: tasks, NextCursor: page.NextCursor, HasMore: page.HasMore}
+}
+
+// checkTaskSearchCursor rejects a cursor returned for another ordering.
+func checkTaskSearchCursor(keys []taskSearchKey, cursor pagination.Cursor) error {
+	for _, key := range keys {
+		if key.cursorValue(cursor) == nil {
+			return pagination.ErrInvalidCursor
+		}
+	}
+	return nil
+}
+
+// taskSearchAfter matches the tasks ordered strictly after cursor.
+func taskSearchAfter(keys []taskSearchKey, cursor pagination.Cursor) (bson.M, error) {
+	if err := checkTaskSearchCursor(keys, cursor); err != nil {
+		return nil, err
+	}
+
+	var after bson.A
+	equal := bson.M{}
+	for _, key := range keys {
+		value := key.cursorValue(cursor)
+		compare := "$gt"
+		if key.descending {
+			compare = "$lt"
+		}
+		clause := bson.M{key.field: bson.M{compare: value}}
+		for field, value := range equal {
+			clause[field] = value
+		}
+		after = append(after, clause)
+		equal[key.field] = value
+	}
+	return bson.M{"$or": after}, nil
+}
+
+func taskSearchSort(keys []taskSearchKey) bson.D {
+	sort := bson.D{}
+	for _, key := range keys {
+		direction := 1
+		if key.descending {
+			direction = -1
+		}
+		sort = append(sort, bson.E{Key: key.field, Value: direction})
+	}
+	return sort
+}
+
+// rangeQuery builds a $gte/$lte condition from optional bounds, or nil when
+// both are unset.
+func rangeQuery[T any](min, max *T) bson.M {
+	if min == nil && max == nil {
+		return nil
+	}
+	query := bson.M{}
+	if min != nil {
+		query["$gte"] = *min
+	}
+	if max != nil {
+		query["$lte"] = *max
+	}
+	return query
+}
+
+func (r *mongoTaskRepository) Update(ctx context.Context, task *models.Task) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": task.ID}, task)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoTaskRepository) UpdateIfStatus(ctx context.Context, task *models.Task, expectedStatus string) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": task.ID, "status": expectedStatus}, task)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoTaskRepository) UpdateIfUnchanged(ctx context.Context, task *models.Task, lastUpdatedAt time.Time) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": task.ID, "updated_at": lastUpdatedAt}, task)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoTaskRepository) FindByDeadline(ctx context.Context, filter TaskDeadlineFilter) ([]models.Task, error) {
+	deadline := bson.M{"$lt": filter.Before}
+	if !filter.After.IsZero() {
+		deadline["$gt"] = filter.After
+	}
+	query := bson.M{"status": bson.M{"$in": filter.Statuses}, "deadline": deadline}
+	if filter.NotOverdue {
+		query["overdue_at"] = nil
+	}
+	if filter.NotReminded != 0 {
+		query["reminders"] = bson.M{"$ne": filter.NotReminded}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "deadline", Value: 1}}).
+		SetLimit(int64(filter.Limit))
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	tasks := []models.Task{}
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (r *mongoTaskRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoTaskRepository) FindBiddingDue(ctx context.Context, at time.Time, limit int) ([]models.Task, error) {
+	query := bson.M{
+		"status":            models.TaskStatusOpen,
+		"bidding_closes_at": bson.M{"$lte": at},
+		"bidding_closed_at": nil,
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "bidding_closes_at", Value: 1}}).
+		SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	tasks := []models.Task{}
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (r *mongoTaskRepository) FreelancerHistories(ctx context.Context, freelancerIDs []primitive.ObjectID) ([]FreelancerHistory, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"status": models.TaskStatusCompleted, "freelancer_id": bson.M{"$in": freelancerIDs}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":               bson.M{"freelancer_id": "$freelancer_id", "category": "$category"},
+			"completed":         bson.M{"$sum": 1},
+			"on_time":           bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$overdue_at", nil}}, 0, 1}}},
+			"budget":            bson.M{"$sum": "$budget"},
+			"last_completed_at": bson.M{"$max": "$completed_at"},
+			"client_ids":        bson.M{"$addToSet": "$client_id"},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":               "$_id.freelancer_id",
+			"completed":         bson.M{"$sum": "$completed"},
+			"on_time":           bson.M{"$sum": "$on_time"},
+			"budget":            bson.M{"$sum": "$budget"},
+			"last_completed_at": bson.M{"$max": "$last_completed_at"},
+			"categories":        bson.M{"$push": bson.M{"category": "$_id.category", "count": "$completed"}},
+			"client_ids":        bson.M{"$push": "$client_ids"},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"completed":         1,
+			"on_time":           1,
+			"average_budget":    bson.M{"$divide": bson.A{"$budget", "$completed"}},
+			"last_completed_at": 1,
+			"categories":        1,
+			"client_ids": bson.M{"$reduce": bson.M{
+				"input":        "$client_ids",
+				"initialValue": bson.A{},
+				"in":           bson.M{"$setUnion": bson.A{"$$value", "$$this"}},
+			}},
+		}}},
+	}
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	histories := []FreelancerHistory{}
+	if err := cursor.All(ctx, &histories); err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Transactor runs a unit of work atomically. Repository calls made with the
+// context passed to fn take part in the transaction; fn returning an error
+// aborts it. fn may be called more than once when the transaction is
+// retried after a conflict, so it must not have effects outside the
+// repositories.
+type Transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type mongoTransactor struct {
+	client *mongo.Client
+}
+
+// NewMongoTransactor runs transactions on the database's client. MongoDB
+// only supports transactions on replica sets and sharded clusters.
+func NewMongoTransactor(db *mongo.Database) Transactor {
+	return &mongoTransactor{client: db.Client()}
+}
+
+func (t *mongoTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := t.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	// The driver retries fn on transient errors such as a write conflict
+	// with a concurrent transaction.
+	_, err = session.WithTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessionCtx)
+	})
+	return err
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/pagination"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// UserFilter narrows a user search. Query matches email, first or last name
+// case-insensitively; empty fields are ignored.
+type UserFilter struct {
+	Query     string
+	UserType  string
+	Suspended *bool
+	Skills    []string // users with any of these skills, ignoring case
+}
+
+type UserRepository interface {
+	Create(ctx context.Context, user *models.User) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	Update(ctx context.Context, user *models.User) error
+	CountByType(ctx context.Context, userType string) (int64, error)
+	Search(ctx context.Context, filter UserFilter, page pagination.Params) (*pagination.Page[models.User], error)
+}
+
+type mongoUserRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoUserRepository(db *mongo.Database) UserRepository {
+	return &mongoUserRepository{collection: db.Collection("users")}
+}
+
+func (r *mongoUserRepository) Create(ctx context.Context, user *models.User) error {
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, user)
+	return mongoError(err)
+}
+
+func (r *mongoUserRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	var user models.User
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&user); err != nil {
+		return nil, mongoError(err)
+	}
+	return &user, nil
+}
+
+func (r *mongoUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	if err := r.collection.FindOne(ctx, bson.M{"email": email}).Decode(&user); err != nil {
+		return nil, mongoError(err)
+	}
+	return &user, nil
+}
+
+func (r *mongoUserRepository) Update(ctx context.Context, user *models.User) error {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": user.ID}, user)
+	if err != nil {
+		return mongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoUserRepository) CountByType(ctx context.Context, userType string) (int64, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"user_type": userType})
+	return count, mongoError(err)
+}
+
+func (r *mongoUserRepository) Search(ctx context.Context, filter UserFilter, page pagination.Params) (*pagination.Page[models.User], error) {
+	query := bson.M{}
+	if filter.Query != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(filter.Query), Options: "i"}
+		query["$or"] = bson.A{
+			bson.M{"email": pattern},
+			bson.M{"first_name": pattern},
+			bson.M{"last_name": pattern},
+		}
+	}
+	if filter.UserType != "" {
+		query["user_type"] = filter.UserType
+	}
+	if filter.Suspended != nil {
+		query["suspended_at"] = bson.M{"$exists": *filter.Suspended}
+	}
+	if skills := models.NormalizeSkills(filter.Skills); len(skills) > 0 {
+		patterns := make(bson.A, len(skills))
+		for i, skill := range skills {
+			patterns[i] = primitive.Regex{Pattern: `^\s*` + regexp.QuoteMeta(skill) + `\s*$`, Options: "i"}
+		}
+		query["skills"] = bson.M{"$in": patterns}
+	}
+
+	return findPage(ctx, r.collection, query, page, true, userCursor)
+}
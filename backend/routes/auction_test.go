@@ -0,0 +1,65 @@
+package routes
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestReverseAuctionUndercut(t *testing.T) {
+	api := newTestAPI(t)
+	client, _ := api.register("ada@example.com", "client")
+	freelancers := map[string]string{}
+	for _, name := range []string{"bob", "eve", "dan"} {
+		freelancers[name], _ = api.register(name+"@example.com", "freelancer")
+	}
+
+	auction := func(mode string) string {
+		return api.createTask(client, map[string]any{
+			"bidding_mode":      mode,
+			"bidding_closes_at": time.Now().Add(3 * 24 * time.Hour).Format(time.RFC3339),
+		})
+	}
+	reverse := auction("reverse_auction")
+	sealed := auction("sealed")
+
+	bidIDs := map[string]string{}
+	tests := []struct {
+		name       string
+		freelancer string
+		task       string
+		update     bool // revise the freelancer's existing bid instead
+		amount     float64
+		want       int
+	}{
+		{"first bid sets the price", "bob", reverse, false, 400, http.StatusCreated},
+		{"matching the lowest bid", "eve", reverse, false, 400, http.StatusConflict},
+		{"above the lowest bid", "eve", reverse, false, 450, http.StatusConflict},
+		{"a cent below the lowest bid", "eve", reverse, false, 399.99, http.StatusCreated},
+		{"revising to the lowest bid", "bob", reverse, true, 399.99, http.StatusConflict},
+		{"revising below the lowest bid", "bob", reverse, true, 390, http.StatusOK},
+		{"revising without changing the amount", "eve", reverse, true, 399.99, http.StatusOK},
+		{"sealed bids need not undercut", "bob", sealed, false, 400, http.StatusCreated},
+		{"second sealed bid above the first", "dan", sealed, false, 500, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api.t = t
+			body := map[string]any{
+				"amount":            tt.amount,
+				"cover_letter":      "I have built many pages like this one",
+				"proposed_deadline": time.Now().Add(5 * 24 * time.Hour).Format(time.RFC3339),
+			}
+			token := freelancers[tt.freelancer]
+			if tt.update {
+				api.call(http.MethodPut, "/api/v1/bids/"+bidIDs[tt.freelancer+tt.task], token, body, tt.want)
+				return
+			}
+			body["task_id"] = tt.task
+			out := api.call(http.MethodPost, "/api/v1/bids", token, body, tt.want)
+			if tt.want == http.StatusCreated {
+				bidIDs[tt.freelancer+tt.task] = out["bid"].(map[string]any)["id"].(string)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+)
+
+// collectPages follows next_cursor from path until has_more is false and
+// returns the IDs of every item in order.
+func (api *testAPI) collectPages(path, token string, limit int) []string {
+	api.t.Helper()
+
+	var ids []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 20 {
+			api.t.Fatalf("%s did not stop paging", path)
+		}
+		query := url.Values{"limit": {fmt.Sprint(limit)}}
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+		separator := "?"
+		if slices.Contains([]byte(path), '?') {
+			separator = "&"
+		}
+		out := api.call(http.MethodGet, path+separator+query.Encode(), token, nil, http.StatusOK)
+
+		items := out["items"].([]any)
+		if len(items) > limit {
+			api.t.Fatalf("page has %d items, limit is %d", len(items), limit)
+		}
+		for _, item := range items {
+			ids = append(ids, item.(map[string]any)["id"].(string))
+		}
+		if out["has_more"] != true {
+			return ids
+		}
+		cursor = out["next_cursor"].(string)
+	}
+}
+
+func TestSearchTasksPaging(t *testing.T) {
+	api := newTestAPI(t)
+	client, _ := api.register("ada@example.com", "client")
+
+	titles := []string{"Landing page", "Landing page and landing form", "Checkout page", "Landing copy", "Blog theme"}
+	budgets := []float64{300, 800, 800, 150, 500}
+	for i, title := range titles {
+		api.createTask(client, map[string]any{
+			"title":       title,
+			"description": "Details are in the attached brief",
+			"budget":      budgets[i],
+			"deadline":    time.Now().Add(time.Duration(10-i) * 24 * time.Hour).Format(time.RFC3339),
+		})
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{"newest", "sort=newest", 5},
+		{"deadline", "sort=deadline", 5},
+		{"budget high", "sort=budget_high", 5},
+		{"budget low", "sort=budget_low", 5},
+		{"relevance", "q=landing&sort=relevance", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api.t = t
+			path := "/api/v1/tasks/search?" + tt.query
+			all := api.collectPages(path, client, 100)
+			if len(all) != tt.want {
+				t.Fatalf("single page has %d tasks, want %d", len(all), tt.want)
+			}
+			for _, limit := range []int{1, 2} {
+				if paged := api.collectPages(path, client, limit); !slices.Equal(paged, all) {
+					t.Errorf("pages of %d = %v, want %v", limit, paged, all)
+				}
+			}
+
+			out := api.call(http.MethodGet, path+"&limit=1", client, nil, http.StatusOK)
+			if out["total"] != float64(tt.want) || out["facets"] == nil {
+				t.Errorf("first page total = %v facets = %v, want the counts over every match", out["total"], out["facets"])
+			}
+		})
+	}
+
+	t.Run("cursor from another sort", func(t *testing.T) {
+		api.t = t
+		out := api.call(http.MethodGet, "/api/v1/tasks/search?sort=budget_high&limit=1", client, nil, http.StatusOK)
+		cursor := url.QueryEscape(out["next_cursor"].(string))
+		api.call(http.MethodGet, "/api/v1/tasks/search?sort=deadline&cursor="+cursor, client, nil, http.StatusBadRequest)
+	})
+}
+
+func TestTaskConversationsPaging(t *testing.T) {
+	api := newTestAPI(t)
+	client, _ := api.register("ada@example.com", "client")
+	taskID := api.createTask(client, nil)
+
+	var started []string
+	for i := 0; i < 3; i++ {
+		freelancer, _ := api.register(fmt.Sprintf("f%d@example.com", i), "freelancer")
+		api.placeBid(freelancer, taskID, 400)
+		out := api.call(http.MethodPost, "/api/v1/tasks/"+taskID+"/conversations", freelancer, nil, http.StatusCreated)
+		started = append(started, out["id"].(string))
+		time.Sleep(time.Millisecond)
+	}
+	slices.Reverse(started)
+
+	path := "/api/v1/tasks/" + taskID + "/conversations"
+	if paged := api.collectPages(path, client, 2); !slices.Equal(paged, started) {
+		t.Errorf("conversations = %v, want most recently active first %v", paged, started)
+	}
+
+	// Creation-ordered cursors from other lists are rejected
+	api.createTask(client, nil)
+	out := api.call(http.MethodGet, "/api/v1/tasks/search?sort=newest&limit=1", client, nil, http.StatusOK)
+	api.call(http.MethodGet, path+"?cursor="+url.QueryEscape(out["next_cursor"].(string)), client, nil, http.StatusBadRequest)
+}
@@ -3,13 +3,102 @@ package routes
 import (
 	"os"
 
+	"github.com/Vivekpdy/tasklanceweb/backend/auctions"
+	"github.com/Vivekpdy/tasklanceweb/backend/chat"
+	"github.com/Vivekpdy/tasklanceweb/backend/config"
 	"github.com/Vivekpdy/tasklanceweb/backend/controllers"
+	"github.com/Vivekpdy/tasklanceweb/backend/deadlines"
+	"github.com/Vivekpdy/tasklanceweb/backend/escrow"
+	"github.com/Vivekpdy/tasklanceweb/backend/gateway"
+	"github.com/Vivekpdy/tasklanceweb/backend/jobs"
+	"github.com/Vivekpdy/tasklanceweb/backend/mailer"
 	"github.com/Vivekpdy/tasklanceweb/backend/middleware"
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/notify"
+	"github.com/Vivekpdy/tasklanceweb/backend/policy"
+	"github.com/Vivekpdy/tasklanceweb/backend/recommend"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter() *gin.Engine {
-	router := gin.Default()
+// Dependencies are the collaborators NewRouter wires into the handlers.
+type Dependencies struct {
+	Repos    *repository.Repositories
+	Mailer   mailer.Mailer
+	Gateway  gateway.PaymentGateway
+	Queue    *jobs.Queue     // built from Repos when nil
+	Notifier *notify.Service // built from Repos, Mailer and Queue when nil
+}
+
+// ProductionDependencies returns the MongoDB-backed dependencies configured
+// from the environment, or an error if that configuration is invalid.
+func ProductionDependencies() (Dependencies, error) {
+	paymentGateway, err := gateway.NewFromEnv()
+	if err != nil {
+		return Dependencies{}, err
+	}
+	deps := Dependencies{
+		Repos:   repository.NewMongoRepositories(config.MongoDB),
+		Mailer:  mailer.NewFromEnv(),
+		Gateway: paymentGateway,
+	}
+	deps.Queue = jobs.NewQueue(deps.Repos.Jobs, jobs.ConfigFromEnv())
+	deps.Notifier = newNotifier(deps)
+	return deps, nil
+}
+
+// SetupRouter builds the production router backed by MongoDB.
+func SetupRouter() (*gin.Engine, error) {
+	deps, err := ProductionDependencies()
+	if err != nil {
+		return nil, err
+	}
+	return NewRouter(deps), nil
+}
+
+func newNotifier(deps Dependencies) *notify.Service {
+	repos := deps.Repos
+	return notify.NewService(repos.Notifications, repos.NotificationPreferences, repos.Users, deps.Mailer, deps.Queue, notify.EmailConfigFromEnv())
+}
+
+// NewRouter builds the API router on top of the given dependencies, so the
+// same routes can be served from MongoDB or from the in-memory stores.
+func NewRouter(deps Dependencies) *gin.Engine {
+	repos := deps.Repos
+
+	authController := controllers.NewAuthController(repos.Users, repos.Sessions, repos.PasswordResets, deps.Mailer)
+	userController := controllers.NewUserController(repos.Users, repos.Sessions)
+	if deps.Queue == nil {
+		deps.Queue = jobs.NewQueue(repos.Jobs, jobs.ConfigFromEnv())
+	}
+	notifier := deps.Notifier
+	if notifier == nil {
+		notifier = newNotifier(deps)
+	}
+	// The deadline scheduler has no routes; it runs from the job queue
+	deadlines.NewService(repos.Tasks, repos.Bids, notifier, deps.Queue, deadlines.ConfigFromEnv())
+	auctions.NewService(repos.Tasks, repos.Bids, repos.Transactor, notifier, deps.Queue, auctions.ConfigFromEnv())
+	escrowService := escrow.NewService(repos.Payments, repos.Ledger, repos.Tasks, repos.Milestones, deps.Gateway, notifier, deps.Queue, escrow.FeePercentFromEnv())
+	recommender := recommend.NewService(repos.Tasks, repos.Bids, repos.Users, recommend.ConfigFromEnv())
+
+	taskController := controllers.NewTaskController(repos.Tasks, repos.Bids, repos.Milestones, repos.Transactor, escrowService)
+	bidController := controllers.NewBidController(repos.Bids, repos.Tasks, repos.Milestones, repos.Transactor, escrowService, notifier)
+	milestoneController := controllers.NewMilestoneController(repos.Milestones, repos.Tasks, repos.Transactor, escrowService)
+	reviewController := controllers.NewReviewController(repos.Reviews, repos.Tasks, repos.Users, notifier)
+	paymentController := controllers.NewPaymentController(repos.Payments, repos.Tasks, escrowService)
+	adminController := controllers.NewAdminController(repos.Users, repos.Sessions, repos.Tasks, repos.Reviews, repos.AdminActions, repos.Jobs, repos.Transactor, escrowService)
+	webhookController := controllers.NewWebhookController(repos.Payments, repos.PaymentEvents, deps.Gateway, escrowService)
+	chatController := controllers.NewChatController(repos.Conversations, repos.Messages, repos.Tasks, repos.Bids, chat.NewHub())
+	notificationController := controllers.NewNotificationController(repos.Notifications, repos.NotificationPreferences, notifier)
+	recommendationController := controllers.NewRecommendationController(recommender, repos.Tasks, repos.Users)
+
+	// Authorization rules live in the policy package; routes only declare them
+	can := middleware.RequirePermission
+	verified := middleware.RequireVerified(repos.Users)
+	idempotent := middleware.Idempotency(repos.IdempotencyKeys, middleware.IdempotencyKeyTTL())
+
+	router := gin.New()
+	router.Use(middleware.Logger(), gin.Recovery())
 
 	// CORS middleware
 	router.Use(middleware.CORSMiddleware())
@@ -28,54 +117,156 @@ func SetupRouter() *gin.Engine {
 		// Public routes
 		auth := v1.Group("/auth")
 		{
-			auth.POST("/register", controllers.Register)
-			auth.POST("/login", controllers.Login)
+			auth.POST("/register", authController.Register)
+			auth.POST("/login", authController.Login)
+			auth.POST("/refresh", authController.Refresh)
+			auth.GET("/verify", authController.VerifyEmail)
+			auth.POST("/verify", authController.VerifyEmail)
+			auth.POST("/resend-verification", authController.ResendVerification)
+			auth.POST("/forgot-password", authController.ForgotPassword)
+			auth.POST("/reset-password", authController.ResetPassword)
+		}
+
+		// Payment provider webhooks, authenticated by their signature
+		v1.POST("/webhooks/payments/:provider", webhookController.PaymentWebhook)
+
+		// Streams, which also accept the access token as a query parameter
+		streams := v1.Group("")
+		streams.Use(middleware.StreamAuthMiddleware(repos.Sessions, repos.Users))
+		{
+			streams.GET("/conversations/:id/ws", chatController.Connect)
+			streams.GET("/notifications/stream", notificationController.StreamNotifications)
 		}
 
 		// Protected routes
 		protected := v1.Group("")
-		protected.Use(middleware.AuthMiddleware())
+		protected.Use(middleware.AuthMiddleware(repos.Sessions, repos.Users))
 		{
+			// Session routes
+			session := protected.Group("/auth")
+			{
+				session.POST("/logout", authController.Logout)
+				session.POST("/logout-all", authController.LogoutAll)
+			}
+
 			// User routes
 			users := protected.Group("/users")
 			{
-				users.GET("/me", controllers.GetCurrentUser)
-				users.PUT("/me", controllers.UpdateUser)
-				users.GET("/:id", controllers.GetUser)
+				users.GET("/me", userController.GetCurrentUser)
+				users.PUT("/me", userController.UpdateUser)
+				users.PUT("/me/password", userController.ChangePassword)
+				users.GET("/:id", userController.GetUser)
 			}
 
 			// Task routes
 			tasks := protected.Group("/tasks")
 			{
-				tasks.GET("", controllers.GetTasks)
-				tasks.GET("/:id", controllers.GetTask)
-				tasks.POST("", controllers.CreateTask)
-				tasks.PUT("/:id", controllers.UpdateTask)
-				tasks.DELETE("/:id", controllers.DeleteTask)
+				tasks.GET("", taskController.GetTasks)
+				tasks.GET("/search", taskController.SearchTasks)
+				tasks.GET("/recommended", can(policy.RecommendTasks), recommendationController.GetRecommendedTasks)
+				tasks.GET("/:id", taskController.GetTask)
+				tasks.POST("", can(policy.CreateTask), verified, idempotent, taskController.CreateTask)
+				tasks.PUT("/:id", can(policy.UpdateTask), taskController.UpdateTask)
+				tasks.DELETE("/:id", can(policy.DeleteTask), taskController.DeleteTask)
+
+				// Lifecycle transitions
+				tasks.POST("/:id/submit", can(policy.SubmitWork), taskController.SubmitWork)
+				tasks.POST("/:id/request-revision", can(policy.ReviewWork), taskController.RequestRevision)
+				tasks.POST("/:id/approve", can(policy.ReviewWork), taskController.ApproveCompletion)
+				tasks.POST("/:id/cancel", can(policy.CancelTask), taskController.CancelTask)
+
+				// Milestones
+				tasks.GET("/:id/milestones", milestoneController.GetTaskMilestones)
+				tasks.POST("/:id/milestones", can(policy.ManageMilestones), milestoneController.CreateMilestone)
+
+				// Chat
+				tasks.GET("/:id/conversations", chatController.GetTaskConversations)
+				tasks.POST("/:id/conversations", chatController.StartConversation)
+
+				// Recommendations
+				tasks.GET("/:id/suggested-freelancers", can(policy.SuggestFreelancers), recommendationController.GetSuggestedFreelancers)
+			}
+
+			// Conversation routes
+			conversations := protected.Group("/conversations")
+			{
+				conversations.GET("/:id/messages", chatController.GetMessages)
+				conversations.POST("/:id/messages", chatController.SendMessage)
+				conversations.POST("/:id/read", chatController.MarkRead)
+			}
+
+			// Milestone routes
+			milestones := protected.Group("/milestones")
+			{
+				milestones.PUT("/:id", can(policy.ManageMilestones), milestoneController.UpdateMilestone)
+				milestones.DELETE("/:id", can(policy.ManageMilestones), milestoneController.DeleteMilestone)
+				milestones.POST("/:id/fund", can(policy.CreatePayment), milestoneController.FundMilestone)
+				milestones.POST("/:id/submit", can(policy.SubmitWork), milestoneController.SubmitMilestone)
+				milestones.POST("/:id/request-revision", can(policy.ReviewWork), milestoneController.RequestMilestoneRevision)
+				milestones.POST("/:id/approve", can(policy.ReviewWork), milestoneController.ApproveMilestone)
 			}
 
 			// Bid routes
 			bids := protected.Group("/bids")
 			{
-				bids.GET("/task/:taskId", controllers.GetTaskBids)
-				bids.POST("", controllers.CreateBid)
-				bids.PUT("/:id", controllers.UpdateBid)
-				bids.POST("/:id/accept", controllers.AcceptBid)
+				bids.GET("/task/:taskId", bidController.GetTaskBids)
+				bids.GET("/task/:taskId/compare", can(policy.TriageBids), bidController.CompareBids)
+				bids.POST("", can(policy.CreateBid), verified, idempotent, bidController.CreateBid)
+				bids.PUT("/:id", can(policy.UpdateBid), bidController.UpdateBid)
+				bids.POST("/:id/accept", can(policy.AcceptBid), bidController.AcceptBid)
+				bids.POST("/:id/withdraw", can(policy.WithdrawBid), bidController.WithdrawBid)
+				bids.POST("/:id/counter", can(policy.CounterBid), bidController.CounterBid)
+				bids.POST("/:id/counter/accept", can(policy.AnswerBid), bidController.AcceptCounter)
+				bids.POST("/:id/counter/decline", can(policy.AnswerBid), bidController.DeclineCounter)
+				bids.PUT("/:id/triage", can(policy.TriageBids), bidController.TriageBid)
+			}
+
+			// Notification routes
+			notifications := protected.Group("/notifications")
+			{
+				notifications.GET("", notificationController.GetNotifications)
+				notifications.GET("/unread-count", notificationController.GetUnreadCount)
+				notifications.GET("/preferences", notificationController.GetNotificationPreferences)
+				notifications.PUT("/preferences", notificationController.UpdateNotificationPreferences)
+				notifications.POST("/read-all", notificationController.MarkAllNotificationsRead)
+				notifications.POST("/:id/read", notificationController.MarkNotificationRead)
 			}
 
 			// Review routes
 			reviews := protected.Group("/reviews")
 			{
-				reviews.GET("/user/:userId", controllers.GetUserReviews)
-				reviews.POST("", controllers.CreateReview)
+				reviews.GET("/user/:userId", reviewController.GetUserReviews)
+				reviews.POST("", can(policy.CreateReview), reviewController.CreateReview)
 			}
 
 			// Payment routes
 			payments := protected.Group("/payments")
 			{
-				payments.GET("/task/:taskId", controllers.GetTaskPayments)
-				payments.POST("", controllers.CreatePayment)
-				payments.PUT("/:id", controllers.UpdatePayment)
+				payments.GET("/balance", paymentController.GetBalance)
+				payments.GET("/task/:taskId", paymentController.GetTaskPayments)
+				payments.POST("", can(policy.CreatePayment), idempotent, paymentController.CreatePayment)
+				payments.PUT("/:id", can(policy.UpdatePayment), paymentController.UpdatePayment)
+			}
+
+			// Admin routes
+			admin := protected.Group("/admin")
+			admin.Use(middleware.RequireRole(models.RoleAdmin), can(policy.AdminAccess))
+			{
+				admin.GET("/permissions", adminController.GetPermissions)
+				admin.GET("/actions", adminController.ListActions)
+
+				admin.GET("/users", adminController.ListUsers)
+				admin.POST("/users/:id/suspend", adminController.SuspendUser)
+				admin.POST("/users/:id/reinstate", adminController.ReinstateUser)
+
+				admin.POST("/tasks/:id/cancel", adminController.CancelTask)
+
+				admin.POST("/reviews/:id/hide", adminController.HideReview)
+				admin.POST("/reviews/:id/unhide", adminController.UnhideReview)
+
+				admin.GET("/jobs", adminController.ListJobs)
+				admin.GET("/jobs/:id", adminController.GetJob)
+				admin.POST("/jobs/:id/retry", adminController.RetryJob)
 			}
 		}
 	}
@@ -0,0 +1,433 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/gateway"
+	"github.com/Vivekpdy/tasklanceweb/backend/mailer"
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+	"github.com/Vivekpdy/tasklanceweb/backend/repository"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// testWebhookSecret signs webhooks sent to the test API's fake gateway.
+const testWebhookSecret = "whsec_test"
+
+// testAPI is the router served from the in-memory stores.
+type testAPI struct {
+	t       *testing.T
+	router  *gin.Engine
+	repos   *repository.Repositories
+	mail    *mailer.MemoryMailer
+	gateway *gateway.Fake
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret")
+	gin.SetMode(gin.TestMode)
+
+	api := &testAPI{
+		t:       t,
+		repos:   repository.NewMemoryRepositories(),
+		mail:    mailer.NewMemoryMailer(),
+		gateway: gateway.NewFake(gateway.WebhookConfig{Secret: testWebhookSecret, Tolerance: 5 * time.Minute}),
+	}
+	api.router = NewRouter(Dependencies{Repos: api.repos, Mailer: api.mail, Gateway: api.gateway})
+	return api
+}
+
+// serve sends a JSON request with an optional bearer token.
+func (api *testAPI) serve(method, path, token string, body any, header http.Header) *httptest.ResponseRecorder {
+	api.t.Helper()
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			api.t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	api.router.ServeHTTP(w, req)
+	return w
+}
+
+// call sends a request, fails the test unless it answers with want and
+// returns the decoded response body.
+func (api *testAPI) call(method, path, token string, body any, want int) map[string]any {
+	api.t.Helper()
+
+	w := api.serve(method, path, token, body, nil)
+	if w.Code != want {
+		api.t.Fatalf("%s %s = %d %s, want %d", method, path, w.Code, w.Body.String(), want)
+	}
+	var out map[string]any
+	if w.Body.Len() > 0 {
+		if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+			api.t.Fatalf("%s %s: %v", method, path, err)
+		}
+	}
+	return out
+}
+
+var verificationLink = regexp.MustCompile(`token=(\S+)`)
+
+// register signs up a verified user and returns its access token and ID.
+func (api *testAPI) register(email, userType string) (token, id string) {
+	api.t.Helper()
+
+	out := api.call(http.MethodPost, "/api/v1/auth/register", "", map[string]any{
+		"email":      email,
+		"password":   "secret1",
+		"first_name": "Test",
+		"last_name":  "User",
+		"user_type":  userType,
+	}, http.StatusCreated)
+
+	messages := api.mail.Messages()
+	match := verificationLink.FindStringSubmatch(messages[len(messages)-1].TextBody)
+	if match == nil {
+		api.t.Fatalf("no verification link mailed to %s", email)
+	}
+	api.call(http.MethodGet, "/api/v1/auth/verify?token="+url.QueryEscape(match[1]), "", nil, http.StatusOK)
+
+	return out["token"].(string), out["user"].(map[string]any)["id"].(string)
+}
+
+// createTask posts an open task as client and returns its ID.
+func (api *testAPI) createTask(client string, extra map[string]any) string {
+	api.t.Helper()
+
+	body := map[string]any{
+		"title":       "Build a landing page",
+		"description": "A responsive landing page with a signup form",
+		"category":    "web",
+		"budget":      500,
+		"deadline":    time.Now().Add(7 * 24 * time.Hour).Format(time.RFC3339),
+	}
+	for key, value := range extra {
+		body[key] = value
+	}
+	out := api.call(http.MethodPost, "/api/v1/tasks", client, body, http.StatusCreated)
+	return out["task"].(map[string]any)["id"].(string)
+}
+
+// placeBid bids amount on a task as freelancer and returns the bid's ID.
+func (api *testAPI) placeBid(freelancer, taskID string, amount float64) string {
+	api.t.Helper()
+
+	out := api.call(http.MethodPost, "/api/v1/bids", freelancer, map[string]any{
+		"task_id":           taskID,
+		"amount":            amount,
+		"cover_letter":      "I have built many pages like this one",
+		"proposed_deadline": time.Now().Add(5 * 24 * time.Hour).Format(time.RFC3339),
+	}, http.StatusCreated)
+	return out["bid"].(map[string]any)["id"].(string)
+}
+
+func objectID(t *testing.T, hex string) primitive.ObjectID {
+	t.Helper()
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestRegister(t *testing.T) {
+	api := newTestAPI(t)
+
+	tests := []struct {
+		name string
+		body map[string]any
+		want int
+	}{
+		{"new client", map[string]any{"email": "ada@example.com", "password": "secret1", "first_name": "Ada", "last_name": "L", "user_type": "client"}, http.StatusCreated},
+		{"new freelancer", map[string]any{"email": "bob@example.com", "password": "secret1", "first_name": "Bob", "last_name": "B", "user_type": "freelancer"}, http.StatusCreated},
+		{"duplicate email", map[string]any{"email": "ada@example.com", "password": "other12", "first_name": "Eve", "last_name": "E", "user_type": "freelancer"}, http.StatusConflict},
+		{"admin role", map[string]any{"email": "mallory@example.com", "password": "secret1", "first_name": "M", "last_name": "M", "user_type": "admin"}, http.StatusBadRequest},
+		{"short password", map[string]any{"email": "carol@example.com", "password": "123", "first_name": "C", "last_name": "C", "user_type": "client"}, http.StatusBadRequest},
+		{"missing email", map[string]any{"password": "secret1", "first_name": "D", "last_name": "D", "user_type": "client"}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api.t = t
+			api.call(http.MethodPost, "/api/v1/auth/register", "", tt.body, tt.want)
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	api := newTestAPI(t)
+	api.register("ada@example.com", "client")
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		want     int
+	}{
+		{"valid credentials", "ada@example.com", "secret1", http.StatusOK},
+		{"wrong password", "ada@example.com", "wrong12", http.StatusUnauthorized},
+		{"unknown email", "nobody@example.com", "secret1", http.StatusUnauthorized},
+		{"missing password", "ada@example.com", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api.t = t
+			out := api.call(http.MethodPost, "/api/v1/auth/login", "", map[string]any{"email": tt.email, "password": tt.password}, tt.want)
+			if tt.want == http.StatusOK {
+				token, _ := out["token"].(string)
+				if token == "" {
+					t.Fatal("login returned no access token")
+				}
+				api.call(http.MethodGet, "/api/v1/users/me", token, nil, http.StatusOK)
+			}
+		})
+	}
+}
+
+func TestCreateTask(t *testing.T) {
+	api := newTestAPI(t)
+	client, _ := api.register("ada@example.com", "client")
+	freelancer, _ := api.register("bob@example.com", "freelancer")
+	unverified := api.call(http.MethodPost, "/api/v1/auth/register", "", map[string]any{
+		"email": "carol@example.com", "password": "secret1", "first_name": "C", "last_name": "C", "user_type": "client",
+	}, http.StatusCreated)["token"].(string)
+
+	valid := map[string]any{
+		"title":       "Build a landing page",
+		"description": "A responsive landing page with a signup form",
+		"category":    "web",
+		"budget":      500,
+		"deadline":    time.Now().Add(7 * 24 * time.Hour).Format(time.RFC3339),
+	}
+	tests := []struct {
+		name  string
+		token string
+		body  map[string]any
+		want  int
+	}{
+		{"client", client, valid, http.StatusCreated},
+		{"freelancer", freelancer, valid, http.StatusForbidden},
+		{"unverified client", unverified, valid, http.StatusForbidden},
+		{"anonymous", "", valid, http.StatusUnauthorized},
+		{"missing title", client, map[string]any{"description": "A responsive landing page", "budget": 500}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api.t = t
+			out := api.call(http.MethodPost, "/api/v1/tasks", tt.token, tt.body, tt.want)
+			if tt.want != http.StatusCreated {
+				return
+			}
+			id := out["task"].(map[string]any)["id"].(string)
+			task := api.call(http.MethodGet, "/api/v1/tasks/"+id, tt.token, nil, http.StatusOK)
+			if task["status"] != "open" {
+				t.Errorf("new task status = %v, want open", task["status"])
+			}
+		})
+	}
+}
+
+func TestAcceptBid(t *testing.T) {
+	api := newTestAPI(t)
+	client, _ := api.register("ada@example.com", "client")
+	otherClient, _ := api.register("dan@example.com", "client")
+	bob, bobID := api.register("bob@example.com", "freelancer")
+	eve, _ := api.register("eve@example.com", "freelancer")
+
+	taskID := api.createTask(client, nil)
+	bobBid := api.placeBid(bob, taskID, 450)
+	eveBid := api.placeBid(eve, taskID, 400)
+	funding := map[string]any{"payment_source": gateway.FakeSourceSuccess}
+
+	tests := []struct {
+		name  string
+		token string
+		bidID string
+		want  int
+	}{
+		{"bidder", bob, bobBid, http.StatusForbidden},
+		{"another client", otherClient, bobBid, http.StatusForbidden},
+		{"unknown bid", client, "000000000000000000000000", http.StatusNotFound},
+		{"task owner", client, bobBid, http.StatusOK},
+		{"competing bid", client, eveBid, http.StatusConflict},
+		{"same bid again", client, bobBid, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api.t = t
+			api.call(http.MethodPost, "/api/v1/bids/"+tt.bidID+"/accept", tt.token, funding, tt.want)
+		})
+	}
+
+	api.t = t
+	task := api.call(http.MethodGet, "/api/v1/tasks/"+taskID, client, nil, http.StatusOK)
+	if task["status"] != "in_progress" || task["freelancer_id"] != bobID {
+		t.Errorf("task is %v assigned to %v, want in_progress assigned to %s", task["status"], task["freelancer_id"], bobID)
+	}
+	rejected, err := api.repos.Bids.FindByID(context.Background(), objectID(t, eveBid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rejected.Status != models.BidStatusRejected {
+		t.Errorf("competing bid status = %v, want rejected", rejected.Status)
+	}
+}
+
+// chargeBarrier holds every charge until two have arrived, so two requests
+// that charge are both past their checks before either goes on.
+type chargeBarrier struct {
+	*gateway.Fake
+	mu      sync.Mutex
+	arrived int
+	ready   chan struct{}
+}
+
+func (g *chargeBarrier) CreateCharge(ctx context.Context, req gateway.ChargeRequest) (*gateway.Charge, error) {
+	g.mu.Lock()
+	if g.arrived++; g.arrived == 2 {
+		close(g.ready)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-g.ready:
+	case <-time.After(5 * time.Second):
+	}
+	return g.Fake.CreateCharge(ctx, req)
+}
+
+func TestAcceptBidConcurrently(t *testing.T) {
+	api := newTestAPI(t)
+	api.router = NewRouter(Dependencies{
+		Repos:   api.repos,
+		Mailer:  api.mail,
+		Gateway: &chargeBarrier{Fake: api.gateway, ready: make(chan struct{})},
+	})
+	client, _ := api.register("ada@example.com", "client")
+	bob, _ := api.register("bob@example.com", "freelancer")
+	eve, _ := api.register("eve@example.com", "freelancer")
+
+	taskID := api.createTask(client, nil)
+	bids := []string{api.placeBid(bob, taskID, 450), api.placeBid(eve, taskID, 400)}
+
+	codes := make([]int, len(bids))
+	var wg sync.WaitGroup
+	for i, bidID := range bids {
+		wg.Add(1)
+		go func(i int, bidID string) {
+			defer wg.Done()
+			w := api.serve(http.MethodPost, "/api/v1/bids/"+bidID+"/accept", client, map[string]any{"payment_source": gateway.FakeSourceSuccess}, nil)
+			codes[i] = w.Code
+		}(i, bidID)
+	}
+	wg.Wait()
+
+	winner, loser := 0, 1
+	if codes[1] == http.StatusOK {
+		winner, loser = 1, 0
+	}
+	if codes[winner] != http.StatusOK || codes[loser] != http.StatusConflict {
+		t.Fatalf("accept responses = %v, want one 200 and one 409", codes)
+	}
+
+	ctx := context.Background()
+	task := objectID(t, taskID)
+	held, err := api.repos.Payments.FindByTaskAndStatus(ctx, task, models.PaymentStatusHeld)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refunded, err := api.repos.Payments.FindByTaskAndStatus(ctx, task, models.PaymentStatusRefunded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(held) != 1 || len(refunded) != 1 {
+		t.Fatalf("%d held and %d refunded payments, want the winner's held and the loser's refunded", len(held), len(refunded))
+	}
+	for i, want := range map[int]string{winner: models.BidStatusAccepted, loser: models.BidStatusRejected} {
+		bid, err := api.repos.Bids.FindByID(ctx, objectID(t, bids[i]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bid.Status != want {
+			t.Errorf("bid %d is %q, want %q", i, bid.Status, want)
+		}
+		payment := held[0]
+		if i == loser {
+			payment = refunded[0]
+		}
+		if payment.FreelancerID != bid.FreelancerID {
+			t.Errorf("bid %d's bidder %s is paid by the %q payment for %s", i, bid.FreelancerID.Hex(), payment.Status, payment.FreelancerID.Hex())
+		}
+	}
+	assigned, err := api.repos.Tasks.FindByID(ctx, task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assigned.FreelancerID == nil || *assigned.FreelancerID != held[0].FreelancerID {
+		t.Errorf("task is assigned to %v, want the winner %s", assigned.FreelancerID, held[0].FreelancerID.Hex())
+	}
+}
+
+// editedBids is a bid store where the bid being accepted is edited by its
+// freelancer just before the acceptance writes it.
+type editedBids struct {
+	repository.BidRepository
+}
+
+func (b *editedBids) UpdateIfUnchanged(ctx context.Context, bid *models.Bid, lastUpdatedAt time.Time) error {
+	if bid.Status == models.BidStatusAccepted {
+		return repository.ErrConflict
+	}
+	return b.BidRepository.UpdateIfUnchanged(ctx, bid, lastUpdatedAt)
+}
+
+func TestAcceptEditedBidLeavesTaskOpen(t *testing.T) {
+	api := newTestAPI(t)
+	repos := *api.repos
+	repos.Bids = &editedBids{BidRepository: api.repos.Bids}
+	api.router = NewRouter(Dependencies{Repos: &repos, Mailer: api.mail, Gateway: api.gateway})
+	client, _ := api.register("ada@example.com", "client")
+	bob, _ := api.register("bob@example.com", "freelancer")
+
+	taskID := api.createTask(client, nil)
+	bidID := api.placeBid(bob, taskID, 450)
+	api.call(http.MethodPost, "/api/v1/bids/"+bidID+"/accept", client, map[string]any{"payment_source": gateway.FakeSourceSuccess}, http.StatusConflict)
+
+	ctx := context.Background()
+	task, err := api.repos.Tasks.FindByID(ctx, objectID(t, taskID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Status != models.TaskStatusOpen || task.FreelancerID != nil {
+		t.Errorf("task is %q assigned to %v, want it still open and unassigned", task.Status, task.FreelancerID)
+	}
+	refunded, err := api.repos.Payments.FindByTaskAndStatus(ctx, task.ID, models.PaymentStatusRefunded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refunded) != 1 {
+		t.Errorf("%d refunded payments, want the charge refunded", len(refunded))
+	}
+}
@@ -0,0 +1,113 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Vivekpdy/tasklanceweb/backend/gateway"
+	"github.com/Vivekpdy/tasklanceweb/backend/models"
+)
+
+// sendWebhook posts a fake gateway event signed at the given time and returns
+// the decoded response with its status under "code".
+func (api *testAPI) sendWebhook(event map[string]any, at time.Time) map[string]any {
+	api.t.Helper()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		api.t.Fatal(err)
+	}
+	header := http.Header{gateway.SignatureHeader: {gateway.SignPayload(testWebhookSecret, payload, at)}}
+	w := api.serve(http.MethodPost, "/api/v1/webhooks/payments/fake", "", json.RawMessage(payload), header)
+	out := map[string]any{}
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		api.t.Fatalf("webhook response %q: %v", w.Body.String(), err)
+	}
+	out["code"] = w.Code
+	return out
+}
+
+func TestPaymentWebhook(t *testing.T) {
+	api := newTestAPI(t)
+	client, _ := api.register("ada@example.com", "client")
+	freelancer, _ := api.register("bob@example.com", "freelancer")
+	taskID := api.createTask(client, nil)
+	bidID := api.placeBid(freelancer, taskID, 450)
+	api.call(http.MethodPost, "/api/v1/bids/"+bidID+"/accept", client, map[string]any{"payment_source": gateway.FakeSourceSuccess}, http.StatusOK)
+
+	ctx := context.Background()
+	held, err := api.repos.Payments.FindByTaskAndStatus(ctx, objectID(t, taskID), models.PaymentStatusHeld)
+	if err != nil || len(held) != 1 {
+		t.Fatalf("held payments = %v %v, want one", held, err)
+	}
+	payment := held[0]
+	refunded := map[string]any{"id": "evt_refund", "type": gateway.EventChargeRefunded, "data": map[string]any{"charge_id": payment.TransactionID}}
+
+	t.Run("tampered body", func(t *testing.T) {
+		api.t = t
+		payload, _ := json.Marshal(refunded)
+		header := http.Header{gateway.SignatureHeader: {gateway.SignPayload(testWebhookSecret, payload, time.Now())}}
+		tampered := map[string]any{"id": "evt_refund", "type": gateway.EventChargeRefunded, "data": map[string]any{"charge_id": "fake_ch_999999"}}
+		if w := api.serve(http.MethodPost, "/api/v1/webhooks/payments/fake", "", tampered, header); w.Code != http.StatusUnauthorized {
+			t.Errorf("tampered webhook = %d %s, want 401", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("outside tolerance", func(t *testing.T) {
+		api.t = t
+		if out := api.sendWebhook(refunded, time.Now().Add(-10*time.Minute)); out["code"] != http.StatusUnauthorized {
+			t.Errorf("stale webhook = %v, want 401", out)
+		}
+	})
+
+	// Neither rejected delivery may have been recorded or applied.
+	api.t = t
+	if _, err := api.repos.PaymentEvents.FindByEventID(ctx, "fake", "evt_refund"); err == nil {
+		t.Fatal("a rejected webhook was recorded")
+	}
+
+	t.Run("valid signature", func(t *testing.T) {
+		api.t = t
+		if out := api.sendWebhook(refunded, time.Now()); out["code"] != http.StatusOK || out["status"] != models.PaymentEventProcessed {
+			t.Fatalf("webhook = %v, want 200 processed", out)
+		}
+		updated, err := api.repos.Payments.FindByID(ctx, payment.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.Status != models.PaymentStatusRefunded {
+			t.Errorf("payment is %q, want refunded", updated.Status)
+		}
+	})
+
+	t.Run("replayed event", func(t *testing.T) {
+		api.t = t
+		first, err := api.repos.PaymentEvents.FindByEventID(ctx, "fake", "evt_refund")
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries, err := api.repos.Ledger.FindByTask(ctx, payment.TaskID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out := api.sendWebhook(refunded, time.Now())
+		if out["code"] != http.StatusOK || out["duplicate"] != true {
+			t.Fatalf("replayed webhook = %v, want 200 duplicate", out)
+		}
+
+		again, err := api.repos.PaymentEvents.FindByEventID(ctx, "fake", "evt_refund")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !again.ProcessedAt.Equal(*first.ProcessedAt) {
+			t.Errorf("replayed event was processed again at %v", again.ProcessedAt)
+		}
+		if after, _ := api.repos.Ledger.FindByTask(ctx, payment.TaskID); len(after) != len(entries) {
+			t.Errorf("ledger has %d entries after the replay, want %d", len(after), len(entries))
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Purposes for single-action tokens sent by email.
+const (
+	PurposeEmailVerification = "email_verification"
+)
+
+// ActionClaims are carried by short-lived tokens that authorize exactly one
+// kind of action, such as confirming an email address.
+type ActionClaims struct {
+	UserID  string `json:"user_id"`
+	Purpose string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+func GenerateActionToken(userID, purpose string, ttl time.Duration, secret string) (string, error) {
+	claims := ActionClaims{
+		UserID:  userID,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secret))
+}
+
+// ValidateActionToken verifies the signature and expiry of an action token and
+// that it was issued for the expected purpose.
+func ValidateActionToken(tokenString, purpose, secret string) (*ActionClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &ActionClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+		return []byte(secret), nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*ActionClaims)
+	if !ok || !token.Valid || claims.Purpose != purpose {
+		return nil, errors.New("invalid token")
+	}
+
+	return claims, nil
+}
+
+// EmailVerificationTTL is how long verification links stay valid, taken from EMAIL_VERIFICATION_EXPIRY.
+func EmailVerificationTTL() time.Duration {
+	return durationFromEnv("EMAIL_VERIFICATION_EXPIRY", 24*time.Hour)
+}
+
+// PasswordResetTTL is how long password reset links stay valid, taken from PASSWORD_RESET_EXPIRY.
+func PasswordResetTTL() time.Duration {
+	return durationFromEnv("PASSWORD_RESET_EXPIRY", time.Hour)
+}
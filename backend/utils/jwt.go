@@ -9,29 +9,44 @@ import (
 )
 
 type Claims struct {
-	UserID   string `json:"user_id"`
-	Email    string `json:"email"`
-	UserType string `json:"user_type"`
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	UserType  string `json:"user_type"`
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID, email, userType, secret string) (string, error) {
-	expiryStr := os.Getenv("JWT_EXPIRY")
-	if expiryStr == "" {
-		expiryStr = "24h"
+// AccessTokenTTL is the lifetime of an access token, taken from JWT_EXPIRY.
+func AccessTokenTTL() time.Duration {
+	return durationFromEnv("JWT_EXPIRY", 15*time.Minute)
+}
+
+// RefreshTokenTTL is the lifetime of a session's refresh token, taken from REFRESH_TOKEN_EXPIRY.
+func RefreshTokenTTL() time.Duration {
+	return durationFromEnv("REFRESH_TOKEN_EXPIRY", 30*24*time.Hour)
+}
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
 	}
 
-	duration, err := time.ParseDuration(expiryStr)
+	duration, err := time.ParseDuration(value)
 	if err != nil {
-		duration = 24 * time.Hour
+		return fallback
 	}
+	return duration
+}
 
+func GenerateToken(userID, email, userType, sessionID, secret string) (string, error) {
 	claims := Claims{
-		UserID:   userID,
-		Email:    email,
-		UserType: userType,
+		UserID:    userID,
+		Email:     email,
+		UserType:  userType,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL())),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
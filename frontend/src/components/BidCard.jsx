@@ -1,4 +1,14 @@
-const BidCard = ({ bid, onAccept, isTaskOwner }) => {
+const BidCard = ({
+  bid,
+  onAccept,
+  onCounter,
+  onWithdraw,
+  onAnswerCounter,
+  onTriage,
+  onNote,
+  isTaskOwner,
+  isBidder,
+}) => {
   const formatDate = (dateString) => {
     return new Date(dateString).toLocaleDateString();
   };
@@ -9,11 +19,21 @@ const BidCard = ({ bid, onAccept, isTaskOwner }) => {
         return 'bg-green-100 text-green-800';
       case 'rejected':
         return 'bg-red-100 text-red-800';
+      case 'countered':
+        return 'bg-blue-100 text-blue-800';
+      case 'withdrawn':
+        return 'bg-gray-100 text-gray-600';
       default:
         return 'bg-yellow-100 text-yellow-800';
     }
   };
 
+  const negotiation = bid.negotiation || [];
+  const counter = bid.status === 'countered'
+    ? [...negotiation].reverse().find((entry) => entry.event === 'counter')
+    : null;
+  const isOpen = bid.status === 'pending' || bid.status === 'countered';
+
   return (
     <div className="bg-white rounded-xl shadow-md hover:shadow-lg transition-all duration-300 p-6 border border-gray-100">
       <div className="flex justify-between items-start mb-4">
@@ -34,11 +54,23 @@ const BidCard = ({ bid, onAccept, isTaskOwner }) => {
             </div>
           )}
         </div>
-        <span
-          className={`px-3 py-1 rounded-full text-xs font-semibold capitalize ${getStatusStyles(bid.status)}`}
-        >
-          {bid.status}
-        </span>
+        <div className="flex gap-2">
+          {bid.archived && (
+            <span className="px-3 py-1 rounded-full text-xs font-semibold bg-gray-100 text-gray-600">
+              Archived
+            </span>
+          )}
+          {bid.shortlisted && (
+            <span className="px-3 py-1 rounded-full text-xs font-semibold bg-purple-100 text-purple-800">
+              Shortlisted
+            </span>
+          )}
+          <span
+            className={`px-3 py-1 rounded-full text-xs font-semibold capitalize ${getStatusStyles(bid.status)}`}
+          >
+            {bid.status}
+          </span>
+        </div>
       </div>
 
       <div className="bg-gray-50 rounded-lg p-4 mb-4 space-y-2">
@@ -59,13 +91,107 @@ const BidCard = ({ bid, onAccept, isTaskOwner }) => {
         </p>
       </div>
 
+      {isTaskOwner && bid.client_note && (
+        <div className="bg-amber-50 rounded-lg p-4 mb-4 text-sm text-amber-900">
+          <p className="font-bold mb-1">Your private note</p>
+          <p>{bid.client_note}</p>
+        </div>
+      )}
+
+      {counter && (
+        <div className="bg-blue-50 rounded-lg p-4 mb-4 text-sm text-blue-900">
+          <p className="font-bold mb-1">Counter-offer awaiting the freelancer</p>
+          <p>
+            ${counter.amount} by {formatDate(counter.proposed_deadline)}
+            {counter.note && ` - ${counter.note}`}
+          </p>
+        </div>
+      )}
+
+      {negotiation.length > 1 && (
+        <details className="mb-4 text-sm text-gray-600">
+          <summary className="cursor-pointer font-medium">
+            Negotiation history ({negotiation.length})
+          </summary>
+          <ul className="mt-2 space-y-1">
+            {negotiation.map((entry, index) => (
+              <li key={index}>
+                {formatDate(entry.at)}: {entry.event.replace(/_/g, ' ')} - $
+                {entry.amount} by {formatDate(entry.proposed_deadline)}
+                {entry.note && ` (${entry.note})`}
+              </li>
+            ))}
+          </ul>
+        </details>
+      )}
+
       {isTaskOwner && bid.status === 'pending' && (
-        <button
-          className="w-full py-2.5 bg-secondary-600 text-white rounded-lg hover:bg-secondary-700 transition-colors duration-200 font-medium"
-          onClick={() => onAccept(bid.id)}
-        >
-          Accept Bid
-        </button>
+        <div className="flex gap-2">
+          <button
+            className="flex-1 py-2.5 bg-secondary-600 text-white rounded-lg hover:bg-secondary-700 transition-colors duration-200 font-medium"
+            onClick={() => onAccept(bid.id)}
+          >
+            Accept Bid
+          </button>
+          <button
+            className="flex-1 py-2.5 border border-secondary-600 text-secondary-700 rounded-lg hover:bg-secondary-50 transition-colors duration-200 font-medium"
+            onClick={() => onCounter(bid.id)}
+          >
+            Counter
+          </button>
+        </div>
+      )}
+
+      {isTaskOwner && onTriage && (
+        <div className="flex gap-2 mt-2 text-sm">
+          {isOpen && (
+            <button
+              className="flex-1 py-2 border border-purple-300 text-purple-700 rounded-lg hover:bg-purple-50 transition-colors duration-200 font-medium"
+              onClick={() => onTriage(bid.id, { shortlisted: !bid.shortlisted })}
+            >
+              {bid.shortlisted ? 'Remove from Shortlist' : 'Shortlist'}
+            </button>
+          )}
+          <button
+            className="flex-1 py-2 border border-gray-300 text-gray-700 rounded-lg hover:bg-gray-50 transition-colors duration-200 font-medium"
+            onClick={() => onTriage(bid.id, { archived: !bid.archived })}
+          >
+            {bid.archived ? 'Unarchive' : 'Archive'}
+          </button>
+          <button
+            className="flex-1 py-2 border border-gray-300 text-gray-700 rounded-lg hover:bg-gray-50 transition-colors duration-200 font-medium"
+            onClick={() => onNote(bid)}
+          >
+            {bid.client_note ? 'Edit Note' : 'Add Note'}
+          </button>
+        </div>
+      )}
+
+      {isBidder && isOpen && (
+        <div className="flex gap-2">
+          {counter && (
+            <>
+              <button
+                className="flex-1 py-2.5 bg-secondary-600 text-white rounded-lg hover:bg-secondary-700 transition-colors duration-200 font-medium"
+                onClick={() => onAnswerCounter(bid.id, true)}
+              >
+                Accept Offer
+              </button>
+              <button
+                className="flex-1 py-2.5 border border-gray-300 text-gray-700 rounded-lg hover:bg-gray-50 transition-colors duration-200 font-medium"
+                onClick={() => onAnswerCounter(bid.id, false)}
+              >
+                Decline Offer
+              </button>
+            </>
+          )}
+          <button
+            className="flex-1 py-2.5 border border-red-300 text-red-700 rounded-lg hover:bg-red-50 transition-colors duration-200 font-medium"
+            onClick={() => onWithdraw(bid.id)}
+          >
+            Withdraw
+          </button>
+        </div>
       )}
     </div>
   );
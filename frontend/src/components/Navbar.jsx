@@ -1,6 +1,7 @@
 import { useState } from 'react';
 import { Link, useNavigate } from 'react-router-dom';
 import { useAuth } from '../context/AuthContext';
+import NotificationBell from './NotificationBell';
 
 const Navbar = () => {
   const { user, logout, isAuthenticated } = useAuth();
@@ -56,6 +57,7 @@ const Navbar = () => {
                 >
                   Profile
                 </Link>
+                <NotificationBell />
                 <div className="flex items-center space-x-4 pl-4 border-l border-gray-300">
                   <span className="text-sm text-gray-600">
                     Hi, <span className="font-semibold text-gray-800">{user?.first_name}</span>
@@ -0,0 +1,120 @@
+import { useEffect, useRef, useState } from 'react';
+import { Link } from 'react-router-dom';
+import notificationService from '../services/notificationService';
+
+const NotificationBell = () => {
+  const [isOpen, setIsOpen] = useState(false);
+  const [unread, setUnread] = useState(0);
+  const [notifications, setNotifications] = useState([]);
+  const containerRef = useRef(null);
+
+  useEffect(() => {
+    notificationService
+      .getNotifications()
+      .then((page) => setNotifications(page.items))
+      .catch(() => {});
+
+    // The stream starts with the unread count and then pushes new notifications
+    return notificationService.subscribe({
+      onUnread: setUnread,
+      onNotification: (notification) => {
+        setNotifications((current) => [notification, ...current]);
+        setUnread((count) => count + 1);
+      },
+    });
+  }, []);
+
+  useEffect(() => {
+    const handleClickOutside = (event) => {
+      if (containerRef.current && !containerRef.current.contains(event.target)) {
+        setIsOpen(false);
+      }
+    };
+    document.addEventListener('mousedown', handleClickOutside);
+    return () => document.removeEventListener('mousedown', handleClickOutside);
+  }, []);
+
+  const handleOpen = async (notification) => {
+    setIsOpen(false);
+    if (notification.read_at) return;
+    try {
+      await notificationService.markRead(notification.id);
+      setNotifications((current) =>
+        current.map((n) => (n.id === notification.id ? { ...n, read_at: new Date().toISOString() } : n))
+      );
+      setUnread((count) => Math.max(0, count - 1));
+    } catch (error) {
+      console.error('Failed to mark notification as read:', error);
+    }
+  };
+
+  const handleMarkAllRead = async () => {
+    try {
+      await notificationService.markAllRead();
+      const now = new Date().toISOString();
+      setNotifications((current) => current.map((n) => ({ ...n, read_at: n.read_at || now })));
+      setUnread(0);
+    } catch (error) {
+      console.error('Failed to mark notifications as read:', error);
+    }
+  };
+
+  return (
+    <div className="relative" ref={containerRef}>
+      <button
+        onClick={() => setIsOpen(!isOpen)}
+        className="relative p-2 rounded-lg text-gray-700 hover:bg-gray-100 focus:outline-none focus:ring-2 focus:ring-primary-500"
+        aria-label="Notifications"
+      >
+        <svg className="w-6 h-6" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+          <path
+            strokeLinecap="round"
+            strokeLinejoin="round"
+            strokeWidth={2}
+            d="M15 17h5l-1.405-1.405A2.032 2.032 0 0118 14.158V11a6.002 6.002 0 00-4-5.659V5a2 2 0 10-4 0v.341C7.67 6.165 6 8.388 6 11v3.159c0 .538-.214 1.055-.595 1.436L4 17h5m6 0v1a3 3 0 11-6 0v-1m6 0H9"
+          />
+        </svg>
+        {unread > 0 && (
+          <span className="absolute -top-1 -right-1 min-w-[1.25rem] h-5 px-1 bg-red-500 text-white text-xs font-semibold rounded-full flex items-center justify-center">
+            {unread > 99 ? '99+' : unread}
+          </span>
+        )}
+      </button>
+
+      {isOpen && (
+        <div className="absolute right-0 mt-2 w-80 bg-white rounded-lg shadow-lg border border-gray-200 overflow-hidden">
+          <div className="flex justify-between items-center px-4 py-3 border-b border-gray-200">
+            <span className="font-semibold text-gray-800">Notifications</span>
+            {unread > 0 && (
+              <button onClick={handleMarkAllRead} className="text-sm text-primary-600 hover:text-primary-700">
+                Mark all as read
+              </button>
+            )}
+          </div>
+          <div className="max-h-96 overflow-y-auto">
+            {notifications.length === 0 ? (
+              <p className="px-4 py-6 text-sm text-gray-500 text-center">No notifications yet</p>
+            ) : (
+              notifications.map((notification) => (
+                <Link
+                  key={notification.id}
+                  to={notification.task_id ? `/tasks/${notification.task_id}` : '/dashboard'}
+                  onClick={() => handleOpen(notification)}
+                  className={`block px-4 py-3 border-b border-gray-100 hover:bg-gray-50 ${
+                    notification.read_at ? '' : 'bg-primary-50'
+                  }`}
+                >
+                  <p className="text-sm font-medium text-gray-800">{notification.title}</p>
+                  <p className="text-sm text-gray-600">{notification.body}</p>
+                  <p className="text-xs text-gray-400 mt-1">{new Date(notification.created_at).toLocaleString()}</p>
+                </Link>
+              ))
+            )}
+          </div>
+        </div>
+      )}
+    </div>
+  );
+};
+
+export default NotificationBell;
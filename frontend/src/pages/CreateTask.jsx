@@ -1,6 +1,7 @@
-import { useState } from 'react';
+import { useRef, useState } from 'react';
 import { useNavigate } from 'react-router-dom';
 import taskService from '../services/taskService';
+import { isFinalError, newIdempotencyKey } from '../services/api';
 
 const CreateTask = () => {
   const navigate = useNavigate();
@@ -11,9 +12,12 @@ const CreateTask = () => {
     deadline: '',
     category: '',
     required_skills: '',
+    bidding_mode: 'open',
+    bidding_closes_at: '',
   });
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState('');
+  const idempotencyKey = useRef(newIdempotencyKey());
 
   const categories = [
     'Web Development',
@@ -49,10 +53,18 @@ const CreateTask = () => {
         budget: parseFloat(formData.budget),
         required_skills: skillsArray,
       };
+      if (formData.bidding_mode === 'open') {
+        delete taskData.bidding_closes_at;
+      } else {
+        taskData.bidding_closes_at = new Date(formData.bidding_closes_at).toISOString();
+      }
 
-      const newTask = await taskService.createTask(taskData);
+      const newTask = await taskService.createTask(taskData, idempotencyKey.current);
       navigate(`/tasks/${newTask.id}`);
     } catch (err) {
+      if (isFinalError(err)) {
+        idempotencyKey.current = newIdempotencyKey();
+      }
       setError(err.response?.data?.error || 'Failed to create task');
       setLoading(false);
     }
@@ -143,6 +155,36 @@ const CreateTask = () => {
             </select>
           </div>
 
+          <div className="form-row">
+            <div className="form-group">
+              <label htmlFor="bidding_mode">Bidding</label>
+              <select
+                id="bidding_mode"
+                name="bidding_mode"
+                value={formData.bidding_mode}
+                onChange={handleChange}
+              >
+                <option value="open">Open - bidders see all bids</option>
+                <option value="sealed">Sealed - bids hidden, lowest shortlisted at close</option>
+                <option value="reverse_auction">Reverse auction - lowest bid at close wins</option>
+              </select>
+            </div>
+
+            {formData.bidding_mode !== 'open' && (
+              <div className="form-group">
+                <label htmlFor="bidding_closes_at">Bidding Closes *</label>
+                <input
+                  type="datetime-local"
+                  id="bidding_closes_at"
+                  name="bidding_closes_at"
+                  value={formData.bidding_closes_at}
+                  onChange={handleChange}
+                  required
+                />
+              </div>
+            )}
+          </div>
+
           <div className="form-group">
             <label htmlFor="required_skills">Required Skills</label>
             <input
@@ -10,6 +10,7 @@ const Dashboard = () => {
   const { user } = useAuth();
   const [tasks, setTasks] = useState([]);
   const [bids, setBids] = useState([]);
+  const [recommended, setRecommended] = useState([]);
   const [loading, setLoading] = useState(true);
   const [activeTab, setActiveTab] = useState('tasks');
 
@@ -45,6 +46,13 @@ const Dashboard = () => {
           }
         }
         setBids(allBids);
+
+        try {
+          const matches = await taskService.getRecommendedTasks();
+          setRecommended(Array.isArray(matches) ? matches : []);
+        } catch (err) {
+          console.error('Failed to fetch recommended tasks:', err);
+        }
       }
     } catch (error) {
       console.error('Failed to fetch dashboard data:', error);
@@ -165,6 +173,12 @@ const Dashboard = () => {
               >
                 Available Tasks
               </button>
+              <button
+                className={`tab ${activeTab === 'recommended' ? 'active' : ''}`}
+                onClick={() => setActiveTab('recommended')}
+              >
+                Recommended ({recommended.length})
+              </button>
               <button
                 className={`tab ${activeTab === 'bids' ? 'active' : ''}`}
                 onClick={() => setActiveTab('bids')}
@@ -186,6 +200,28 @@ const Dashboard = () => {
                   </div>
                 )}
               </div>
+            ) : activeTab === 'recommended' ? (
+              <div className="tasks-section">
+                <h2>Recommended for You</h2>
+                {recommended.length === 0 ? (
+                  <p className="empty-state">
+                    No recommendations yet. Add skills to your profile to get better matches.
+                  </p>
+                ) : (
+                  <div className="tasks-grid">
+                    {recommended.map((match) => (
+                      <div key={match.task.id} className="recommended-task">
+                        <TaskCard task={match.task} />
+                        <p className="match-score">
+                          {Math.round(match.score * 100)}% match
+                          {match.matched_skills?.length > 0 &&
+                            ` · ${match.matched_skills.join(', ')}`}
+                        </p>
+                      </div>
+                    ))}
+                  </div>
+                )}
+              </div>
             ) : (
               <div className="bids-section">
                 <h2>My Bids</h2>
@@ -2,6 +2,7 @@ import { useState, useEffect } from 'react';
 import { useAuth } from '../context/AuthContext';
 import userService from '../services/userService';
 import reviewService from '../services/reviewService';
+import notificationService from '../services/notificationService';
 import ReviewCard from '../components/ReviewCard';
 
 const Profile = () => {
@@ -16,6 +17,7 @@ const Profile = () => {
   });
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState('');
+  const [emailPreference, setEmailPreference] = useState('instant');
 
   useEffect(() => {
     if (user) {
@@ -26,6 +28,10 @@ const Profile = () => {
         skills: Array.isArray(user.skills) ? user.skills.join(', ') : '',
       });
       fetchReviews();
+      notificationService
+        .getPreferences()
+        .then((preferences) => setEmailPreference(preferences.email))
+        .catch((err) => console.error('Failed to fetch notification preferences:', err));
     }
   }, [user]);
 
@@ -38,6 +44,18 @@ const Profile = () => {
     }
   };
 
+  const handleEmailPreferenceChange = async (e) => {
+    const previous = emailPreference;
+    setEmailPreference(e.target.value);
+    try {
+      const preferences = await notificationService.updatePreferences(e.target.value);
+      setEmailPreference(preferences.email);
+    } catch (err) {
+      setEmailPreference(previous);
+      setError(err.response?.data?.error || 'Failed to update email preferences');
+    }
+  };
+
   const handleChange = (e) => {
     setFormData({
       ...formData,
@@ -218,6 +236,15 @@ const Profile = () => {
           </div>
         )}
 
+        <div className="detail-section">
+          <h3>Email Notifications</h3>
+          <select value={emailPreference} onChange={handleEmailPreferenceChange}>
+            <option value="instant">Email me as things happen</option>
+            <option value="daily_digest">Send me a daily digest</option>
+            <option value="off">Don't email me</option>
+          </select>
+        </div>
+
         <div className="reviews-section">
           <h2>Reviews ({reviews.length})</h2>
           {reviews.length === 0 ? (
@@ -1,8 +1,9 @@
-import { useState, useEffect } from 'react';
+import { useState, useEffect, useRef } from 'react';
 import { useParams, useNavigate } from 'react-router-dom';
 import BidCard from '../components/BidCard';
 import taskService from '../services/taskService';
 import bidService from '../services/bidService';
+import { isFinalError, newIdempotencyKey } from '../services/api';
 import { useAuth } from '../context/AuthContext';
 
 const TaskDetails = () => {
@@ -12,6 +13,10 @@ const TaskDetails = () => {
 
   const [task, setTask] = useState(null);
   const [bids, setBids] = useState([]);
+  const [bidSummary, setBidSummary] = useState(null);
+  const [bidView, setBidView] = useState('');
+  const [comparison, setComparison] = useState(null);
+  const [suggestions, setSuggestions] = useState(null);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState('');
   const [showBidForm, setShowBidForm] = useState(false);
@@ -20,12 +25,16 @@ const TaskDetails = () => {
     proposed_deadline: '',
     cover_letter: '',
   });
+  const bidIdempotencyKey = useRef(newIdempotencyKey());
 
   useEffect(() => {
     fetchTaskDetails();
-    fetchBids();
   }, [id]);
 
+  useEffect(() => {
+    fetchBids();
+  }, [id, bidView]);
+
   const fetchTaskDetails = async () => {
     try {
       const data = await taskService.getTaskById(id);
@@ -40,8 +49,9 @@ const TaskDetails = () => {
 
   const fetchBids = async () => {
     try {
-      const data = await bidService.getTaskBids(id);
-      setBids(Array.isArray(data) ? data : []);
+      const data = await bidService.getTaskBidListing(id, bidView);
+      setBids(Array.isArray(data.items) ? data.items : []);
+      setBidSummary(data.open_bids === undefined ? null : data);
     } catch (err) {
       console.error('Failed to load bids:', err);
     }
@@ -53,7 +63,8 @@ const TaskDetails = () => {
       await bidService.createBid({
         task_id: id,
         ...bidFormData,
-      });
+      }, bidIdempotencyKey.current);
+      bidIdempotencyKey.current = newIdempotencyKey();
       setShowBidForm(false);
       setBidFormData({
         amount: '',
@@ -62,6 +73,9 @@ const TaskDetails = () => {
       });
       fetchBids();
     } catch (err) {
+      if (isFinalError(err)) {
+        bidIdempotencyKey.current = newIdempotencyKey();
+      }
       alert(err.response?.data?.error || 'Failed to submit bid');
     }
   };
@@ -77,6 +91,70 @@ const TaskDetails = () => {
     }
   };
 
+  const handleBidAction = async (action, failureMessage) => {
+    try {
+      await action();
+      fetchBids();
+    } catch (err) {
+      alert(err.response?.data?.error || failureMessage);
+    }
+  };
+
+  const handleWithdrawBid = (bidId) => {
+    if (window.confirm('Withdraw this bid? You cannot bid on this task again.')) {
+      handleBidAction(() => bidService.withdrawBid(bidId), 'Failed to withdraw bid');
+    }
+  };
+
+  const handleCounterBid = (bidId) => {
+    const amount = window.prompt('Counter-offer amount ($)');
+    if (!amount) return;
+    const deadline = window.prompt('Counter-offer deadline (YYYY-MM-DD)');
+    if (!deadline) return;
+    const note = window.prompt('Note for the freelancer (optional)') || '';
+    handleBidAction(
+      () => bidService.counterBid(bidId, {
+        amount: parseFloat(amount),
+        proposed_deadline: deadline,
+        note,
+      }),
+      'Failed to send counter-offer'
+    );
+  };
+
+  const handleTriageBid = (bidId, triage) => {
+    handleBidAction(() => bidService.triageBid(bidId, triage), 'Failed to update bid');
+  };
+
+  const handleNoteBid = (bid) => {
+    const note = window.prompt('Private note on this bid', bid.client_note || '');
+    if (note === null) return;
+    handleTriageBid(bid.id, { note });
+  };
+
+  const handleCompareBids = async () => {
+    try {
+      setComparison(await bidService.compareBids(id));
+    } catch (err) {
+      alert(err.response?.data?.error || 'Failed to compare bids');
+    }
+  };
+
+  const handleSuggestFreelancers = async () => {
+    try {
+      setSuggestions(await taskService.getSuggestedFreelancers(id));
+    } catch (err) {
+      alert(err.response?.data?.error || 'Failed to load suggested freelancers');
+    }
+  };
+
+  const handleAnswerCounter = (bidId, accept) => {
+    handleBidAction(
+      () => (accept ? bidService.acceptCounter(bidId) : bidService.declineCounter(bidId)),
+      'Failed to answer counter-offer'
+    );
+  };
+
   const handleDelete = async () => {
     if (window.confirm('Are you sure you want to delete this task?')) {
       try {
@@ -94,6 +172,8 @@ const TaskDetails = () => {
 
   const isOwner = user?.id === task.client_id;
   const canBid = user?.user_type === 'freelancer' && task.status === 'open';
+  // Auction bids can be triaged once bidding closes
+  const canTriage = isOwner && (!task.bidding_mode || task.bidding_mode === 'open' || !!task.bidding_closed_at);
 
   return (
     <div className="task-details-page">
@@ -217,9 +297,109 @@ const TaskDetails = () => {
       </div>
 
       <div className="bids-section">
-        <h2>Bids ({bids.length})</h2>
+        <h2>Bids ({bidSummary ? bidSummary.open_bids : bids.length})</h2>
+        {task.bidding_mode && task.bidding_mode !== 'open' && (
+          <p className="bidding-info">
+            {task.bidding_mode === 'sealed' ? 'Sealed bidding' : 'Reverse auction'}
+            {task.bidding_closed_at
+              ? ' - closed'
+              : ` - closes ${new Date(task.bidding_closes_at).toLocaleString()}`}
+            {bidSummary?.lowest_amount !== undefined &&
+              ` - lowest bid $${bidSummary.lowest_amount}`}
+          </p>
+        )}
+        {canTriage && (
+          <div className="bid-triage">
+            <select value={bidView} onChange={(e) => setBidView(e.target.value)}>
+              <option value="">All bids</option>
+              <option value="active">Active</option>
+              <option value="shortlisted">Shortlisted</option>
+              <option value="archived">Archived</option>
+            </select>
+            <button type="button" onClick={handleCompareBids}>
+              Compare Shortlist
+            </button>
+          </div>
+        )}
+        {comparison && (
+          <div className="bid-comparison">
+            <table>
+              <thead>
+                <tr>
+                  <th>Freelancer</th>
+                  <th>Amount</th>
+                  <th>Rating</th>
+                  <th>Skill match</th>
+                  <th>Completed</th>
+                  <th>On time</th>
+                </tr>
+              </thead>
+              <tbody>
+                {comparison.items.map((item) => (
+                  <tr key={item.bid.id}>
+                    <td>{item.freelancer.first_name} {item.freelancer.last_name}</td>
+                    <td>${item.bid.amount}</td>
+                    <td>{item.freelancer.rating.toFixed(1)}</td>
+                    <td>
+                      {Math.round(item.skill_overlap * 100)}%
+                      {item.matched_skills.length > 0 && ` (${item.matched_skills.join(', ')})`}
+                    </td>
+                    <td>{item.completed_tasks}</td>
+                    <td>
+                      {item.on_time_rate === null ? '-' : `${Math.round(item.on_time_rate * 100)}%`}
+                    </td>
+                  </tr>
+                ))}
+              </tbody>
+            </table>
+            <button type="button" onClick={() => setComparison(null)}>
+              Close
+            </button>
+          </div>
+        )}
+        {isOwner && task.status === 'open' && !suggestions && (
+          <button type="button" onClick={handleSuggestFreelancers}>
+            Suggest Freelancers
+          </button>
+        )}
+        {suggestions && (
+          <div className="suggested-freelancers">
+            <h3>Suggested Freelancers</h3>
+            {suggestions.length === 0 ? (
+              <p>No freelancers match this task's skills yet.</p>
+            ) : (
+              <table>
+                <thead>
+                  <tr>
+                    <th>Freelancer</th>
+                    <th>Match</th>
+                    <th>Skills</th>
+                    <th>Completed</th>
+                    <th>On time</th>
+                  </tr>
+                </thead>
+                <tbody>
+                  {suggestions.map((match) => (
+                    <tr key={match.freelancer.id}>
+                      <td>{match.freelancer.first_name} {match.freelancer.last_name}</td>
+                      <td>{Math.round(match.score * 100)}%</td>
+                      <td>{match.matched_skills.join(', ')}</td>
+                      <td>{match.completed_tasks}</td>
+                      <td>
+                        {match.on_time_rate === null ? '-' : `${Math.round(match.on_time_rate * 100)}%`}
+                      </td>
+                    </tr>
+                  ))}
+                </tbody>
+              </table>
+            )}
+            <button type="button" onClick={() => setSuggestions(null)}>
+              Close
+            </button>
+          </div>
+        )}
         {bids.length === 0 ? (
-          <p className="no-bids">No bids yet</p>
+          <p className="no-bids">{bidSummary ? 'Other bids on this task are private' : 'No bids yet'}</p>
         ) : (
           <div className="bids-list">
             {bids.map((bid) => (
@@ -227,7 +407,13 @@ const TaskDetails = () => {
                 key={bid.id}
                 bid={bid}
                 onAccept={handleAcceptBid}
+                onCounter={handleCounterBid}
+                onWithdraw={handleWithdrawBid}
+                onAnswerCounter={handleAnswerCounter}
+                onTriage={canTriage ? handleTriageBid : undefined}
+                onNote={handleNoteBid}
                 isTaskOwner={isOwner}
+                isBidder={user?.id === bid.freelancer_id}
               />
             ))}
           </div>
@@ -37,4 +37,18 @@ api.interceptors.response.use(
   }
 );
 
+// Keys for the Idempotency-Key header. A form keeps one key until the server
+// has definitively answered, so a double-click or a retry after a network
+// error replays the first request instead of creating a duplicate.
+export const newIdempotencyKey = () => crypto.randomUUID();
+
+export const idempotencyHeaders = (key) => (key ? { headers: { 'Idempotency-Key': key } } : {});
+
+// True once a request has been rejected for good, after which the next
+// submission needs a fresh key. 409 means the first attempt is still running.
+export const isFinalError = (error) => {
+  const status = error.response?.status;
+  return status !== undefined && status < 500 && status !== 409;
+};
+
 export default api;
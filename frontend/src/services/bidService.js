@@ -1,15 +1,31 @@
-import api from './api';
+import api, { idempotencyHeaders } from './api';
 
 const bidService = {
   // Get all bids for a task
   getTaskBids: async (taskId) => {
     const response = await api.get(`/bids/task/${taskId}`);
+    return response.data.items;
+  },
+
+  // Get the bids of a task the caller may see, with open_bids and
+  // lowest_amount on sealed and reverse-auction tasks. The task owner may
+  // pass a view: active, shortlisted or archived
+  getTaskBidListing: async (taskId, view) => {
+    const params = view ? { view } : {};
+    const response = await api.get(`/bids/task/${taskId}`, { params });
+    return response.data;
+  },
+
+  // Compare bids side by side; without bidIds the shortlist is compared
+  compareBids: async (taskId, bidIds = []) => {
+    const params = bidIds.length ? { ids: bidIds.join(',') } : {};
+    const response = await api.get(`/bids/task/${taskId}/compare`, { params });
     return response.data;
   },
 
   // Create a new bid
-  createBid: async (bidData) => {
-    const response = await api.post('/bids', bidData);
+  createBid: async (bidData, idempotencyKey) => {
+    const response = await api.post('/bids', bidData, idempotencyHeaders(idempotencyKey));
     return response.data;
   },
 
@@ -24,6 +40,36 @@ const bidService = {
     const response = await api.post(`/bids/${bidId}/accept`);
     return response.data;
   },
+
+  // Shortlist, archive or annotate a bid (task owner only)
+  triageBid: async (bidId, triage) => {
+    const response = await api.put(`/bids/${bidId}/triage`, triage);
+    return response.data;
+  },
+
+  // Withdraw a bid
+  withdrawBid: async (bidId, note) => {
+    const response = await api.post(`/bids/${bidId}/withdraw`, { note });
+    return response.data;
+  },
+
+  // Counter-offer on a bid's amount and deadline
+  counterBid: async (bidId, counterData) => {
+    const response = await api.post(`/bids/${bidId}/counter`, counterData);
+    return response.data;
+  },
+
+  // Accept the client's counter-offer
+  acceptCounter: async (bidId, note) => {
+    const response = await api.post(`/bids/${bidId}/counter/accept`, { note });
+    return response.data;
+  },
+
+  // Decline the client's counter-offer
+  declineCounter: async (bidId, note) => {
+    const response = await api.post(`/bids/${bidId}/counter/decline`, { note });
+    return response.data;
+  },
 };
 
 export default bidService;
@@ -0,0 +1,60 @@
+import api from './api';
+
+const notificationService = {
+  // Get the current user's notifications, newest first
+  getNotifications: async ({ unread = false, cursor } = {}) => {
+    const response = await api.get('/notifications', {
+      params: { unread: unread || undefined, cursor },
+    });
+    return response.data;
+  },
+
+  // Get the number of unread notifications
+  getUnreadCount: async () => {
+    const response = await api.get('/notifications/unread-count');
+    return response.data.unread;
+  },
+
+  // Mark one notification as read
+  markRead: async (notificationId) => {
+    const response = await api.post(`/notifications/${notificationId}/read`);
+    return response.data;
+  },
+
+  // Mark every notification as read
+  markAllRead: async () => {
+    const response = await api.post('/notifications/read-all');
+    return response.data;
+  },
+
+  // Get how notification emails are delivered: instant, daily_digest or off
+  getPreferences: async () => {
+    const response = await api.get('/notifications/preferences');
+    return response.data.preferences;
+  },
+
+  // Change how notification emails are delivered
+  updatePreferences: async (email) => {
+    const response = await api.put('/notifications/preferences', { email });
+    return response.data.preferences;
+  },
+
+  // Open the server-sent event stream. EventSource cannot send headers, so
+  // the token goes in the query string. Returns a function closing the stream.
+  subscribe: ({ onNotification, onUnread }) => {
+    const token = localStorage.getItem('token');
+    const url = `${api.defaults.baseURL}/notifications/stream?access_token=${encodeURIComponent(token)}`;
+    const source = new EventSource(url);
+
+    source.addEventListener('unread', (event) => {
+      onUnread?.(JSON.parse(event.data).unread);
+    });
+    source.addEventListener('notification', (event) => {
+      onNotification?.(JSON.parse(event.data));
+    });
+
+    return () => source.close();
+  },
+};
+
+export default notificationService;
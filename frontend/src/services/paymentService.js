@@ -1,15 +1,15 @@
-import api from './api';
+import api, { idempotencyHeaders } from './api';
 
 const paymentService = {
   // Get all payments for a task
   getTaskPayments: async (taskId) => {
     const response = await api.get(`/payments/task/${taskId}`);
-    return response.data;
+    return response.data.items;
   },
 
   // Create a new payment
-  createPayment: async (paymentData) => {
-    const response = await api.post('/payments', paymentData);
+  createPayment: async (paymentData, idempotencyKey) => {
+    const response = await api.post('/payments', paymentData, idempotencyHeaders(idempotencyKey));
     return response.data;
   },
 
@@ -4,7 +4,7 @@ const reviewService = {
   // Get all reviews for a user
   getUserReviews: async (userId) => {
     const response = await api.get(`/reviews/user/${userId}`);
-    return response.data;
+    return response.data.items;
   },
 
   // Create a new review
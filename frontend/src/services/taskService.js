@@ -1,11 +1,11 @@
-import api from './api';
+import api, { idempotencyHeaders } from './api';
 
 const taskService = {
   // Get all tasks with optional filters
   getTasks: async (filters = {}) => {
     const params = new URLSearchParams(filters).toString();
     const response = await api.get(`/tasks?${params}`);
-    return response.data;
+    return response.data.items;
   },
 
   // Get task by ID
@@ -15,8 +15,8 @@ const taskService = {
   },
 
   // Create a new task
-  createTask: async (taskData) => {
-    const response = await api.post('/tasks', taskData);
+  createTask: async (taskData, idempotencyKey) => {
+    const response = await api.post('/tasks', taskData, idempotencyHeaders(idempotencyKey));
     return response.data;
   },
 
@@ -31,6 +31,18 @@ const taskService = {
     const response = await api.delete(`/tasks/${taskId}`);
     return response.data;
   },
+
+  // Get open tasks recommended for the current freelancer
+  getRecommendedTasks: async (limit = 10) => {
+    const response = await api.get(`/tasks/recommended?limit=${limit}`);
+    return response.data.items;
+  },
+
+  // Get freelancers suggested for a task (task owner only)
+  getSuggestedFreelancers: async (taskId, limit = 10) => {
+    const response = await api.get(`/tasks/${taskId}/suggested-freelancers?limit=${limit}`);
+    return response.data.items;
+  },
 };
 
 export default taskService;